@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/tekenstam/vidkit/internal/pkg/config"
+	"github.com/tekenstam/vidkit/internal/pkg/ignore"
 	"github.com/tekenstam/vidkit/internal/pkg/media"
 	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+	"github.com/tekenstam/vidkit/internal/pkg/naming"
+	"github.com/tekenstam/vidkit/internal/pkg/release"
 	"github.com/tekenstam/vidkit/pkg/resolution"
 )
 
@@ -20,12 +25,35 @@ var (
 	date    = "unknown"
 )
 
-func processFile(path string, cfg *config.Config) error {
+func processFile(path string, rules []release.Rule, cfg *config.Config) error {
+	// Samples are smaller and shorter than the videos they come with
+	if cfg.MinFileSizeMB > 0 {
+		if stat, err := os.Stat(path); err == nil && stat.Size() < int64(cfg.MinFileSizeMB)<<20 {
+			fmt.Printf("\nSkipping %s: smaller than %d MB\n", path, cfg.MinFileSizeMB)
+			return nil
+		}
+	}
+
 	info, err := media.GetVideoInfo(path)
 	if err != nil {
 		return fmt.Errorf("error analyzing video: %v", err)
 	}
 
+	if cfg.MinDuration > 0 {
+		if seconds, err := strconv.ParseFloat(info.Format.Duration, 64); err == nil && seconds < float64(cfg.MinDuration) {
+			fmt.Printf("\nSkipping %s: shorter than %d seconds\n", path, cfg.MinDuration)
+			return nil
+		}
+	}
+
+	// Files VidKit named itself parse back into the values of the templates;
+	// those already named and placed by the current templates are left alone
+	templateTV, templateMovie, conforms := matchTemplates(path, info, cfg)
+	if conforms && cfg.SkipConforming {
+		fmt.Printf("\nSkipping %s: already named by the configured templates\n", path)
+		return nil
+	}
+
 	fmt.Printf("\n=== Processing: %s ===\n", path)
 
 	// Print file information
@@ -61,15 +89,31 @@ func processFile(path string, cfg *config.Config) error {
 		return nil
 	}
 
-	// Check if this is a TV show
-	tvShowInfo := metadata.ExtractTVShowInfo(path)
-	if tvShowInfo.Season > 0 && tvShowInfo.Episode > 0 {
+	// Identify the video by its absolute path so directory names can help
+	identPath := path
+	if absPath, err := filepath.Abs(path); err == nil {
+		identPath = absPath
+	}
+
+	// Extras are moved next to their parent title instead of being looked up
+	isTemplateMatch := templateTV.IsEpisode() || templateMovie.Title != ""
+	if extraInfo := metadata.ExtractExtraInfo(identPath, rules...); extraInfo.Kind != "" && !isTemplateMatch {
+		return processExtra(path, info, extraInfo, cfg)
+	}
+
+	tvShowInfo, movieInfo := identifyFile(identPath, info.Tags(), templateTV, templateMovie, rules, cfg)
+
+	// The duration helps tell apart search results with similar titles
+	if seconds, err := strconv.ParseFloat(info.Format.Duration, 64); err == nil {
+		tvShowInfo.Duration = int(seconds)
+		movieInfo.Duration = int(seconds)
+	}
+	if tvShowInfo.IsEpisode() && tvShowInfo.Confidence >= movieInfo.Confidence {
 		// This is a TV show, process it accordingly
 		return processTVShow(path, info, tvShowInfo, cfg)
 	}
 
 	// If not a TV show, treat as movie
-	movieInfo := metadata.ExtractMovieInfo(path)
 	if movieInfo.Title != "" {
 		// This appears to be a movie
 		return processMovie(path, info, movieInfo, cfg)
@@ -78,7 +122,85 @@ func processFile(path string, cfg *config.Config) error {
 	return nil
 }
 
+// identifyFile identifies a video from its path, the values recovered from
+// the templates and its embedded tags. Names may read as an episode and as a
+// movie; the more confident interpretation wins, TV shows winning ties.
+func identifyFile(path string, tags map[string]string, templateTV metadata.TVShowSearch, templateMovie metadata.MovieSearch, rules []release.Rule, cfg *config.Config) (metadata.TVShowSearch, metadata.MovieSearch) {
+	tvShowInfo := metadata.ExtractTVShowInfo(path, rules...)
+	movieInfo := metadata.ExtractMovieInfo(path, rules...)
+	switch {
+	case templateTV.IsEpisode():
+		tvShowInfo, movieInfo = templateTV, metadata.MovieSearch{}
+	case templateMovie.Title != "":
+		tvShowInfo, movieInfo = metadata.TVShowSearch{}, templateMovie
+	}
+	if cfg.EmbeddedTags != metadata.EmbeddedTagsIgnore {
+		tvShowInfo, movieInfo = useEmbeddedTags(tags, tvShowInfo, movieInfo, cfg.EmbeddedTags == metadata.EmbeddedTagsPrefer)
+	}
+	return tvShowInfo, movieInfo
+}
+
+// useEmbeddedTags replaces the episode and movie parsed from the filename by
+// those identified by the embedded tags, when the tags are more confident or
+// preferred. Fields the tags lack keep their value from the filename. Movie
+// tags must beat the stronger reading of the filename, so a title tag never
+// turns an identified episode into a movie.
+func useEmbeddedTags(tags map[string]string, tvShowInfo metadata.TVShowSearch, movieInfo metadata.MovieSearch, prefer bool) (metadata.TVShowSearch, metadata.MovieSearch) {
+	if tagged := metadata.ExtractEmbeddedTVShowInfo(tags); tagged.IsEpisode() && (prefer || tagged.Confidence >= tvShowInfo.Confidence) {
+		fmt.Printf("Identified by embedded tags: %s %s\n", tagged.Title, metadata.FormatEpisodeRange(tagged.Season, tagged.EpisodeNumbers()))
+		if tagged.EpisodeTitle == "" {
+			tagged.EpisodeTitle = tvShowInfo.EpisodeTitle
+		}
+		// The tags name an episode, so the file is no movie
+		return tagged, metadata.MovieSearch{}
+	}
+	best := max(tvShowInfo.Confidence, movieInfo.Confidence)
+	if tagged := metadata.ExtractEmbeddedMovieInfo(tags); tagged.Confidence > 0 && ((prefer && !tvShowInfo.IsEpisode()) || tagged.Confidence >= best) {
+		if tagged.Title == "" {
+			tagged.Title = movieInfo.Title
+		}
+		if tagged.Year == 0 {
+			tagged.Year = movieInfo.Year
+		}
+		if tagged.Edition == "" {
+			tagged.Edition = movieInfo.Edition
+		}
+		tagged.Part = movieInfo.Part
+		fmt.Printf("Identified by embedded tags: %s\n", tagged.Title)
+		return metadata.TVShowSearch{}, tagged
+	}
+	return tvShowInfo, movieInfo
+}
+
+// processTVShow looks up an episode and renames its file. The remaining
+// files of a show the user picked a title for use that title without asking.
 func processTVShow(path string, info *media.VideoInfo, tvShowInfo metadata.TVShowSearch, cfg *config.Config) error {
+	key := pickKey(release.ScopeTV, tvShowInfo.Title, tvShowInfo.Year)
+	search := tvShowInfo
+	picked, ok := showPicks[key]
+	if ok {
+		fmt.Printf("\nUsing %s, picked for earlier files of the show\n", picked)
+		search = picked.tvShowSearch(tvShowInfo)
+	}
+
+	for {
+		d, p, err := renameTVShow(path, info, search, ok, cfg)
+		switch {
+		case err != nil:
+			return err
+		case d == decideRenameAll:
+			showPicks[key] = p
+		case d == decideLookup:
+			search = p.tvShowSearch(tvShowInfo)
+			continue
+		}
+		return nil
+	}
+}
+
+// renameTVShow looks up an episode, shows the new name of its file and
+// renames it as decided. Picked files are renamed without asking.
+func renameTVShow(path string, info *media.VideoInfo, tvShowInfo metadata.TVShowSearch, picked bool, cfg *config.Config) (decision, pick, error) {
 	fmt.Println("\n=== Looking up TV show metadata... ===")
 
 	// Create a formatted search string
@@ -86,26 +208,59 @@ func processTVShow(path string, info *media.VideoInfo, tvShowInfo metadata.TVSho
 	if tvShowInfo.Year > 0 {
 		searchString = fmt.Sprintf("%s (year: %d)", searchString, tvShowInfo.Year)
 	}
-	searchString = fmt.Sprintf("%s - S%02dE%02d", searchString, tvShowInfo.Season, tvShowInfo.Episode)
-	fmt.Printf("Searching for %s\n", searchString)
+	switch {
+	case tvShowInfo.AirDate != "":
+		searchString = fmt.Sprintf("%s - aired %s", searchString, tvShowInfo.AirDate)
+	case tvShowInfo.AbsoluteEpisode > 0:
+		searchString = fmt.Sprintf("%s - episode %d", searchString, tvShowInfo.AbsoluteEpisode)
+	default:
+		searchString = fmt.Sprintf("%s - %s", searchString, metadata.FormatEpisodeRange(tvShowInfo.Season, tvShowInfo.EpisodeNumbers()))
+	}
+	if tvShowInfo.ProviderID != "" {
+		searchString = fmt.Sprintf("%s [%s:%s]", searchString, tvShowInfo.Provider, tvShowInfo.ProviderID)
+	} else if tvShowInfo.IMDbID != "" {
+		searchString = fmt.Sprintf("%s [%s]", searchString, tvShowInfo.IMDbID)
+	}
+	fmt.Printf("Searching for %s (confidence %.0f%%)\n", searchString, tvShowInfo.Confidence*100)
 
 	// Create the appropriate provider using factory
 	provider, err := metadata.CreateTVShowProvider(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create TV show provider: %v", err)
+		return decideSkip, pick{}, fmt.Errorf("failed to create TV show provider: %v", err)
+	}
+	result := lookupResult{
+		path:   path,
+		search: searchString,
+		shared: "show",
+		candidates: func() ([]metadata.Candidate, error) {
+			return provider.TVShowCandidates(tvShowInfo, cfg.Language, maxCandidates)
+		},
+		providers: cfg.TVProviderChain(),
 	}
 
 	// Search for the TV show
 	tvShowMetadata, err := provider.SearchTVShow(tvShowInfo, cfg.Language)
 	if err != nil {
-		// Just log the error and continue without metadata
+		// Just log the error and continue without metadata; interactive
+		// runs may search again, batch runs queue the file for review
 		fmt.Printf("Warning: Failed to look up TV show: %v\n", err)
-		return nil
+		if cfg.PreviewMode || picked {
+			return decideSkip, pick{}, nil
+		}
+		d, p := decide(result, cfg)
+		return d, p, nil
+	}
+	tvShowMetadata.Confidence = metadata.TVShowResultConfidence(tvShowInfo, tvShowMetadata)
+
+	// Combine the titles of multi-episode files in the configured style
+	if len(tvShowMetadata.EpisodeTitles) > 1 {
+		tvShowMetadata.EpisodeTitle = metadata.JoinEpisodeTitles(tvShowMetadata.EpisodeTitles, cfg.EpisodeTitleStyle)
 	}
 
 	// Print TV show metadata
 	fmt.Println("\n=== TV Show Metadata ===")
 	fmt.Printf("Title: %s\n", tvShowMetadata.Title)
+	printProvider(tvShowMetadata.Provider, tvShowMetadata.Confidence)
 	if tvShowMetadata.Year > 0 {
 		fmt.Printf("Year: %d\n", tvShowMetadata.Year)
 	}
@@ -125,7 +280,14 @@ func processTVShow(path string, info *media.VideoInfo, tvShowInfo metadata.TVSho
 	// Print episode information
 	fmt.Println("\n=== Episode Information ===")
 	fmt.Printf("Season: %d\n", tvShowMetadata.Season)
-	fmt.Printf("Episode: %d\n", tvShowMetadata.Episode)
+	if len(tvShowMetadata.Episodes) > 1 {
+		fmt.Printf("Episodes: %s\n", metadata.FormatEpisodeRange(tvShowMetadata.Season, tvShowMetadata.Episodes))
+	} else {
+		fmt.Printf("Episode: %d\n", tvShowMetadata.Episode)
+	}
+	if tvShowMetadata.AbsoluteEpisode > 0 {
+		fmt.Printf("Absolute Episode: %d\n", tvShowMetadata.AbsoluteEpisode)
+	}
 	if tvShowMetadata.EpisodeTitle != "" {
 		fmt.Printf("Title: %s\n", tvShowMetadata.EpisodeTitle)
 	}
@@ -137,7 +299,10 @@ func processTVShow(path string, info *media.VideoInfo, tvShowInfo metadata.TVSho
 	}
 
 	// Generate a new filename using the metadata
-	newFileName := generateTVFilename(path, info, tvShowMetadata, cfg)
+	newFileName, err := generateTVFilename(path, info, tvShowMetadata, cfg)
+	if err != nil {
+		return decideSkip, pick{}, err
+	}
 
 	// Show rename preview
 	fmt.Println("\n=== File Renaming ===")
@@ -147,28 +312,62 @@ func processTVShow(path string, info *media.VideoInfo, tvShowInfo metadata.TVSho
 	// Skip renaming if preview mode
 	if cfg.PreviewMode {
 		fmt.Println("\n[PREVIEW MODE] File would be renamed as shown above")
-		return nil
+		return decideSkip, pick{}, nil
 	}
 
 	// Skip if the target file already exists
 	if cfg.NoOverwrite && fileExists(newFileName) {
 		fmt.Println("\nSkipping rename: Target file already exists")
-		return nil
+		return decideSkip, pick{}, nil
 	}
 
-	// In batch mode, rename without confirmation
-	if cfg.BatchMode || confirmRename() {
+	// Batch runs and picked shows rename good matches without confirmation
+	d, p := decideRename, pick{}
+	if !picked {
+		result.match = titleWithYear(tvShowMetadata.Title, tvShowMetadata.Year)
+		result.provider, result.id, result.confidence = tvShowMetadata.Provider, tvShowMetadata.ID, tvShowMetadata.Confidence
+		d, p = decide(result, cfg)
+	}
+	if d == decideRename || d == decideRenameAll {
 		err := os.Rename(path, newFileName)
 		if err != nil {
-			return fmt.Errorf("error renaming file: %v", err)
+			return decideSkip, pick{}, fmt.Errorf("error renaming file: %v", err)
 		}
 		fmt.Println("File renamed successfully!")
 	}
 
-	return nil
+	return d, p, nil
 }
 
+// processMovie looks up a movie and renames its file. The remaining parts of
+// a split movie the user picked a title for use that title without asking.
 func processMovie(path string, info *media.VideoInfo, movieInfo metadata.MovieSearch, cfg *config.Config) error {
+	key := pickKey(release.ScopeMovie, movieInfo.Title, movieInfo.Year)
+	search := movieInfo
+	picked, ok := showPicks[key]
+	if ok {
+		fmt.Printf("\nUsing %s, picked for earlier files of the movie\n", picked)
+		search = picked.movieSearch(movieInfo)
+	}
+
+	for {
+		d, p, err := renameMovie(path, info, search, ok, cfg)
+		switch {
+		case err != nil:
+			return err
+		case d == decideRenameAll:
+			showPicks[key] = p
+		case d == decideLookup:
+			search = p.movieSearch(movieInfo)
+			continue
+		}
+		return nil
+	}
+}
+
+// renameMovie looks up a movie, shows the new name of its file and renames
+// it as decided. Picked files are renamed without asking.
+func renameMovie(path string, info *media.VideoInfo, movieInfo metadata.MovieSearch, picked bool, cfg *config.Config) (decision, pick, error) {
 	fmt.Println("\n=== Looking up movie metadata... ===")
 
 	// Create a formatted search string
@@ -176,34 +375,73 @@ func processMovie(path string, info *media.VideoInfo, movieInfo metadata.MovieSe
 	if movieInfo.Year > 0 {
 		searchString = fmt.Sprintf("%s (year: %d)", searchString, movieInfo.Year)
 	}
-	fmt.Printf("Searching for %s...\n", searchString)
+	if movieInfo.Edition != "" {
+		searchString = fmt.Sprintf("%s [%s]", searchString, movieInfo.Edition)
+	}
+	if movieInfo.Part > 0 {
+		searchString = fmt.Sprintf("%s (part %d)", searchString, movieInfo.Part)
+	}
+	if movieInfo.ProviderID != "" {
+		searchString = fmt.Sprintf("%s [%s:%s]", searchString, movieInfo.Provider, movieInfo.ProviderID)
+	} else if movieInfo.IMDbID != "" {
+		searchString = fmt.Sprintf("%s [%s]", searchString, movieInfo.IMDbID)
+	}
+	fmt.Printf("Searching for %s (confidence %.0f%%)...\n", searchString, movieInfo.Confidence*100)
 
-	// Create the appropriate provider using factory
+	// The picker lists the candidates of the same provider, which keeps
+	// those of the lookup instead of searching again
 	provider, err := metadata.CreateMovieProvider(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create movie provider: %v", err)
+		return decideSkip, pick{}, fmt.Errorf("failed to create movie provider: %v", err)
+	}
+	result := lookupResult{
+		path:   path,
+		search: searchString,
+		candidates: func() ([]metadata.Candidate, error) {
+			return provider.MovieCandidates(movieInfo, cfg.Language, maxCandidates)
+		},
+		providers: cfg.MovieProviderChain(),
+	}
+	if movieInfo.Part > 0 {
+		result.shared = "movie"
 	}
 
 	// Search for the movie
-	movieMetadata, err := provider.SearchMovie(movieInfo, cfg.Language)
+	movieMetadata, err := lookupMovie(movieInfo, provider, cfg)
 	if err != nil {
-		// Just log the error and continue without metadata
+		// Just log the error and continue without metadata; interactive
+		// runs may search again, batch runs queue the file for review
 		fmt.Printf("Warning: Failed to look up movie: %v\n", err)
-		return nil
+		if cfg.PreviewMode || picked {
+			return decideSkip, pick{}, nil
+		}
+		d, p := decide(result, cfg)
+		return d, p, nil
 	}
+	movieMetadata.Confidence = metadata.MovieResultConfidence(movieInfo, movieMetadata)
 
 	// Print movie metadata
 	fmt.Println("\n=== Movie Metadata ===")
 	fmt.Printf("Title: %s\n", movieMetadata.Title)
+	printProvider(movieMetadata.Provider, movieMetadata.Confidence)
 	if movieMetadata.Year > 0 {
 		fmt.Printf("Year: %d\n", movieMetadata.Year)
 	}
+	if movieMetadata.Edition != "" {
+		fmt.Printf("Edition: %s\n", movieMetadata.Edition)
+	}
+	if movieMetadata.Part > 0 {
+		fmt.Printf("Part: %d\n", movieMetadata.Part)
+	}
 	if movieMetadata.Overview != "" {
 		fmt.Printf("Overview: %s\n", movieMetadata.Overview)
 	}
 
 	// Generate a new filename using the metadata
-	newFileName := generateFilename(path, info, movieMetadata, cfg)
+	newFileName, err := generateFilename(path, info, movieMetadata, cfg)
+	if err != nil {
+		return decideSkip, pick{}, err
+	}
 
 	// Show rename preview
 	fmt.Println("\n=== File Renaming ===")
@@ -213,34 +451,198 @@ func processMovie(path string, info *media.VideoInfo, movieInfo metadata.MovieSe
 	// Skip renaming if preview mode
 	if cfg.PreviewMode {
 		fmt.Println("\n[PREVIEW MODE] File would be renamed as shown above")
-		return nil
+		return decideSkip, pick{}, nil
 	}
 
 	// Skip if the target file already exists
 	if cfg.NoOverwrite && fileExists(newFileName) {
 		fmt.Println("\nSkipping rename: Target file already exists")
-		return nil
+		return decideSkip, pick{}, nil
 	}
 
-	// In batch mode, rename without confirmation
-	if cfg.BatchMode || confirmRename() {
+	// Batch runs and picked movies rename good matches without confirmation
+	d, p := decideRename, pick{}
+	if !picked {
+		result.match = titleWithYear(movieMetadata.Title, movieMetadata.Year)
+		result.provider, result.id, result.confidence = movieMetadata.Provider, movieMetadata.ID, movieMetadata.Confidence
+		d, p = decide(result, cfg)
+	}
+	if d == decideRename || d == decideRenameAll {
 		err := os.Rename(path, newFileName)
 		if err != nil {
-			return fmt.Errorf("error renaming file: %v", err)
+			return decideSkip, pick{}, fmt.Errorf("error renaming file: %v", err)
 		}
 		fmt.Println("File renamed successfully!")
 	}
 
+	return d, p, nil
+}
+
+// processExtra moves an extra into the extras folder of its kind, which media
+// servers expect next to the movie or show it belongs to. When organizing
+// files, the parent movie is looked up so the extra follows it into its
+// directory; otherwise the folder is created next to the extra.
+func processExtra(path string, info *media.VideoInfo, extraInfo metadata.ExtraSearch, cfg *config.Config) error {
+	fmt.Println("\n=== Extra ===")
+	fmt.Printf("Kind: %s\n", extraInfo.Kind)
+	if extraInfo.Title != "" {
+		parent := extraInfo.Title
+		if extraInfo.Year > 0 {
+			parent = fmt.Sprintf("%s (%d)", parent, extraInfo.Year)
+		}
+		fmt.Printf("Belongs to: %s\n", parent)
+	}
+
+	// Extras already kept in their folder stay where they are
+	parentDir := filepath.Dir(path)
+	if filepath.Base(parentDir) == extraInfo.Kind {
+		fmt.Println("Already in its extras folder")
+		return nil
+	}
+
+	if cfg.OrganizeFiles && cfg.MovieDirectoryTemplate != "" && !extraInfo.TV && extraInfo.Title != "" {
+		dir, err := movieDirectory(path, info, metadata.MovieSearch{Title: extraInfo.Title, Year: extraInfo.Year}, cfg)
+		if err != nil {
+			fmt.Printf("Warning: Failed to look up parent movie: %v\n", err)
+		} else {
+			parentDir = dir
+		}
+	}
+	newFileName := filepath.Join(parentDir, extraInfo.Kind, filepath.Base(path))
+
+	// Show move preview
+	fmt.Println("\n=== File Renaming ===")
+	fmt.Printf("Original: %s\n", path)
+	fmt.Printf("New name: %s\n", newFileName)
+
+	// Skip moving if preview mode
+	if cfg.PreviewMode {
+		fmt.Println("\n[PREVIEW MODE] File would be moved as shown above")
+		return nil
+	}
+
+	// Skip if the target file already exists
+	if cfg.NoOverwrite && fileExists(newFileName) {
+		fmt.Println("\nSkipping move: Target file already exists")
+		return nil
+	}
+
+	// In batch mode, move without confirmation
+	if cfg.BatchMode || confirmRename() {
+		if err := os.MkdirAll(filepath.Dir(newFileName), 0755); err != nil {
+			return fmt.Errorf("error creating extras folder: %v", err)
+		}
+		if err := os.Rename(path, newFileName); err != nil {
+			return fmt.Errorf("error moving file: %v", err)
+		}
+		fmt.Println("File moved successfully!")
+	}
+
 	return nil
 }
 
-func processPath(path string, cfg *config.Config) error {
+// printProvider prints the provider that answered a lookup, and warns about
+// weak matches.
+func printProvider(provider string, confidence float64) {
+	if provider == "" {
+		return
+	}
+	if confidence > 0 && confidence < metadata.MinResultConfidence {
+		fmt.Printf("Provider: %s (weak match, confidence %.0f%%)\n", provider, confidence*100)
+		return
+	}
+	fmt.Printf("Provider: %s\n", provider)
+}
+
+// movieLookups caches movie lookups by search, so the parts of a movie split
+// across files share one lookup and are organized into the same directory.
+var movieLookups = make(map[metadata.MovieSearch]*metadata.MovieMetadata)
+
+// lookupMovie searches for a movie with the provider. Searches that only
+// differ by part or duration share a single lookup.
+func lookupMovie(movieInfo metadata.MovieSearch, provider metadata.MetadataProvider, cfg *config.Config) (*metadata.MovieMetadata, error) {
+	key := movieInfo
+	key.Part = 0
+	key.Duration = 0
+	if cached, ok := movieLookups[key]; ok {
+		movie := *cached
+		movie.Part = movieInfo.Part
+		return &movie, nil
+	}
+
+	movie, err := provider.SearchMovie(movieInfo, cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+	movieLookups[key] = movie
+	return movie, nil
+}
+
+// movieDirectory looks up a movie and returns the directory its file is
+// organized into.
+func movieDirectory(path string, info *media.VideoInfo, movieInfo metadata.MovieSearch, cfg *config.Config) (string, error) {
+	provider, err := metadata.CreateMovieProvider(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to create movie provider: %v", err)
+	}
+	movieMetadata, err := lookupMovie(movieInfo, provider, cfg)
+	if err != nil {
+		return "", err
+	}
+	target, err := generateFilename(path, info, movieMetadata, cfg)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(target), nil
+}
+
+// processRoot processes a file or directory given on the command line. A
+// directory matching the ignore patterns, including those of the
+// .vidkitignore file next to it, is skipped like the directories below it.
+func processRoot(path string, ignores *ignore.Matcher, rules []release.Rule, cfg *config.Config) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("error accessing path: %v", err)
+		}
+		parentIgnores, err := ignores.ForDir(filepath.Dir(absPath))
+		if err != nil {
+			return fmt.Errorf("error reading ignore file: %v", err)
+		}
+		if parentIgnores.Match(absPath) {
+			fmt.Printf("\nSkipping %s: matches an ignore pattern\n", path)
+			return nil
+		}
+	}
+	return processPath(path, ignores, rules, cfg)
+}
+
+// ignored reports whether a file or directory matches the ignore patterns.
+// The path is made absolute, so patterns naming parent directories
+// ("Downloads/incomplete") match however the path was given.
+func ignored(ignores *ignore.Matcher, path string) bool {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	return ignores.Match(path)
+}
+
+// processPath processes a video file or the videos in a directory. Files and
+// directories matching the ignore patterns, including those of the
+// .vidkitignore files along the way, are skipped.
+func processPath(path string, ignores *ignore.Matcher, rules []release.Rule, cfg *config.Config) error {
 	info, err := os.Stat(path)
 	if err != nil {
 		return fmt.Errorf("error accessing path: %v", err)
 	}
 
 	if info.IsDir() {
+		// The ignore file of a directory applies to everything below it
+		ignores, err := ignores.ForDir(path)
+		if err != nil {
+			return fmt.Errorf("error reading ignore file: %v", err)
+		}
+
 		// Process directory
 		entries, err := os.ReadDir(path)
 		if err != nil {
@@ -257,8 +659,13 @@ func processPath(path string, cfg *config.Config) error {
 
 			if entryInfo.IsDir() {
 				if cfg.Recursive {
+					if ignored(ignores, entryPath) {
+						fmt.Printf("\nSkipping %s: matches an ignore pattern\n", entryPath)
+						continue
+					}
+
 					// Process subdirectory recursively
-					if err := processPath(entryPath, cfg); err != nil {
+					if err := processPath(entryPath, ignores, rules, cfg); err != nil {
 						fmt.Printf("Warning: Error processing %s: %v\n", entryPath, err)
 					}
 				}
@@ -273,9 +680,11 @@ func processPath(path string, cfg *config.Config) error {
 					}
 				}
 
-				if isSupported {
+				if isSupported && ignored(ignores, entryPath) {
+					fmt.Printf("\nSkipping %s: matches an ignore pattern\n", entryPath)
+				} else if isSupported {
 					// Process video file
-					if err := processFile(entryPath, cfg); err != nil {
+					if err := processFile(entryPath, rules, cfg); err != nil {
 						fmt.Printf("Warning: Error processing %s: %v\n", entryPath, err)
 					}
 				}
@@ -293,7 +702,16 @@ func processPath(path string, cfg *config.Config) error {
 		}
 
 		if isSupported {
-			if err := processFile(path, cfg); err != nil {
+			// Single files honor the ignore file of their directory as well
+			ignores, err := ignores.ForDir(filepath.Dir(path))
+			if err != nil {
+				return fmt.Errorf("error reading ignore file: %v", err)
+			}
+			if ignored(ignores, path) {
+				fmt.Printf("\nSkipping %s: matches an ignore pattern\n", path)
+				return nil
+			}
+			if err := processFile(path, rules, cfg); err != nil {
 				return fmt.Errorf("error processing file: %v", err)
 			}
 		} else {
@@ -304,200 +722,175 @@ func processPath(path string, cfg *config.Config) error {
 	return nil
 }
 
-func generateFilename(originalPath string, info *media.VideoInfo, metadata *metadata.MovieMetadata, cfg *config.Config) string {
-	ext := filepath.Ext(originalPath)
-	baseDir := filepath.Dir(originalPath)
-	
-	// Find first video stream
-	videoStreamIndex := -1
-	for i, stream := range info.Streams {
-		if stream.CodecType == "video" {
-			videoStreamIndex = i
-			break
-		}
+func generateFilename(originalPath string, info *media.VideoInfo, movie *metadata.MovieMetadata, cfg *config.Config) (string, error) {
+	// Apply movie filename template from configuration
+	template := movieFilenameTemplate(cfg, movie.Part)
+
+	// Only organize into directories when a directory template is configured
+	directoryTemplate := ""
+	if movie.Title != "" && cfg.OrganizeFiles {
+		directoryTemplate = cfg.MovieDirectoryTemplate
 	}
 
-	// Extract resolution
-	resolutionString := "unknown"
-	codec := "unknown"
-	if videoStreamIndex >= 0 {
-		videoStream := info.Streams[videoStreamIndex]
-		resolutionString = resolution.GetStandardResolution(videoStream.Width, videoStream.Height)
-		codec = videoStream.CodecName
+	return buildTargetPath(originalPath, template, directoryTemplate, naming.MovieValues(movie, info), cfg)
+}
+
+func generateTVFilename(originalPath string, info *media.VideoInfo, show *metadata.TVShowMetadata, cfg *config.Config) (string, error) {
+	// Apply TV show filename template from configuration
+	template := cfg.TVFilenameTemplate
+	if template == "" {
+		template = "{title} - {episode_range} - {episode_title}"
 	}
 
-	// Apply movie filename template from configuration
+	// Only organize into directories when a directory template is configured
+	directoryTemplate := ""
+	if cfg.OrganizeFiles {
+		directoryTemplate = cfg.TVDirectoryTemplate
+	}
+
+	return buildTargetPath(originalPath, template, directoryTemplate, naming.TVValues(show, info), cfg)
+}
+
+// movieFilenameTemplate returns the configured movie filename template. Parts
+// of a movie split across files need distinct names, so the part is appended
+// to templates that leave it out.
+func movieFilenameTemplate(cfg *config.Config, part int) string {
 	template := cfg.MovieFilenameTemplate
 	if template == "" {
 		template = "{title} ({year}) [{resolution} {codec}]"
 	}
-
-	// Replace template variables
-	filename := template
-	filename = strings.ReplaceAll(filename, "{title}", metadata.Title)
-	filename = strings.ReplaceAll(filename, "{year}", fmt.Sprintf("%d", metadata.Year))
-	filename = strings.ReplaceAll(filename, "{resolution}", resolutionString)
-	filename = strings.ReplaceAll(filename, "{codec}", codec)
-	
-	// Replace genres if needed
-	if strings.Contains(filename, "{genre}") && len(metadata.Genres) > 0 {
-		filename = strings.ReplaceAll(filename, "{genre}", metadata.Genres[0])
-	} else {
-		filename = strings.ReplaceAll(filename, "{genre}", "Unknown")
+	if part > 0 {
+		if parsed, err := naming.Parse(template); err == nil && !parsed.Uses("part") {
+			template += "< - {part}>"
+		}
 	}
+	return template
+}
 
-	// Apply word separator
-	if cfg.SceneStyle {
-		filename = strings.ReplaceAll(filename, " ", ".")
+// matchTemplates identifies a file by the configured templates. It returns the
+// episode or movie the name describes, with empty searches for names the
+// templates did not produce, and whether the file already has the name and,
+// when organizing, the directory the templates give it.
+func matchTemplates(path string, info *media.VideoInfo, cfg *config.Config) (metadata.TVShowSearch, metadata.MovieSearch, bool) {
+	opts, err := namingOptions(cfg)
+	if err != nil {
+		return metadata.TVShowSearch{}, metadata.MovieSearch{}, false
 	}
-
-	// Apply lowercase if requested
-	if cfg.Lowercase {
-		filename = strings.ToLower(filename)
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
 	}
 
-	// Create organized output directory structure using templates if configured
-	if metadata.Title != "" && cfg.OrganizeFiles && cfg.MovieDirectoryTemplate != "" {
-		// Apply movie directory template
-		dirTemplate := cfg.MovieDirectoryTemplate
-		if dirTemplate == "" {
-			dirTemplate = "Movies/{title} ({year})"
-		}
-		directory := dirTemplate
-		directory = strings.ReplaceAll(directory, "{title}", metadata.Title)
-		directory = strings.ReplaceAll(directory, "{year}", fmt.Sprintf("%d", metadata.Year))
-		if strings.Contains(directory, "{genre}") && len(metadata.Genres) > 0 {
-			directory = strings.ReplaceAll(directory, "{genre}", metadata.Genres[0])
-		} else {
-			directory = strings.ReplaceAll(directory, "{genre}", "Unknown")
-		}
-		if cfg.SceneStyle {
-			directory = strings.ReplaceAll(directory, " ", ".")
+	tvDirectory, movieDirectory := "", ""
+	if cfg.OrganizeFiles {
+		tvDirectory, movieDirectory = cfg.TVDirectoryTemplate, cfg.MovieDirectoryTemplate
+	}
+	if values, conforms, ok := matchTemplate(path, info, cfg.TVFilenameTemplate, tvDirectory, opts); ok {
+		if search := naming.TVShowSearch(values); search.IsEpisode() {
+			return search, metadata.MovieSearch{}, conforms
 		}
-		if cfg.Lowercase {
-			directory = strings.ToLower(directory)
+	}
+	// The part is optional, so one template matches complete movies and parts
+	if values, conforms, ok := matchTemplate(path, info, movieFilenameTemplate(cfg, 1), movieDirectory, opts); ok {
+		if search := naming.MovieSearch(values); search.Title != "" {
+			return metadata.TVShowSearch{}, search, conforms
 		}
-		fullPath := filepath.Join(baseDir, directory, filename+ext)
-		return fullPath
 	}
-	
-	// If no directory organization, just return the filename
-	return filepath.Join(baseDir, filename+ext)
+	return metadata.TVShowSearch{}, metadata.MovieSearch{}, false
 }
 
-func generateTVFilename(originalPath string, info *media.VideoInfo, metadata *metadata.TVShowMetadata, cfg *config.Config) string {
-	ext := filepath.Ext(originalPath)
-	baseDir := filepath.Dir(originalPath)
-	
-	// Find first video stream
-	videoStreamIndex := -1
-	for i, stream := range info.Streams {
-		if stream.CodecType == "video" {
-			videoStreamIndex = i
-			break
+// matchTemplate matches a path against a filename template, and a directory
+// template when one is given. A file whose directories do not match is still
+// identified by its name, but does not conform.
+func matchTemplate(path string, info *media.VideoInfo, filenameTemplate, directoryTemplate string, opts naming.Options) (naming.Values, bool, bool) {
+	if directoryTemplate != "" {
+		if m, err := naming.NewMatcher(filenameTemplate, directoryTemplate, opts); err == nil {
+			if values, ok := m.Match(path, info); ok {
+				return values, true, true
+			}
 		}
 	}
-
-	// Extract resolution
-	resolutionString := "unknown"
-	codec := "unknown"
-	if videoStreamIndex >= 0 {
-		videoStream := info.Streams[videoStreamIndex]
-		resolutionString = resolution.GetStandardResolution(videoStream.Width, videoStream.Height)
-		codec = videoStream.CodecName
+	m, err := naming.NewMatcher(filenameTemplate, "", opts)
+	if err != nil {
+		return nil, false, false
 	}
+	values, ok := m.Match(path, info)
+	return values, ok && directoryTemplate == "", ok
+}
 
-	// Apply TV show filename template from configuration
-	template := cfg.TVFilenameTemplate
-	if template == "" {
-		template = "{title} - S{season:02d}E{episode:02d} - {episode_title}"
-	}
-
-	// Replace template variables
-	filename := template
-	filename = strings.ReplaceAll(filename, "{title}", metadata.Title)
-	filename = strings.ReplaceAll(filename, "{year}", fmt.Sprintf("%d", metadata.Year))
-	filename = strings.ReplaceAll(filename, "{resolution}", resolutionString)
-	filename = strings.ReplaceAll(filename, "{codec}", codec)
-	
-	// Handle any special formatting for season/episode
-	if strings.Contains(filename, "{season:02d}") {
-		filename = strings.ReplaceAll(filename, "{season:02d}", fmt.Sprintf("%02d", metadata.Season))
-	} else {
-		filename = strings.ReplaceAll(filename, "{season}", fmt.Sprintf("%d", metadata.Season))
+// namingOptions returns the naming options of the configuration.
+func namingOptions(cfg *config.Config) (naming.Options, error) {
+	sanitizer, err := naming.NewSanitizer(cfg.TargetFilesystem, cfg.UnicodeNormalization)
+	if err != nil {
+		return naming.Options{}, err
+	}
+	return naming.Options{
+		Separator:  cfg.Separator,
+		SceneStyle: cfg.SceneStyle,
+		Lowercase:  cfg.Lowercase,
+		Sanitizer:  sanitizer,
+		Articles:   naming.ArticlesFor(cfg.Language, cfg.Articles),
+	}, nil
+}
+
+// buildTargetPath renders the templates and joins the result with the
+// directory of the original file.
+func buildTargetPath(originalPath, filenameTemplate, directoryTemplate string, values naming.Values, cfg *config.Config) (string, error) {
+	opts, err := namingOptions(cfg)
+	if err != nil {
+		return "", err
 	}
-	
-	if strings.Contains(filename, "{episode:02d}") {
-		filename = strings.ReplaceAll(filename, "{episode:02d}", fmt.Sprintf("%02d", metadata.Episode))
-	} else {
-		filename = strings.ReplaceAll(filename, "{episode}", fmt.Sprintf("%d", metadata.Episode))
-	}
-	
-	filename = strings.ReplaceAll(filename, "{episode_title}", metadata.EpisodeTitle)
-	
-	// Replace network if needed
-	if strings.Contains(filename, "{network}") {
-		filename = strings.ReplaceAll(filename, "{network}", metadata.Network)
-	}
-	
-	// Replace genres if needed
-	if strings.Contains(filename, "{genre}") && len(metadata.Genres) > 0 {
-		filename = strings.ReplaceAll(filename, "{genre}", metadata.Genres[0])
-	} else {
-		filename = strings.ReplaceAll(filename, "{genre}", "Unknown")
+	directory, filename, err := naming.Render(filenameTemplate, directoryTemplate, filepath.Ext(originalPath), values, opts)
+	if err != nil {
+		return "", fmt.Errorf("error generating filename: %v", err)
 	}
+	return filepath.Join(filepath.Dir(originalPath), directory, filename), nil
+}
 
-	// Apply word separator
-	if cfg.SceneStyle {
-		filename = strings.ReplaceAll(filename, " ", ".")
+// validateTemplates parses every configured template and checks the naming
+// settings so mistakes are reported before any file is processed.
+func validateTemplates(cfg *config.Config) error {
+	templates := map[string]string{
+		"movie filename template":  cfg.MovieFilenameTemplate,
+		"TV filename template":     cfg.TVFilenameTemplate,
+		"movie directory template": cfg.MovieDirectoryTemplate,
+		"TV directory template":    cfg.TVDirectoryTemplate,
+	}
+	for name, text := range templates {
+		if _, err := naming.Parse(text); err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
 	}
-
-	// Apply lowercase if requested
-	if cfg.Lowercase {
-		filename = strings.ToLower(filename)
+	if _, err := naming.NewSanitizer(cfg.TargetFilesystem, cfg.UnicodeNormalization); err != nil {
+		return err
+	}
+	if cfg.EpisodeTitleStyle != "" && !slices.Contains(metadata.EpisodeTitleStyles, cfg.EpisodeTitleStyle) {
+		return fmt.Errorf("unknown episode title style %q (supported: %s)", cfg.EpisodeTitleStyle, strings.Join(metadata.EpisodeTitleStyles, ", "))
 	}
+	if cfg.EmbeddedTags != "" && !slices.Contains(metadata.EmbeddedTagsModes, cfg.EmbeddedTags) {
+		return fmt.Errorf("unknown embedded tags mode %q (supported: %s)", cfg.EmbeddedTags, strings.Join(metadata.EmbeddedTagsModes, ", "))
+	}
+	return nil
+}
 
-	// Create organized output directory structure using templates if configured
-	if cfg.OrganizeFiles && cfg.TVDirectoryTemplate != "" {
-		// Apply TV show directory template
-		dirTemplate := cfg.TVDirectoryTemplate
-		if dirTemplate == "" {
-			dirTemplate = "TV/{title}/Season {season:02d}"
-		}
-		directory := dirTemplate
-		directory = strings.ReplaceAll(directory, "{title}", metadata.Title)
-		directory = strings.ReplaceAll(directory, "{year}", fmt.Sprintf("%d", metadata.Year))
-		if strings.Contains(directory, "{season:02d}") {
-			directory = strings.ReplaceAll(directory, "{season:02d}", fmt.Sprintf("%02d", metadata.Season))
-		} else {
-			directory = strings.ReplaceAll(directory, "{season}", fmt.Sprintf("%d", metadata.Season))
-		}
-		if strings.Contains(directory, "{network}") {
-			directory = strings.ReplaceAll(directory, "{network}", metadata.Network)
-		}
-		if strings.Contains(directory, "{genre}") && len(metadata.Genres) > 0 {
-			directory = strings.ReplaceAll(directory, "{genre}", metadata.Genres[0])
-		} else {
-			directory = strings.ReplaceAll(directory, "{genre}", "Unknown")
+// parseRules compiles the user-defined parsing rules of the configuration.
+func parseRules(cfg *config.Config) ([]release.Rule, error) {
+	rules := make([]release.Rule, 0, len(cfg.ParseRules))
+	for i, r := range cfg.ParseRules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
 		}
-		if cfg.SceneStyle {
-			directory = strings.ReplaceAll(directory, " ", ".")
-		}
-		if cfg.Lowercase {
-			directory = strings.ToLower(directory)
+		rule, err := release.NewRule(name, r.Pattern, r.Scope, r.Path)
+		if err != nil {
+			return nil, err
 		}
-		fullPath := filepath.Join(baseDir, directory, filename+ext)
-		return fullPath
+		rules = append(rules, rule)
 	}
-	
-	// If no directory organization, just return the filename
-	return filepath.Join(baseDir, filename+ext)
+	return rules, nil
 }
 
 func confirmRename() bool {
-	fmt.Print("\nDo you want to rename the file? (y/N): ")
-	var response string
-	fmt.Scanln(&response)
+	response, _ := readLine("\nDo you want to rename the file? (y/N): ")
 	return strings.ToLower(response) == "y"
 }
 
@@ -506,25 +899,47 @@ func fileExists(path string) bool {
 	return !os.IsNotExist(err)
 }
 
+// providerFlag collects the values of a repeatable provider flag. A value may
+// also list several providers separated by commas.
+type providerFlag []string
+
+func (f *providerFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *providerFlag) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*f = append(*f, name)
+		}
+	}
+	return nil
+}
+
 func main() {
 	// Define command-line flags
 	batchMode := flag.Bool("batch", false, "Process files without prompting")
 	recursive := flag.Bool("recursive", false, "Process directories recursively")
 	lowercase := flag.Bool("lowercase", false, "Convert filenames to lowercase")
-	sceneStyle := flag.Bool("scene-style", false, "Use dots instead of spaces (scene style)")
+	sceneStyle := flag.Bool("scene-style", false, "Use scene-style names (dots as separator, no apostrophes or commas)")
 	organize := flag.Bool("organize", false, "Organize files into directories")
 	noOverwrite := flag.Bool("no-overwrite", false, "Don't overwrite existing files")
 	noMetadata := flag.Bool("no-metadata", false, "Skip metadata lookup")
 	previewMode := flag.Bool("preview", false, "Preview mode (don't modify files)")
 	showVersion := flag.Bool("version", false, "Show version information")
-	
+	renameAll := flag.Bool("rename-all", false, "Also process files already named by the configured templates")
+
 	// Language and filename template options
 	lang := flag.String("lang", "en", "Metadata language (ISO 639-1 code)")
 	movieFilenameTemplate := flag.String("movie-filename-template", "", "Template for movie filenames (e.g., '{title} ({year}) [{resolution}]')")
 	tvFilenameTemplate := flag.String("tv-filename-template", "", "Template for TV show filenames (e.g., '{title} S{season:02d}E{episode:02d} {episode_title}')")
-	separator := flag.String("separator", "", "Character to use as separator in filenames")
-	movieProvider := flag.String("movie-provider", "", "Select movie metadata provider (tmdb, omdb)")
-	tvProvider := flag.String("tv-provider", "", "Select TV show metadata provider (tvmaze, tvdb)")
+	separator := flag.String("separator", "", "Word separator for filenames and directories (e.g., '.', '_', '-')")
+	targetFS := flag.String("target-fs", "", "Target filesystem naming rules (posix, windows, smb, exfat)")
+	preset := flag.String("preset", "", "Naming preset for media servers ("+strings.Join(config.PresetNames(), ", ")+"); template flags override individual pieces")
+	var movieProviders, tvProviders providerFlag
+	flag.Var(&movieProviders, "movie-provider", "Select movie metadata provider (tmdb, omdb); repeat to try several in order")
+	flag.Var(&tvProviders, "tv-provider", "Select TV show metadata provider (tvmaze, tvdb, tmdb, omdb); repeat to try several in order")
+	embeddedTags := flag.String("embedded-tags", "", "How tags embedded in video files identify them ("+strings.Join(metadata.EmbeddedTagsModes, ", ")+")")
 
 	// Directory organization templates
 	movieDirectoryTemplate := flag.String("movie-directory-template", "", "Template for movie directory organization (e.g., 'Movies/{genre}/{title} ({year})')")
@@ -545,6 +960,14 @@ func main() {
 		cfg = config.DefaultConfig()
 	}
 
+	// Apply the naming preset first so the flags below can override individual pieces
+	if *preset != "" {
+		if err := cfg.ApplyPreset(*preset); err != nil {
+			fmt.Printf("Error in configuration: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Override config with command-line flags
 	if *batchMode {
 		cfg.BatchMode = true
@@ -566,6 +989,10 @@ func main() {
 		cfg.Separator = *separator
 	}
 
+	if *targetFS != "" {
+		cfg.TargetFilesystem = *targetFS
+	}
+
 	if !*noOverwrite {
 		cfg.NoOverwrite = false
 	}
@@ -582,6 +1009,14 @@ func main() {
 		cfg.Language = *lang
 	}
 
+	if *embeddedTags != "" {
+		cfg.EmbeddedTags = *embeddedTags
+	}
+
+	if *renameAll {
+		cfg.SkipConforming = false
+	}
+
 	if *movieFilenameTemplate != "" {
 		cfg.MovieFilenameTemplate = *movieFilenameTemplate
 	}
@@ -590,27 +1025,49 @@ func main() {
 		cfg.TVFilenameTemplate = *tvFilenameTemplate
 	}
 
-	// Apply provider selection from flags
-	if *movieProvider != "" {
-		switch *movieProvider {
-		case "tmdb":
-			cfg.MovieProvider = config.ProviderTMDb
-		case "omdb":
-			cfg.MovieProvider = config.ProviderOMDb
-		default:
-			fmt.Printf("Warning: Unknown movie provider '%s', using default\n", *movieProvider)
+	// Apply provider selection from flags; repeated flags replace the
+	// configured provider lists
+	if len(movieProviders) > 0 {
+		var providers []config.ProviderType
+		for _, name := range movieProviders {
+			switch name {
+			case "tmdb":
+				providers = append(providers, config.ProviderTMDb)
+			case "omdb":
+				providers = append(providers, config.ProviderOMDb)
+			default:
+				fmt.Printf("Warning: Unknown movie provider '%s', ignoring it\n", name)
+			}
+		}
+		if len(providers) > 0 {
+			cfg.MovieProvider, cfg.MovieProviders = providers[0], providers
 		}
 	}
 
-	if *tvProvider != "" {
-		switch *tvProvider {
-		case "tvmaze":
-			cfg.TVProvider = config.ProviderTVMaze
-		case "tvdb":
-			cfg.TVProvider = config.ProviderTVDb
-		default:
-			fmt.Printf("Warning: Unknown TV provider '%s', using default\n", *tvProvider)
+	if len(tvProviders) > 0 {
+		var providers []config.ProviderType
+		for _, name := range tvProviders {
+			switch name {
+			case "tvmaze":
+				providers = append(providers, config.ProviderTVMaze)
+			case "tvdb":
+				providers = append(providers, config.ProviderTVDb)
+			case "tmdb":
+				providers = append(providers, config.ProviderTMDb)
+			case "omdb":
+				providers = append(providers, config.ProviderOMDb)
+			default:
+				fmt.Printf("Warning: Unknown TV provider '%s', ignoring it\n", name)
+			}
 		}
+		if len(providers) > 0 {
+			cfg.TVProvider, cfg.TVProviders = providers[0], providers
+		}
+	}
+
+	// "vidkit parse" never looks up metadata, so it needs no API keys
+	if flag.Arg(0) == "parse" {
+		cfg.NoMetadata = true
 	}
 
 	// Set config back so it's available for next time
@@ -620,20 +1077,63 @@ func main() {
 	}
 
 	// Set organize flags
-	cfg.OrganizeFiles = *organize
-	cfg.MovieDirectoryTemplate = *movieDirectoryTemplate
-	cfg.TVDirectoryTemplate = *tvDirectoryTemplate
+	if *organize {
+		cfg.OrganizeFiles = true
+	}
+	if *movieDirectoryTemplate != "" {
+		cfg.MovieDirectoryTemplate = *movieDirectoryTemplate
+	}
+	if *tvDirectoryTemplate != "" {
+		cfg.TVDirectoryTemplate = *tvDirectoryTemplate
+	}
+
+	if err := validateTemplates(cfg); err != nil {
+		fmt.Printf("Error in configuration: %v\n", err)
+		os.Exit(1)
+	}
+	rules, err := parseRules(cfg)
+	if err != nil {
+		fmt.Printf("Error in configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// "vidkit parse <name>..." shows how names are identified without touching
+	// any file, using the same templates and rules as a real run
+	if flag.Arg(0) == "parse" {
+		if err := runParse(flag.Args()[1:], rules, cfg); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// "vidkit review" goes through the files batch runs left for review
+	if flag.Arg(0) == "review" {
+		if err := runReview(rules, cfg); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Check if we have any paths to process
 	if flag.NArg() == 0 {
 		fmt.Println("Usage: vidkit [options] <file_or_directory>")
+		fmt.Println("       vidkit parse <name>...")
+		fmt.Println("       vidkit review")
 		flag.PrintDefaults()
 		return
 	}
 
+	ignores, err := ignore.New(cfg.IgnorePatterns)
+	if err != nil {
+		fmt.Printf("Error in configuration: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Process each path
 	for _, path := range flag.Args() {
-		if err := processPath(path, cfg); err != nil {
+		if err := processRoot(path, ignores, rules, cfg); err != nil {
 			fmt.Printf("Error: %v\n", err)
 		}
 	}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+func TestIdentifyFile(t *testing.T) {
+	const episode = "/tv/Breaking.Bad.S01E05.Gray.Matter.720p.BluRay.x264-DEMAND.mkv"
+
+	tests := []struct {
+		name       string
+		path       string
+		tags       map[string]string
+		mode       string
+		wantTV     string // Title of the episode's show; empty for movies
+		wantMovie  string
+		wantSeason int
+	}{
+		{
+			name:       "Episode title tag",
+			path:       episode,
+			tags:       map[string]string{"title": "Gray Matter"},
+			wantTV:     "Breaking Bad",
+			wantSeason: 1,
+		},
+		{
+			name:       "Episode title tag preferred",
+			path:       episode,
+			tags:       map[string]string{"title": "Gray Matter"},
+			mode:       metadata.EmbeddedTagsPrefer,
+			wantTV:     "Breaking Bad",
+			wantSeason: 1,
+		},
+		{
+			name:       "Release name tag",
+			path:       episode,
+			tags:       map[string]string{"title": "Breaking.Bad.S01E05.Gray.Matter.720p.BluRay.x264-DEMAND"},
+			wantTV:     "Breaking Bad",
+			wantSeason: 1,
+		},
+		{
+			name:       "Show tags",
+			path:       "/tv/Gray Matter.mkv",
+			tags:       map[string]string{"show": "Breaking Bad", "season_number": "1", "episode_sort": "5"},
+			wantTV:     "Breaking Bad",
+			wantSeason: 1,
+		},
+		{
+			name:      "Movie tags beat a weak filename",
+			path:      "/movies/movie_final.mkv",
+			tags:      map[string]string{"title": "Inception", "date": "2010-07-16"},
+			wantMovie: "Inception",
+		},
+		{
+			name:      "Movie IDs beat an episode filename",
+			path:      episode,
+			tags:      map[string]string{"title": "Inception", "imdb": "tt1375666"},
+			wantMovie: "Inception",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			if tt.mode != "" {
+				cfg.EmbeddedTags = tt.mode
+			}
+			tv, movie := identifyFile(tt.path, tt.tags, metadata.TVShowSearch{}, metadata.MovieSearch{}, nil, cfg)
+			isTV := tv.IsEpisode() && tv.Confidence >= movie.Confidence
+			switch {
+			case tt.wantTV != "":
+				if !isTV || tv.Title != tt.wantTV || tv.Season != tt.wantSeason {
+					t.Errorf("identifyFile() = %+v / %+v, want an episode of %s season %d", tv, movie, tt.wantTV, tt.wantSeason)
+				}
+			case isTV || movie.Title != tt.wantMovie:
+				t.Errorf("identifyFile() = %+v / %+v, want the movie %s", tv, movie, tt.wantMovie)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
+	"github.com/tekenstam/vidkit/internal/pkg/release"
+)
+
+// runParse implements "vidkit parse <name>...": it prints how each name is
+// identified, including the parsing rule that matched, without looking up
+// metadata or touching any file. Directories in a name are used just like
+// those of real files.
+func runParse(names []string, rules []release.Rule, cfg *config.Config) error {
+	if len(names) == 0 {
+		return fmt.Errorf("usage: vidkit parse <name>...")
+	}
+
+	for _, name := range names {
+		// The more confident interpretation wins, like for files being
+		// processed
+		kind := "TV episode"
+		parsed, rule := release.ParsePathWithRules(name, release.ScopeTV, rules)
+		confidence := parsed.EpisodeConfidence()
+		movie, movieRule := release.ParsePathWithRules(name, release.ScopeMovie, rules)
+		isExtra := movie.Extra != "" && !movie.IsEpisode()
+		if isExtra || !parsed.IsEpisode() || movie.MovieConfidence() > confidence {
+			kind = "Movie"
+			parsed, rule, confidence = movie, movieRule, movie.MovieConfidence()
+			if parsed.Extra != "" {
+				kind = "Extra (" + parsed.Extra + ")"
+			}
+		}
+		if rule == "" {
+			rule = "built-in"
+		}
+
+		// Names rendered from the configured templates parse back exactly
+		if tv, movie, _ := matchTemplates(name, nil, cfg); tv.IsEpisode() {
+			kind, rule, confidence = "TV episode", "template", tv.Confidence
+			parsed.Title, parsed.Year, parsed.Season, parsed.Special = tv.Title, tv.Year, tv.Season, tv.Special
+			parsed.Episodes, parsed.AbsoluteEpisode, parsed.AirDate, parsed.EpisodeTitle = tv.Episodes, tv.AbsoluteEpisode, tv.AirDate, tv.EpisodeTitle
+			if len(parsed.Episodes) == 0 && tv.Episode > 0 {
+				parsed.Episodes = []int{tv.Episode}
+			}
+		} else if movie.Title != "" {
+			kind, rule, confidence = "Movie", "template", movie.Confidence
+			parsed.Title, parsed.Year, parsed.Edition, parsed.Part = movie.Title, movie.Year, movie.Edition, movie.Part
+		}
+
+		fmt.Printf("\n=== Parse: %s ===\n", name)
+		printField("Rule", rule)
+		printField("Type", kind)
+		printField("Confidence", fmt.Sprintf("%.0f%%", confidence*100))
+		printField("Title", parsed.Title)
+		printField("Year", formatNumber(parsed.Year))
+		printField("Edition", parsed.Edition)
+		printField("Part", formatNumber(parsed.Part))
+		if parsed.Special {
+			printField("Season", "0 (specials)")
+		} else {
+			printField("Season", formatNumber(parsed.Season))
+		}
+		printField("Episodes", joinNumbers(parsed.Episodes))
+		printField("Absolute Episode", formatNumber(parsed.AbsoluteEpisode))
+		printField("Air Date", parsed.AirDate)
+		printField("Episode Title", parsed.EpisodeTitle)
+		printField("Resolution", parsed.Resolution)
+		printField("Source", parsed.Source)
+		printField("Video Codec", parsed.VideoCodec)
+		printField("Audio Codec", parsed.AudioCodec)
+		printField("Audio Channels", parsed.AudioChannels)
+		printField("HDR", strings.Join(parsed.HDR, ", "))
+		printField("Streaming Service", parsed.StreamingService)
+		printField("Release Group", parsed.ReleaseGroup)
+		printField("Languages", strings.Join(parsed.Languages, ", "))
+		if parsed.Proper {
+			printField("Proper", "yes")
+		}
+		if parsed.Repack {
+			printField("Repack", "yes")
+		}
+		printField("Container", parsed.Container)
+	}
+	return nil
+}
+
+// printField prints a parsed field, skipping fields the name does not mention.
+func printField(label, value string) {
+	if value != "" {
+		fmt.Printf("%-18s %s\n", label+":", value)
+	}
+}
+
+// formatNumber formats a parsed number, leaving unset numbers empty.
+func formatNumber(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// joinNumbers formats a list of episode numbers.
+func joinNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
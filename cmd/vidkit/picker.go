@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+	"github.com/tekenstam/vidkit/internal/pkg/release"
+	"github.com/tekenstam/vidkit/internal/pkg/review"
+)
+
+// maxCandidates is the number of search results the picker lists.
+const maxCandidates = 5
+
+// stdin reads the answers to prompts line by line.
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine prints a prompt and returns the line the user typed, without
+// surrounding spaces. It returns false at the end of the input.
+func readLine(prompt string) (string, bool) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// decision is what happens to a file after its lookup.
+type decision int
+
+const (
+	decideSkip      decision = iota // Leave the file alone
+	decideRename                    // Rename the file as shown
+	decideRenameAll                 // Rename, and use the same title for the remaining files of the show or split movie
+	decideLookup                    // Look the file up again with a changed search
+)
+
+// pick is a title picked in the picker or typed in by the user.
+type pick struct {
+	Title      string // New title to search for; empty to keep the searched one
+	Year       int
+	IMDbID     string
+	Provider   string // Provider and ID of a picked title
+	ProviderID string
+}
+
+func (p pick) String() string {
+	switch {
+	case p.ProviderID != "":
+		return p.Provider + ":" + p.ProviderID
+	case p.IMDbID != "":
+		return p.IMDbID
+	case p.Year > 0:
+		return fmt.Sprintf("'%s' (%d)", p.Title, p.Year)
+	}
+	return fmt.Sprintf("'%s'", p.Title)
+}
+
+// movieSearch returns the search changed by the pick. IDs from the embedded
+// tags give way to the user's choice.
+func (p pick) movieSearch(search metadata.MovieSearch) metadata.MovieSearch {
+	if p.Title != "" {
+		search.Title, search.Year = p.Title, p.Year
+	}
+	search.IMDbID, search.TMDbID = p.IMDbID, 0
+	search.Provider, search.ProviderID = p.Provider, p.ProviderID
+	return search
+}
+
+// tvShowSearch returns the search changed by the pick. IDs from the embedded
+// tags give way to the user's choice.
+func (p pick) tvShowSearch(search metadata.TVShowSearch) metadata.TVShowSearch {
+	if p.Title != "" {
+		search.Title, search.Year = p.Title, p.Year
+	}
+	search.IMDbID, search.TMDbID = p.IMDbID, 0
+	search.Provider, search.ProviderID = p.Provider, p.ProviderID
+	return search
+}
+
+// showPicks holds the titles the user picked for all remaining files of a
+// show or of a movie split across files, keyed by pickKey of the original
+// search.
+var showPicks = make(map[string]pick)
+
+// pickKey identifies the files of a show or movie by their kind
+// (release.ScopeTV or release.ScopeMovie) and the title and year they were
+// identified as, so a show and a movie of the same name do not share picks.
+func pickKey(kind, title string, year int) string {
+	return fmt.Sprintf("%s:%s (%d)", kind, strings.ToLower(title), year)
+}
+
+// lookupResult is the outcome of a lookup, as far as deciding about the
+// rename is concerned.
+type lookupResult struct {
+	path       string
+	search     string // Description of the search
+	match      string // Title and year found; empty when the lookup failed
+	provider   string // Provider and ID of the title found
+	id         string
+	confidence float64 // How well the title found matches the search
+	shared     string  // What other files share the title: "show", or "movie" for the parts of a movie; empty for single files
+
+	// candidates returns the ranked search results for the picker
+	candidates func() ([]metadata.Candidate, error)
+	providers  []config.ProviderType
+}
+
+// decide decides what happens to a file after its lookup. Batch runs rename
+// good matches and queue weak ones for review; interactive runs ask the user
+// in the candidate picker.
+func decide(r lookupResult, cfg *config.Config) (decision, pick) {
+	if cfg.BatchMode {
+		if r.confidence < metadata.MinResultConfidence {
+			queueForReview(r)
+			return decideSkip, pick{}
+		}
+		return decideRename, pick{}
+	}
+
+	candidates, err := r.candidates()
+	if err != nil && r.match != "" {
+		fmt.Printf("Warning: Failed to list other search results: %v\n", err)
+	}
+	return choose(r, candidates)
+}
+
+// queueForReview adds a file with a weak match, or without any match, to the
+// review queue.
+func queueForReview(r lookupResult) {
+	queue, err := review.Load(config.ReviewQueuePath())
+	if err == nil {
+		queue.Add(review.Entry{
+			Path:       r.path,
+			Search:     r.search,
+			Match:      r.match,
+			Provider:   r.provider,
+			Confidence: r.confidence,
+		})
+		err = queue.Save()
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to queue the file for review: %v\n", err)
+		return
+	}
+	reason := fmt.Sprintf("weak match (confidence %.0f%%)", r.confidence*100)
+	if r.match == "" {
+		reason = "no match found"
+	}
+	fmt.Printf("\nQueued for review: %s; run \"vidkit review\" to pick the title\n", reason)
+}
+
+// choose lists the search results and asks the user what to do with the
+// file, until the answer is valid. Files are skipped at the end of the input.
+func choose(r lookupResult, candidates []metadata.Candidate) (decision, pick) {
+	if len(candidates) > 0 {
+		fmt.Println("\n=== Search Results ===")
+		for i, c := range candidates {
+			marker := " "
+			if c.Provider == r.provider && c.ID == r.id {
+				marker = "*"
+			}
+			fmt.Printf("%s %d) %s\n", marker, i+1, describeCandidate(c))
+			if c.Overview != "" {
+				fmt.Printf("     %s\n", snippet(c.Overview, 72))
+			}
+		}
+	}
+
+	var options []string
+	if r.match != "" {
+		options = append(options, "[Enter] rename")
+	}
+	switch len(candidates) {
+	case 0:
+	case 1:
+		options = append(options, "[1] pick the result")
+	default:
+		options = append(options, fmt.Sprintf("[1-%d] pick a result", len(candidates)))
+	}
+	options = append(options, "[e] enter a search", "[i] enter an ID")
+	if r.match != "" && r.shared != "" {
+		options = append(options, "[a] rename and use for the rest of the "+r.shared)
+	}
+	options = append(options, "[s] skip")
+	prompt := "\n" + strings.Join(options, ", ") + ": "
+
+	for {
+		answer, ok := readLine(prompt)
+		if !ok {
+			return decideSkip, pick{}
+		}
+		switch answer = strings.ToLower(answer); answer {
+		case "", "y":
+			if r.match != "" {
+				return decideRename, pick{}
+			}
+		case "a":
+			if r.match != "" && r.shared != "" {
+				return decideRenameAll, pick{Provider: r.provider, ProviderID: r.id}
+			}
+		case "s", "n":
+			return decideSkip, pick{}
+		case "e":
+			if text, _ := readLine("Search for (title, optionally followed by the year in parentheses): "); text != "" {
+				return decideLookup, parseSearchText(text)
+			}
+			continue
+		case "i":
+			text, _ := readLine("ID (IMDb ID such as tt0386676, or provider:ID such as tvmaze:526): ")
+			p, err := parseID(text, r.providers)
+			if err != nil {
+				fmt.Printf("Invalid ID: %v\n", err)
+				continue
+			}
+			return decideLookup, p
+		default:
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(candidates) {
+				c := candidates[n-1]
+				return decideLookup, pick{Provider: c.Provider, ProviderID: c.ID}
+			}
+		}
+		fmt.Printf("Unknown choice '%s'\n", answer)
+	}
+}
+
+// describeCandidate returns a line describing a search result: its title,
+// year, network, provider ID and score.
+func describeCandidate(c metadata.Candidate) string {
+	desc := titleWithYear(c.Title, c.Year)
+	if c.Network != "" {
+		desc += " - " + c.Network
+	}
+	return fmt.Sprintf("%s [%s:%s] %.0f%%", desc, c.Provider, c.ID, c.Score*100)
+}
+
+// titleWithYear returns a title followed by its year, when known.
+func titleWithYear(title string, year int) string {
+	if year > 0 {
+		return fmt.Sprintf("%s (%d)", title, year)
+	}
+	return title
+}
+
+// snippet shortens text to at most n characters, ending at a word.
+func snippet(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	runes := []rune(text)[:n]
+	if i := strings.LastIndex(string(runes), " "); i > 0 {
+		return string(runes)[:i] + "..."
+	}
+	return string(runes) + "..."
+}
+
+// searchYearPattern matches a search typed by the user that ends with a year
+// in parentheses: "The Office (2005)"
+var searchYearPattern = regexp.MustCompile(`^(.+?)\s*\((\d{4})\)$`)
+
+// parseSearchText returns the search typed by the user.
+func parseSearchText(text string) pick {
+	if m := searchYearPattern.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[2])
+		return pick{Title: m[1], Year: year}
+	}
+	return pick{Title: text}
+}
+
+// imdbIDPattern matches IMDb IDs: tt0386676
+var imdbIDPattern = regexp.MustCompile(`^tt\d+$`)
+
+// parseID returns the title named by an ID typed by the user: an IMDb ID, an
+// ID at one of the configured providers ("tvmaze:526"), or an ID at the
+// first configured provider.
+func parseID(text string, providers []config.ProviderType) (pick, error) {
+	text = strings.TrimSpace(text)
+	if len(providers) == 0 {
+		return pick{}, fmt.Errorf("no providers configured")
+	}
+	if imdbIDPattern.MatchString(text) {
+		if !slices.ContainsFunc(providers, func(p config.ProviderType) bool { return p != config.ProviderTVDb }) {
+			return pick{}, fmt.Errorf("TVDb cannot look up IMDb IDs")
+		}
+		return pick{IMDbID: text}, nil
+	}
+
+	provider, id := providers[0], text
+	if name, rest, found := strings.Cut(text, ":"); found {
+		provider, id = config.ProviderType(strings.ToLower(name)), rest
+		if !slices.Contains(providers, provider) {
+			return pick{}, fmt.Errorf("%s is not a configured provider", name)
+		}
+	}
+	switch {
+	case id == "":
+		return pick{}, fmt.Errorf("no ID given")
+	case provider == config.ProviderOMDb && !imdbIDPattern.MatchString(id):
+		return pick{}, fmt.Errorf("OMDb IDs are IMDb IDs such as tt0386676")
+	case provider != config.ProviderOMDb:
+		if _, err := strconv.Atoi(id); err != nil {
+			return pick{}, fmt.Errorf("%s IDs are numbers", provider)
+		}
+	}
+	return pick{Provider: string(provider), ProviderID: id}, nil
+}
+
+// runReview implements "vidkit review": it goes through the files that batch
+// runs queued for review, asking about each in the candidate picker.
+// Reviewed files leave the queue, as do files that no longer exist.
+func runReview(rules []release.Rule, cfg *config.Config) error {
+	queue, err := review.Load(config.ReviewQueuePath())
+	if err != nil {
+		return err
+	}
+	if len(queue.Entries) == 0 {
+		fmt.Println("No files are waiting for review")
+		return nil
+	}
+
+	// Reviewing is interactive, whatever the configuration says
+	cfg.BatchMode = false
+	for _, entry := range slices.Clone(queue.Entries) {
+		fmt.Printf("\n=== Review: %s ===\n", entry.Path)
+		if entry.Match == "" {
+			fmt.Printf("Identified as %s, no match found\n", entry.Search)
+		} else {
+			fmt.Printf("Identified as %s, best match %s (confidence %.0f%%)\n", entry.Search, entry.Match, entry.Confidence*100)
+		}
+		if !fileExists(entry.Path) {
+			fmt.Println("Removing it from the queue: the file no longer exists")
+			queue.Remove(entry.Path)
+			continue
+		}
+		if err := processFile(entry.Path, rules, cfg); err != nil {
+			fmt.Printf("Warning: Error processing %s: %v\n", entry.Path, err)
+			continue
+		}
+		if !cfg.PreviewMode {
+			queue.Remove(entry.Path)
+			if err := queue.Save(); err != nil {
+				return err
+			}
+		}
+	}
+	return queue.Save()
+}
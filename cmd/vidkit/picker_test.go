@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+	"github.com/tekenstam/vidkit/internal/pkg/review"
+)
+
+// script makes the prompts read their answers from input.
+func script(t *testing.T, input string) {
+	original := stdin
+	stdin = bufio.NewReader(strings.NewReader(input))
+	t.Cleanup(func() { stdin = original })
+}
+
+// tempReviewQueue moves the review queue to a temporary directory and
+// returns its path.
+func tempReviewQueue(t *testing.T) string {
+	original := config.ConfigFilePath
+	dir := t.TempDir()
+	config.ConfigFilePath = func() string { return filepath.Join(dir, "config.json") }
+	t.Cleanup(func() { config.ConfigFilePath = original })
+	return config.ReviewQueuePath()
+}
+
+func TestChoose(t *testing.T) {
+	episode := lookupResult{
+		match: "The Office (2005)", provider: "tvmaze", id: "526", shared: "show",
+		providers: []config.ProviderType{config.ProviderTVMaze, config.ProviderTMDb},
+	}
+	movie := lookupResult{
+		match: "Solaris (2002)", provider: "tmdb", id: "2103",
+		providers: []config.ProviderType{config.ProviderTMDb},
+	}
+	failed := lookupResult{providers: []config.ProviderType{config.ProviderTVMaze}}
+	candidates := []metadata.Candidate{
+		{Provider: "tvmaze", ID: "526", Title: "The Office", Year: 2005},
+		{Provider: "tvmaze", ID: "530", Title: "The Office", Year: 2001},
+	}
+
+	tests := []struct {
+		name       string
+		result     lookupResult
+		candidates []metadata.Candidate
+		input      string
+		want       decision
+		wantPick   pick
+	}{
+		{name: "Enter renames", result: episode, candidates: candidates, input: "\n", want: decideRename},
+		{name: "Yes renames", result: movie, input: "Y\n", want: decideRename},
+		{
+			name: "Rename for the rest of the show", result: episode, candidates: candidates, input: "a\n",
+			want: decideRenameAll, wantPick: pick{Provider: "tvmaze", ProviderID: "526"},
+		},
+		{name: "Rest of the show is not offered for movies", result: movie, input: "a\ns\n", want: decideSkip},
+		{
+			name: "Pick a result", result: episode, candidates: candidates, input: "2\n",
+			want: decideLookup, wantPick: pick{Provider: "tvmaze", ProviderID: "530"},
+		},
+		{name: "Result out of range", result: episode, candidates: candidates, input: "3\nn\n", want: decideSkip},
+		{
+			name: "Search with year", result: episode, candidates: candidates, input: "e\nThe Office (2001)\n",
+			want: decideLookup, wantPick: pick{Title: "The Office", Year: 2001},
+		},
+		{
+			name: "Empty search asks again", result: failed, input: "e\n\ne\nKommissar Rex\n",
+			want: decideLookup, wantPick: pick{Title: "Kommissar Rex"},
+		},
+		{
+			name: "Invalid ID asks again", result: episode, candidates: candidates, input: "i\nomdb:tt0386676\ni\ntvmaze:530\n",
+			want: decideLookup, wantPick: pick{Provider: "tvmaze", ProviderID: "530"},
+		},
+		{name: "Nothing to rename after a failed lookup", result: failed, input: "\na\ns\n", want: decideSkip},
+		{name: "End of input skips", result: episode, candidates: candidates, input: "x\n", want: decideSkip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script(t, tt.input)
+			got, gotPick := choose(tt.result, tt.candidates)
+			if got != tt.want || gotPick != tt.wantPick {
+				t.Errorf("choose() = %v, %+v, want %v, %+v", got, gotPick, tt.want, tt.wantPick)
+			}
+		})
+	}
+}
+
+func TestDecide(t *testing.T) {
+	listed := func() ([]metadata.Candidate, error) {
+		return []metadata.Candidate{{Provider: "tmdb", ID: "593", Title: "Solaris", Year: 1972}}, nil
+	}
+	failing := func() ([]metadata.Candidate, error) { return nil, errors.New("daily limit reached") }
+
+	tests := []struct {
+		name      string
+		result    lookupResult
+		batch     bool
+		input     string
+		want      decision
+		wantPick  pick
+		wantQueue string // Match of the queued file; "-" when nothing is queued
+	}{
+		{
+			name:   "Batch renames good matches",
+			result: lookupResult{path: "/movies/Solaris.2002.mkv", match: "Solaris (2002)", confidence: 1},
+			batch:  true, want: decideRename, wantQueue: "-",
+		},
+		{
+			name:   "Batch queues weak matches",
+			result: lookupResult{path: "/movies/Solaris.mkv", match: "Solaris (1972)", confidence: 0.5},
+			batch:  true, want: decideSkip, wantQueue: "Solaris (1972)",
+		},
+		{
+			name:   "Batch queues failed lookups",
+			result: lookupResult{path: "/movies/Solaris.mkv"},
+			batch:  true, want: decideSkip, wantQueue: "",
+		},
+		{
+			name:   "Interactive runs ask",
+			result: lookupResult{path: "/movies/Solaris.mkv", match: "Solaris (2002)", confidence: 0.5, candidates: listed},
+			input:  "1\n", want: decideLookup, wantPick: pick{Provider: "tmdb", ProviderID: "593"}, wantQueue: "-",
+		},
+		{
+			name:   "Interactive runs ask without results",
+			result: lookupResult{path: "/movies/Solaris.mkv", candidates: failing},
+			input:  "s\n", want: decideSkip, wantQueue: "-",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queuePath := tempReviewQueue(t)
+			script(t, tt.input)
+			got, gotPick := decide(tt.result, &config.Config{BatchMode: tt.batch})
+			if got != tt.want || gotPick != tt.wantPick {
+				t.Errorf("decide() = %v, %+v, want %v, %+v", got, gotPick, tt.want, tt.wantPick)
+			}
+
+			queue, err := review.Load(queuePath)
+			if err != nil {
+				t.Fatalf("review.Load() error = %v", err)
+			}
+			switch {
+			case tt.wantQueue == "-" && len(queue.Entries) > 0:
+				t.Errorf("queued %+v, want nothing queued", queue.Entries)
+			case tt.wantQueue != "-" && (len(queue.Entries) != 1 || queue.Entries[0].Path != tt.result.path || queue.Entries[0].Match != tt.wantQueue):
+				t.Errorf("queued %+v, want %s with match %q", queue.Entries, tt.result.path, tt.wantQueue)
+			}
+		})
+	}
+}
+
+func TestParseID(t *testing.T) {
+	tvmazeAndTMDb := []config.ProviderType{config.ProviderTVMaze, config.ProviderTMDb}
+
+	tests := []struct {
+		text      string
+		providers []config.ProviderType
+		want      pick
+		wantErr   bool
+	}{
+		{text: "tt0386676", providers: tvmazeAndTMDb, want: pick{IMDbID: "tt0386676"}},
+		{text: "tt0386676", providers: []config.ProviderType{config.ProviderTVDb}, wantErr: true},
+		{text: " tvmaze:526 ", providers: tvmazeAndTMDb, want: pick{Provider: "tvmaze", ProviderID: "526"}},
+		{text: "TMDb:2316", providers: tvmazeAndTMDb, want: pick{Provider: "tmdb", ProviderID: "2316"}},
+		{text: "526", providers: tvmazeAndTMDb, want: pick{Provider: "tvmaze", ProviderID: "526"}},
+		{text: "omdb:tt0386676", providers: []config.ProviderType{config.ProviderOMDb}, want: pick{Provider: "omdb", ProviderID: "tt0386676"}},
+		{text: "omdb:526", providers: []config.ProviderType{config.ProviderOMDb}, wantErr: true},
+		{text: "tvdb:abc", providers: []config.ProviderType{config.ProviderTVDb}, wantErr: true},
+		{text: "tvdb:526", providers: tvmazeAndTMDb, wantErr: true},
+		{text: "tvmaze:", providers: tvmazeAndTMDb, wantErr: true},
+		{text: "", providers: tvmazeAndTMDb, wantErr: true},
+		{text: "526", providers: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseID(tt.text, tt.providers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseID() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
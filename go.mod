@@ -2,7 +2,10 @@ module github.com/tekenstam/vidkit
 
 go 1.21
 
-require github.com/cyruzin/golang-tmdb v1.6.8
+require (
+	github.com/cyruzin/golang-tmdb v1.6.8
+	golang.org/x/text v0.14.0
+)
 
 require (
 	github.com/json-iterator/go v1.1.12 // indirect
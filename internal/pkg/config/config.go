@@ -22,8 +22,8 @@ type ProviderType string
 
 const (
 	// Movie provider types
-	ProviderTMDb ProviderType = "tmdb" // The Movie Database (primary movie provider)
-	ProviderOMDb ProviderType = "omdb" // Open Movie Database (alternative movie provider)
+	ProviderTMDb ProviderType = "tmdb" // The Movie Database (primary movie provider, also serves TV shows)
+	ProviderOMDb ProviderType = "omdb" // Open Movie Database (alternative movie provider, also serves TV shows)
 
 	// TV show provider types
 	ProviderTVMaze ProviderType = "tvmaze" // TVMaze (primary TV show provider)
@@ -40,30 +40,74 @@ type Config struct {
 	TVDbAPIKey string `json:"tvdb_api_key"` // API key for The TV Database
 
 	// Operational modes
-	BatchMode      bool     `json:"batch_mode"` // Run without interactive prompts
-	Recursive      bool     `json:"recursive"` // Process directories recursively
-	PreviewMode    bool     `json:"preview_mode"`
-	OnlyVideo      bool     `json:"only_video"`
+	BatchMode   bool `json:"batch_mode"` // Run without interactive prompts
+	Recursive   bool `json:"recursive"`  // Process directories recursively
+	PreviewMode bool `json:"preview_mode"`
+	OnlyVideo   bool `json:"only_video"`
 
 	// File handling preferences
-	Lowercase  bool   `json:"lowercase"`  // Convert filenames to lowercase
-	SceneStyle bool   `json:"scene_style"` // Use dots instead of spaces in filenames
-	Separator  string `json:"separator"` // Character to use as separator in filenames
+	Lowercase      bool     `json:"lowercase"`   // Convert filenames to lowercase
+	SceneStyle     bool     `json:"scene_style"` // Use dots instead of spaces in filenames
+	Separator      string   `json:"separator"`   // Character to use as separator in filenames
 	FileExtensions []string `json:"file_extensions"`
 	Language       string   `json:"language"` // Preferred language for metadata (ISO 639-1 code)
 	NoOverwrite    bool     `json:"no_overwrite"`
 	NoMetadata     bool     `json:"no_metadata"`
 
+	// Target filesystem naming rules
+	TargetFilesystem     string `json:"target_filesystem"`     // Filesystem profile for generated names (posix, windows, smb, exfat)
+	UnicodeNormalization string `json:"unicode_normalization"` // Override the profile's Unicode normalization (nfc, nfd)
+
+	// How the titles of multi-episode files are combined (join, first, collapse)
+	EpisodeTitleStyle string `json:"episode_title_style"`
+
+	// Leading articles ignored by {title_sort} and {first_letter}, keyed by language.
+	// Languages listed here replace the built-in article list.
+	Articles map[string][]string `json:"articles,omitempty"`
+
+	// User-defined filename patterns, tried in order before the built-in ones
+	ParseRules []ParseRule `json:"parse_rules,omitempty"`
+
+	// How tags embedded in MP4 and MKV files identify videos (auto, prefer, ignore)
+	EmbeddedTags string `json:"embedded_tags"`
+
+	// Skip files already named, and organized when enabled, by the templates
+	SkipConforming bool `json:"skip_conforming"`
+
+	// Files to skip: glob or regular expression ("re:" prefix) patterns matched
+	// against file and directory names, and thresholds that filter out samples
+	IgnorePatterns []string `json:"ignore_patterns"`
+	MinFileSizeMB  int      `json:"min_file_size_mb,omitempty"` // Skip files smaller than this many megabytes
+	MinDuration    int      `json:"min_duration,omitempty"`     // Skip videos shorter than this many seconds
+
 	// Provider preferences
 	MovieProvider ProviderType `json:"movie_provider"` // Preferred movie metadata provider
 	TVProvider    ProviderType `json:"tv_provider"`    // Preferred TV show metadata provider
 
+	// Providers asked in order until one finds a good match; replace the
+	// preferred provider when set
+	MovieProviders []ProviderType `json:"movie_providers,omitempty"`
+	TVProviders    []ProviderType `json:"tv_providers,omitempty"`
+
+	// Naming preset (plex, jellyfin, emby, kodi, scene); replaces the templates,
+	// separator and casing settings below
+	Preset string `json:"preset,omitempty"`
+
 	// Filename and directory templates
-	MovieFilenameTemplate string `json:"movie_filename_template"` // Template for movie filename 
-	TVFilenameTemplate    string `json:"tv_filename_template"`    // Template for TV show filename
+	MovieFilenameTemplate  string `json:"movie_filename_template"`  // Template for movie filename
+	TVFilenameTemplate     string `json:"tv_filename_template"`     // Template for TV show filename
 	MovieDirectoryTemplate string `json:"movie_directory_template"` // Template for movie directory organization
 	TVDirectoryTemplate    string `json:"tv_directory_template"`    // Template for TV show directory organization
-	OrganizeFiles  bool   `json:"organize_files"` // Whether to move files to organized directories
+	OrganizeFiles          bool   `json:"organize_files"`           // Whether to move files to organized directories
+}
+
+// ParseRule is a user-defined filename pattern for naming schemes that the
+// built-in patterns do not recognize, such as DVR recordings.
+type ParseRule struct {
+	Name    string `json:"name"`            // Name shown by "vidkit parse"
+	Pattern string `json:"pattern"`         // Regular expression with named groups (title, year, season, episode, episode_title, air_date, edition)
+	Scope   string `json:"scope,omitempty"` // Limit the rule to "tv" or "movie" files
+	Path    string `json:"path,omitempty"`  // Glob the file path must match (e.g., "DVR/*.ts")
 }
 
 // ConfigFilePath returns the path to the config file
@@ -72,32 +116,33 @@ var ConfigFilePath = func() string {
 	return filepath.Join(homeDir, ".config", "vidkit", "config.json")
 }
 
+// ReviewQueuePath returns the path to the queue of files that batch runs left
+// for review, next to the config file
+func ReviewQueuePath() string {
+	return filepath.Join(filepath.Dir(ConfigFilePath()), "review.json")
+}
+
+// loadedDefaults returns the settings used for keys a config file leaves out.
+// Existing config files keep working as they always did, so these differ from
+// DefaultConfig, which is written to new config files: all video containers
+// are processed, existing files are not overwritten, non-video files are not
+// skipped, and the templates are the plain ones.
+func loadedDefaults() *Config {
+	cfg := DefaultConfig()
+	cfg.FileExtensions = []string{".mp4", ".mkv", ".avi", ".mov", ".wmv", ".m4v", ".mpg", ".mpeg", ".webm", ".flv", ".ts", ".m2ts", ".mts", ".mxf"}
+	cfg.NoOverwrite = true
+	cfg.OnlyVideo = false
+	cfg.MovieFilenameTemplate = "{title} ({year})"
+	cfg.TVFilenameTemplate = "{title} {episode_range} {episode_title}"
+	cfg.MovieDirectoryTemplate = "Movies/{title} ({year})"
+	cfg.TVDirectoryTemplate = "TV/{title}/Season {season:02d}"
+	return cfg
+}
+
 // LoadConfig loads configuration from file
 func LoadConfig() (*Config, error) {
-	// Create default config
-	cfg := &Config{
-		TMDbAPIKey:     "",
-		OMDbAPIKey:     "",
-		TVDbAPIKey:     "",
-		BatchMode:      false,
-		Recursive:      false,
-		Lowercase:      false,
-		SceneStyle:     false,
-		Separator:      " ",
-		FileExtensions: []string{".mp4", ".mkv", ".avi", ".mov", ".wmv", ".m4v", ".mpg", ".mpeg", ".webm", ".flv", ".ts", ".m2ts", ".mts", ".mxf"},
-		Language:       "en",
-		NoOverwrite:    true,
-		NoMetadata:     false,
-		MovieProvider:  ProviderTMDb,
-		TVProvider:     ProviderTVMaze,
-		PreviewMode:    false,
-		OnlyVideo:      false,
-		MovieFilenameTemplate: "{title} ({year})",
-		TVFilenameTemplate:    "{title} S{season:02d}E{episode:02d} {episode_title}",
-		MovieDirectoryTemplate: "Movies/{title} ({year})",
-		TVDirectoryTemplate:    "TV/{title}/Season {season:02d}",
-		OrganizeFiles:  false,
-	}
+	// Settings missing from the file keep their default value
+	cfg := loadedDefaults()
 
 	// Check if config file exists
 	configPath := ConfigFilePath()
@@ -127,6 +172,19 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// A preset replaces the defaults of the templates and naming rules;
+	// settings of the file still win over it
+	if cfg.Preset != "" {
+		preset := loadedDefaults()
+		if err := preset.ApplyPreset(cfg.Preset); err != nil {
+			return nil, err
+		}
+		cfg = preset
+		if err := json.Unmarshal(configData, cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -139,20 +197,22 @@ func ValidateConfig(cfg *Config) error {
 
 	// Apply default TV format if needed
 	if cfg.TVFilenameTemplate == "" {
-		cfg.TVFilenameTemplate = "{title} - S{season:02d}E{episode:02d} - {episode_title}"
+		cfg.TVFilenameTemplate = "{title} - {episode_range} - {episode_title}"
 	}
 
 	// Check if metadata is enabled but no API key is provided
 	if !cfg.NoMetadata {
 		// Check based on selected providers
-		if cfg.MovieProvider == ProviderTMDb && cfg.TMDbAPIKey == "" {
-			return errors.New("TMDb API key is required for metadata lookup (set tmdb_api_key in config.json)")
-		}
-		if cfg.MovieProvider == ProviderOMDb && cfg.OMDbAPIKey == "" {
-			return errors.New("OMDb API key is required for metadata lookup (set omdb_api_key in config.json)")
-		}
-		if cfg.TVProvider == ProviderTVDb && cfg.TVDbAPIKey == "" {
-			return errors.New("TVDb API key is required for metadata lookup (set tvdb_api_key in config.json)")
+		for _, provider := range append(cfg.MovieProviderChain(), cfg.TVProviderChain()...) {
+			if provider == ProviderTMDb && cfg.TMDbAPIKey == "" {
+				return errors.New("TMDb API key is required for metadata lookup (set tmdb_api_key in config.json)")
+			}
+			if provider == ProviderOMDb && cfg.OMDbAPIKey == "" {
+				return errors.New("OMDb API key is required for metadata lookup (set omdb_api_key in config.json)")
+			}
+			if provider == ProviderTVDb && cfg.TVDbAPIKey == "" {
+				return errors.New("TVDb API key is required for metadata lookup (set tvdb_api_key in config.json)")
+			}
 		}
 	}
 
@@ -164,6 +224,24 @@ func ValidateConfig(cfg *Config) error {
 	return nil
 }
 
+// MovieProviderChain returns the movie providers to ask, in order: the
+// configured list, or the preferred provider alone.
+func (c *Config) MovieProviderChain() []ProviderType {
+	if len(c.MovieProviders) > 0 {
+		return c.MovieProviders
+	}
+	return []ProviderType{c.MovieProvider}
+}
+
+// TVProviderChain returns the TV show providers to ask, in order: the
+// configured list, or the preferred provider alone.
+func (c *Config) TVProviderChain() []ProviderType {
+	if len(c.TVProviders) > 0 {
+		return c.TVProviders
+	}
+	return []ProviderType{c.TVProvider}
+}
+
 // SaveConfig saves the configuration to the default location
 func SaveConfig(config *Config) error {
 	configPath := ConfigFilePath()
@@ -212,26 +290,41 @@ func DefaultConfig() *Config {
 		SceneStyle: false,
 		Separator:  " ",
 		Language:   "en",
-		
+
 		NoOverwrite: false,
 		NoMetadata:  false,
 		PreviewMode: false,
 		OnlyVideo:   true,
 
+		// Default target filesystem for generated names
+		TargetFilesystem: "posix",
+
+		// Combine "Finale (1)" and "Finale (2)" into "Finale", join other titles
+		EpisodeTitleStyle: "collapse",
+
+		// Weigh embedded tags against the filename
+		EmbeddedTags: "auto",
+
+		// Leave files named by the current templates alone
+		SkipConforming: true,
+
 		// Default providers
 		MovieProvider: ProviderTMDb,
 		TVProvider:    ProviderTVMaze,
 
 		// Default filename templates
 		MovieFilenameTemplate: "{title} ({year}) [{resolution} {codec}]",
-		TVFilenameTemplate:    "{title} - S{season:02d}E{episode:02d} - {episode_title}",
-		
+		TVFilenameTemplate:    "{title} - {episode_range} - {episode_title}",
+
 		// Default directory templates
 		MovieDirectoryTemplate: "{genre}/{title} ({year})",
 		TVDirectoryTemplate:    "{genre}/{title}/Season {season}",
-		OrganizeFiles: false,
+		OrganizeFiles:          false,
 
 		// Default file extensions to process
 		FileExtensions: []string{".mp4", ".mkv", ".avi", ".mov", ".m4v"},
+
+		// Skip the samples that come with downloads
+		IgnorePatterns: []string{"sample", "*.sample", "*-sample"},
 	}
 }
@@ -23,6 +23,10 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Default separator = %v, want space", cfg.Separator)
 	}
 
+	if cfg.TargetFilesystem != "posix" {
+		t.Errorf("Default target filesystem = %v, want posix", cfg.TargetFilesystem)
+	}
+
 	// Check default providers
 	if cfg.MovieProvider != ProviderTMDb {
 		t.Errorf("Default movie provider = %v, want %v", cfg.MovieProvider, ProviderTMDb)
@@ -50,13 +54,13 @@ func TestValidateConfig(t *testing.T) {
 		{
 			name: "Valid config",
 			config: &Config{
-				Separator:            " ",
-				Language:             "en",
+				Separator:             " ",
+				Language:              "en",
 				MovieFilenameTemplate: "{title} ({year})",
 				TVFilenameTemplate:    "{title} S{season:02d}E{episode:02d}",
-				MovieProvider:        ProviderTMDb,
-				TVProvider:           ProviderTVMaze,
-				TMDbAPIKey:           "test_key",
+				MovieProvider:         ProviderTMDb,
+				TVProvider:            ProviderTVMaze,
+				TMDbAPIKey:            "test_key",
 			},
 			wantError: false,
 		},
@@ -70,8 +74,8 @@ func TestValidateConfig(t *testing.T) {
 			config: &Config{
 				MovieFilenameTemplate: "{title} ({year})",
 				TVFilenameTemplate:    "{title} S{season:02d}E{episode:02d}",
-				MovieProvider:        ProviderTMDb,
-				NoMetadata:           true, // No metadata lookup, so no API key needed
+				MovieProvider:         ProviderTMDb,
+				NoMetadata:            true, // No metadata lookup, so no API key needed
 			},
 			wantError: false,
 		},
@@ -80,8 +84,8 @@ func TestValidateConfig(t *testing.T) {
 			config: &Config{
 				MovieFilenameTemplate: "{title} ({year})",
 				TVFilenameTemplate:    "{title} S{season:02d}E{episode:02d}",
-				MovieProvider:        ProviderTMDb,
-				NoMetadata:           false,
+				MovieProvider:         ProviderTMDb,
+				NoMetadata:            false,
 			},
 			wantError: true,
 		},
@@ -90,11 +94,42 @@ func TestValidateConfig(t *testing.T) {
 			config: &Config{
 				MovieFilenameTemplate: "{title} ({year})",
 				TVFilenameTemplate:    "{title} S{season:02d}E{episode:02d}",
-				MovieProvider:        ProviderOMDb,
-				NoMetadata:           false,
+				MovieProvider:         ProviderOMDb,
+				NoMetadata:            false,
+			},
+			wantError: true,
+		},
+		{
+			name: "TMDb TV provider without API key",
+			config: &Config{
+				MovieFilenameTemplate: "{title} ({year})",
+				TVFilenameTemplate:    "{title} S{season:02d}E{episode:02d}",
+				MovieProvider:         ProviderOMDb,
+				OMDbAPIKey:            "test_key",
+				TVProvider:            ProviderTMDb,
+				NoMetadata:            false,
+			},
+			wantError: true,
+		},
+		{
+			name: "Provider chain without API key",
+			config: &Config{
+				MovieProvider:  ProviderTMDb,
+				MovieProviders: []ProviderType{ProviderTMDb, ProviderOMDb},
+				TMDbAPIKey:     "test_key",
 			},
 			wantError: true,
 		},
+		{
+			name: "Provider chain replaces the preferred provider",
+			config: &Config{
+				MovieProvider:  ProviderTMDb,
+				MovieProviders: []ProviderType{ProviderOMDb},
+				OMDbAPIKey:     "test_key",
+				TVProviders:    []ProviderType{ProviderTVMaze, ProviderOMDb},
+			},
+			wantError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -184,6 +219,48 @@ func TestLoadAndSaveConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFileExtensions(t *testing.T) {
+	originalPath := ConfigFilePath
+	defer SetConfigPath(originalPath)
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	SetConfigPath(func() string {
+		return configPath
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "Missing setting keeps every container",
+			content: `{"tmdb_api_key": "test_key"}`,
+			want:    loadedDefaults().FileExtensions,
+		},
+		{
+			name:    "Configured list replaces the defaults",
+			content: `{"file_extensions": [".mkv", ".ts"]}`,
+			want:    []string{".mkv", ".ts"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configPath, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(cfg.FileExtensions, tt.want) {
+				t.Errorf("Loaded file extensions = %v, want %v", cfg.FileExtensions, tt.want)
+			}
+		})
+	}
+}
+
 func TestConfigPermissions(t *testing.T) {
 	// Skip this test on platforms where file permissions behave differently
 	if os.Getenv("SKIP_PERMISSION_TEST") != "" {
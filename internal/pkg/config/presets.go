@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Preset bundles the naming settings that follow the conventions of a media
+// server or naming scheme. Applying a preset replaces all four templates as
+// well as the separator and casing settings.
+type Preset struct {
+	Description            string // Short description shown in help output
+	MovieFilenameTemplate  string
+	TVFilenameTemplate     string
+	MovieDirectoryTemplate string
+	TVDirectoryTemplate    string
+	Separator              string
+	SceneStyle             bool
+	Lowercase              bool
+}
+
+// Presets lists the built-in naming presets by name.
+//
+// The templates follow the naming guides of each server: Plex marks editions
+// with "{edition-...}", Jellyfin and Emby append them as " - Edition", and all
+// servers recognize "S01E01-E02" ranges for multi-episode files and
+// " - part1" for movies split across files.
+var Presets = map[string]Preset{
+	"plex": {
+		Description:            "Plex Media Server",
+		MovieFilenameTemplate:  "{title} ({year})< {{edition-{edition}}}>< - {part}>",
+		TVFilenameTemplate:     "{title}< ({year})> - {episode_range}< - {episode_title}>",
+		MovieDirectoryTemplate: "Movies/{title} ({year})< {{edition-{edition}}}>",
+		TVDirectoryTemplate:    "TV Shows/{title}< ({year})>/Season {season:02d}",
+		Separator:              " ",
+	},
+	"jellyfin": {
+		Description:            "Jellyfin",
+		MovieFilenameTemplate:  "{title} ({year})< - {edition}>< - {part}>",
+		TVFilenameTemplate:     "{title} {episode_range}< - {episode_title}>",
+		MovieDirectoryTemplate: "Movies/{title} ({year})",
+		TVDirectoryTemplate:    "Shows/{title}< ({year})>/Season {season:02d}",
+		Separator:              " ",
+	},
+	"emby": {
+		Description:            "Emby",
+		MovieFilenameTemplate:  "{title} ({year})< - {edition}>< - {part}>",
+		TVFilenameTemplate:     "{title} - {episode_range}< - {episode_title}>",
+		MovieDirectoryTemplate: "Movies/{title} ({year})",
+		TVDirectoryTemplate:    "TV Shows/{title}< ({year})>/Season {season}",
+		Separator:              " ",
+	},
+	"kodi": {
+		Description:            "Kodi",
+		MovieFilenameTemplate:  "{title} ({year})< - {part}>",
+		TVFilenameTemplate:     "{title} {episode_range}< - {episode_title}>",
+		MovieDirectoryTemplate: "Movies/{title} ({year})",
+		TVDirectoryTemplate:    "TV Shows/{title}< ({year})>/Season {season:02d}",
+		Separator:              " ",
+	},
+	"scene": {
+		Description:            "Scene release names separated by dots",
+		MovieFilenameTemplate:  "{title} {year}< {edition}>< {part}>< {resolution}>< {video_codec}>",
+		TVFilenameTemplate:     "{title} {episode_range}< {episode_title}>< {resolution}>< {video_codec}>",
+		MovieDirectoryTemplate: "{title} {year}< {edition}>",
+		TVDirectoryTemplate:    "{title}/Season {season:02d}",
+		Separator:              ".",
+		SceneStyle:             true,
+	},
+}
+
+// PresetNames returns the sorted names of all built-in presets.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyPreset replaces the templates, separator and casing settings of the
+// configuration with those of the named preset and records it in Preset.
+// Settings applied afterwards, such as command-line flags, still override
+// individual pieces.
+func (c *Config) ApplyPreset(name string) error {
+	preset, ok := Presets[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	c.Preset = strings.ToLower(name)
+	c.MovieFilenameTemplate = preset.MovieFilenameTemplate
+	c.TVFilenameTemplate = preset.TVFilenameTemplate
+	c.MovieDirectoryTemplate = preset.MovieDirectoryTemplate
+	c.TVDirectoryTemplate = preset.TVDirectoryTemplate
+	c.Separator = preset.Separator
+	c.SceneStyle = preset.SceneStyle
+	c.Lowercase = preset.Lowercase
+	return nil
+}
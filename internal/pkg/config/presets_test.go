@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyPreset(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Lowercase = true
+	if err := cfg.ApplyPreset("Plex"); err != nil {
+		t.Fatalf("ApplyPreset() error = %v", err)
+	}
+
+	plex := Presets["plex"]
+	if cfg.Preset != "plex" {
+		t.Errorf("Preset = %q, want plex", cfg.Preset)
+	}
+	if cfg.MovieFilenameTemplate != plex.MovieFilenameTemplate || cfg.TVFilenameTemplate != plex.TVFilenameTemplate {
+		t.Errorf("Filename templates = %q, %q, want plex templates", cfg.MovieFilenameTemplate, cfg.TVFilenameTemplate)
+	}
+	if cfg.MovieDirectoryTemplate != plex.MovieDirectoryTemplate || cfg.TVDirectoryTemplate != plex.TVDirectoryTemplate {
+		t.Errorf("Directory templates = %q, %q, want plex templates", cfg.MovieDirectoryTemplate, cfg.TVDirectoryTemplate)
+	}
+	if cfg.Lowercase || cfg.SceneStyle || cfg.Separator != " " {
+		t.Errorf("Naming rules = lowercase %v, scene %v, separator %q, want plex rules", cfg.Lowercase, cfg.SceneStyle, cfg.Separator)
+	}
+
+	if err := cfg.ApplyPreset("scene"); err != nil {
+		t.Fatalf("ApplyPreset(scene) error = %v", err)
+	}
+	if !cfg.SceneStyle || cfg.Separator != "." {
+		t.Errorf("Scene preset = scene %v, separator %q, want scene style with dots", cfg.SceneStyle, cfg.Separator)
+	}
+
+	if err := cfg.ApplyPreset("infuse"); err == nil {
+		t.Errorf("ApplyPreset(infuse) error = nil, want error")
+	}
+}
+
+func TestLoadConfigPreset(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalPath := ConfigFilePath
+	defer SetConfigPath(originalPath)
+
+	configPath := filepath.Join(tmpDir, "config.json")
+	SetConfigPath(func() string {
+		return configPath
+	})
+
+	// The preset replaces the default templates, while those of the file win
+	data := `{"preset": "jellyfin", "movie_filename_template": "{title}", "language": "de"}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.MovieFilenameTemplate != "{title}" {
+		t.Errorf("Movie template = %q, want the template of the file", cfg.MovieFilenameTemplate)
+	}
+	if cfg.TVFilenameTemplate != Presets["jellyfin"].TVFilenameTemplate {
+		t.Errorf("TV template = %q, want jellyfin template", cfg.TVFilenameTemplate)
+	}
+	if cfg.Language != "de" {
+		t.Errorf("Language = %q, want de", cfg.Language)
+	}
+	if cfg.TargetFilesystem != DefaultConfig().TargetFilesystem {
+		t.Errorf("Target filesystem = %q, want default", cfg.TargetFilesystem)
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{"preset": "unknown"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(); err == nil {
+		t.Errorf("LoadConfig() with unknown preset error = nil, want error")
+	}
+}
@@ -0,0 +1,148 @@
+// Package ignore decides which files and directories VidKit skips.
+//
+// Patterns are matched against single path components, the name of a file or
+// directory, so ignoring a directory ignores everything below it. A pattern is
+// either a glob or, with the "re:" prefix, a regular expression:
+//
+//	sample                the file "sample.mkv" or a directory named "Sample"
+//	*.sample.*            files such as "Movie.2012.sample.mkv"
+//	Downloads/incomplete  a directory "incomplete" inside a directory "Downloads"
+//	re:(?i)\bextras?\b    any name containing the word "extra" or "extras"
+//
+// Globs are case-insensitive and also match a file name without its
+// extension. Globs containing "/" match as many trailing components of the
+// path. Regular expressions match anywhere in the name unless anchored.
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the file listing the patterns of a directory.
+const FileName = ".vidkitignore"
+
+// regexPrefix marks a pattern as a regular expression.
+const regexPrefix = "re:"
+
+// pattern is a compiled glob or regular expression.
+type pattern struct {
+	glob    string // Lowercase glob; empty for regular expressions
+	slashes int    // Number of "/" in the glob, which matches as many more trailing path components
+	re      *regexp.Regexp
+}
+
+// Matcher matches names against a set of patterns and those of its parent.
+type Matcher struct {
+	parent   *Matcher
+	patterns []pattern
+}
+
+// New compiles patterns into a matcher. It fails for invalid globs and
+// regular expressions.
+func New(patterns []string) (*Matcher, error) {
+	return (*Matcher)(nil).Child(patterns)
+}
+
+// Child returns a matcher that matches the patterns of m as well as the
+// given ones, as used for the patterns of a subdirectory. A nil matcher has
+// no patterns.
+func (m *Matcher) Child(patterns []string) (*Matcher, error) {
+	child := &Matcher{parent: m}
+	for _, p := range patterns {
+		if expr, ok := strings.CutPrefix(p, regexPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ignore pattern %q: %v", p, err)
+			}
+			child.patterns = append(child.patterns, pattern{re: re})
+			continue
+		}
+		glob := strings.ToLower(strings.Trim(p, "/"))
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %v", p, err)
+		}
+		child.patterns = append(child.patterns, pattern{glob: glob, slashes: strings.Count(glob, "/")})
+	}
+	return child, nil
+}
+
+// Match reports whether a file or directory matches any pattern. Most
+// patterns only look at the name, the last component of the path; globs
+// containing "/" look at the trailing components of the path as well, so
+// pass absolute paths to give them the full context.
+func (m *Matcher) Match(file string) bool {
+	components := strings.Split(strings.ToLower(filepath.ToSlash(filepath.Clean(file))), "/")
+	name := filepath.Base(file)
+	ext := filepath.Ext(strings.ToLower(name))
+	for ; m != nil; m = m.parent {
+		for _, p := range m.patterns {
+			if p.re != nil {
+				if p.re.MatchString(name) {
+					return true
+				}
+				continue
+			}
+			if p.slashes >= len(components) {
+				continue
+			}
+			tail := strings.Join(components[len(components)-p.slashes-1:], "/")
+			if ok, _ := path.Match(p.glob, tail); ok {
+				return true
+			}
+			if ok, _ := path.Match(p.glob, strings.TrimSuffix(tail, ext)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadDir returns the patterns of the ignore file in dir, one per line.
+// Empty lines and lines starting with "#" are skipped. A directory without
+// an ignore file has no patterns.
+func ReadDir(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// ForDir returns the matcher for the entries of dir: m extended by the
+// patterns of the ignore file in dir, or m itself when there is none.
+func (m *Matcher) ForDir(dir string) (*Matcher, error) {
+	patterns, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return m, nil
+	}
+	child, err := m.Child(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Join(dir, FileName), err)
+	}
+	return child, nil
+}
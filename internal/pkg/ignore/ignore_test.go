@@ -0,0 +1,109 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	m, err := New([]string{"sample", "*.sample", "re:(?i)\\bextras?\\b"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "sample.mkv", want: true},
+		{name: "Sample", want: true},
+		{name: "SAMPLE.avi", want: true},
+		{name: "Movie.2012.1080p-GRP.sample.mkv", want: true},
+		{name: "Extras", want: true},
+		{name: "Movie Extra Footage.mkv", want: true},
+		{name: "Movie.2012.1080p-GRP.mkv", want: false},
+		{name: "Samples of Joy (2010).mkv", want: false},
+		{name: "Extraction (2020).mkv", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Match(tt.name); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	m, err := New([]string{"sample", "Downloads/incomplete", "tv/*/extras/"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/home/user/Downloads/incomplete", want: true},
+		{path: "Downloads/Incomplete", want: true},
+		{path: "Downloads/incomplete/", want: true},
+		{path: "/media/TV/Lost/Extras", want: true},
+		{path: "/media/TV/Lost/extras.mkv", want: true},
+		{path: "/home/user/Downloads/sample", want: true},
+		{path: "incomplete", want: false},
+		{path: "/home/user/Torrents/incomplete", want: false},
+		{path: "/home/user/Downloads/incomplete/movie.mkv", want: false},
+		{path: "/media/TV/Extras", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Match(filepath.FromSlash(tt.path)); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, pattern := range []string{"[", "re:("} {
+		if _, err := New([]string{pattern}); err == nil {
+			t.Errorf("New(%q) error = nil, want error", pattern)
+		}
+	}
+}
+
+func TestForDir(t *testing.T) {
+	dir := t.TempDir()
+	content := "# Local patterns\n\n*-proof\n"
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parent, err := New([]string{"sample"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	m, err := parent.ForDir(dir)
+	if err != nil {
+		t.Fatalf("ForDir() error = %v", err)
+	}
+	for name, want := range map[string]bool{"grp-proof.mkv": true, "sample.mkv": true, "movie.mkv": false} {
+		if got := m.Match(name); got != want {
+			t.Errorf("Match(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	// Directories without an ignore file keep the patterns of their parent
+	empty := t.TempDir()
+	if m, err := parent.ForDir(empty); err != nil || m != parent {
+		t.Errorf("ForDir() = %v, %v, want the parent matcher", m, err)
+	}
+
+	// Patterns only apply below the directory of the ignore file
+	if parent.Match("grp-proof.mkv") {
+		t.Errorf("parent Match(%q) = true, want false", "grp-proof.mkv")
+	}
+}
@@ -0,0 +1,161 @@
+package media
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VideoStream returns the first video stream of the file, or nil when the file
+// has no video stream.
+func (v *VideoInfo) VideoStream() *Stream {
+	return v.firstStream("video")
+}
+
+// AudioStream returns the first audio stream of the file, or nil when the file
+// has no audio stream.
+func (v *VideoInfo) AudioStream() *Stream {
+	return v.firstStream("audio")
+}
+
+func (v *VideoInfo) firstStream(codecType string) *Stream {
+	if v == nil {
+		return nil
+	}
+	for i := range v.Streams {
+		if v.Streams[i].CodecType == codecType {
+			return &v.Streams[i]
+		}
+	}
+	return nil
+}
+
+// pixFmtDepth extracts the bit depth from pixel formats such as "yuv420p10le" or "p010le".
+var pixFmtDepth = regexp.MustCompile(`p0?(10|12|16)(le|be)?$`)
+
+// BitDepth returns the bit depth of a video stream. It prefers the explicit
+// bits_per_raw_sample value and falls back to the pixel format. It returns 0
+// when the depth cannot be determined.
+//
+// Example:
+//
+//	stream := media.Stream{PixFmt: "yuv420p10le"}
+//	stream.BitDepth() // 10
+func (s *Stream) BitDepth() int {
+	if depth, err := strconv.Atoi(s.BitsPerRawSample); err == nil && depth > 0 {
+		return depth
+	}
+	if s.PixFmt == "" {
+		return 0
+	}
+	if m := pixFmtDepth.FindStringSubmatch(s.PixFmt); m != nil {
+		depth, _ := strconv.Atoi(m[1])
+		return depth
+	}
+	return 8
+}
+
+// HDRFormat returns the HDR format of a video stream ("DV", "HDR10+", "HDR10"
+// or "HLG"), or an empty string for SDR video.
+//
+// Dolby Vision is detected from its configuration record or codec tag, HDR10+
+// from its dynamic metadata in the side data of the stream or, as read by
+// GetVideoInfo, of its first frame, HDR10 from the PQ (smpte2084) transfer function
+// and HLG from the arib-std-b67 transfer function.
+func (s *Stream) HDRFormat() string {
+	for _, sideData := range s.SideDataList {
+		switch {
+		case strings.Contains(sideData.SideDataType, "DOVI"):
+			return "DV"
+		case strings.Contains(sideData.SideDataType, "HDR10+"):
+			return "HDR10+"
+		}
+	}
+	switch strings.ToLower(s.CodecTag) {
+	case "dvhe", "dvh1", "dvav", "dva1":
+		return "DV"
+	}
+	switch s.ColorTransfer {
+	case "smpte2084":
+		return "HDR10"
+	case "arib-std-b67":
+		return "HLG"
+	}
+	return ""
+}
+
+// FormatChannels returns the release-style channel notation ("2.0", "5.1",
+// "7.1") for an audio stream. The channel layout is used when it is known,
+// otherwise the channel count decides. It returns an empty string when
+// neither is available.
+//
+// Example:
+//
+//	media.FormatChannels(6, "5.1(side)") // "5.1"
+//	media.FormatChannels(2, "")          // "2.0"
+func FormatChannels(channels int, layout string) string {
+	layout = strings.ToLower(layout)
+	if i := strings.IndexByte(layout, '('); i >= 0 {
+		layout = layout[:i]
+	}
+	switch layout {
+	case "mono":
+		return "1.0"
+	case "stereo", "2.0":
+		return "2.0"
+	case "2.1", "3.0", "4.0", "4.1", "5.0", "5.1", "6.0", "6.1", "7.0", "7.1":
+		return layout
+	}
+
+	switch {
+	case channels <= 0:
+		return ""
+	case channels == 1:
+		return "1.0"
+	case channels == 2:
+		return "2.0"
+	case channels == 6:
+		return "5.1"
+	case channels == 8:
+		return "7.1"
+	default:
+		// Assume one LFE channel for other multi-channel layouts
+		return fmt.Sprintf("%d.1", channels-1)
+	}
+}
+
+// FrameRateValue converts an ffprobe frame rate fraction such as "24000/1001"
+// into frames per second rounded to three decimals (23.976). It returns 0 for
+// empty or invalid values.
+func FrameRateValue(frameRate string) float64 {
+	parts := strings.Split(frameRate, "/")
+	num, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0
+	}
+	if len(parts) == 2 {
+		den, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || den == 0 {
+			return 0
+		}
+		num /= den
+	}
+	return math.Round(num*1000) / 1000
+}
+
+// FormatDuration converts a duration in seconds (as reported by ffprobe) into
+// a compact human-readable form such as "2h16m" or "45m". It returns an empty
+// string for empty or invalid values.
+func FormatDuration(seconds string) string {
+	value, err := strconv.ParseFloat(seconds, 64)
+	if err != nil || value <= 0 {
+		return ""
+	}
+	minutes := int(math.Round(value / 60))
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,144 @@
+package media
+
+import (
+	"testing"
+)
+
+// TestFormatChannels verifies the release-style channel notation for layouts
+// reported by ffprobe and for bare channel counts.
+func TestFormatChannels(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels int
+		layout   string
+		want     string
+	}{
+		{name: "Stereo layout", channels: 2, layout: "stereo", want: "2.0"},
+		{name: "Mono layout", channels: 1, layout: "mono", want: "1.0"},
+		{name: "Side surround layout", channels: 6, layout: "5.1(side)", want: "5.1"},
+		{name: "Seven one layout", channels: 8, layout: "7.1", want: "7.1"},
+		{name: "Count only stereo", channels: 2, want: "2.0"},
+		{name: "Count only six", channels: 6, want: "5.1"},
+		{name: "Count only eight", channels: 8, want: "7.1"},
+		{name: "Unknown layout falls back to count", channels: 6, layout: "hexagonal", want: "5.1"},
+		{name: "Nothing known", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatChannels(tt.channels, tt.layout); got != tt.want {
+				t.Errorf("FormatChannels(%d, %q) = %q, want %q", tt.channels, tt.layout, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStreamBitDepth verifies bit depth detection from bits_per_raw_sample and pixel formats.
+func TestStreamBitDepth(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream Stream
+		want   int
+	}{
+		{name: "Raw sample value", stream: Stream{BitsPerRawSample: "10", PixFmt: "yuv420p"}, want: 10},
+		{name: "Ten bit pixel format", stream: Stream{PixFmt: "yuv420p10le"}, want: 10},
+		{name: "Twelve bit pixel format", stream: Stream{PixFmt: "yuv444p12be"}, want: 12},
+		{name: "P010 pixel format", stream: Stream{PixFmt: "p010le"}, want: 10},
+		{name: "Eight bit pixel format", stream: Stream{PixFmt: "yuv420p"}, want: 8},
+		{name: "Unknown", stream: Stream{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stream.BitDepth(); got != tt.want {
+				t.Errorf("BitDepth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStreamHDRFormat verifies HDR detection from side data, codec tags and transfer functions.
+func TestStreamHDRFormat(t *testing.T) {
+	dolbyVision := Stream{ColorTransfer: "smpte2084", SideDataList: []SideData{{SideDataType: "DOVI configuration record"}}}
+	hdr10Plus := Stream{ColorTransfer: "smpte2084", SideDataList: []SideData{{SideDataType: "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"}}}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		want   string
+	}{
+		{name: "Dolby Vision side data", stream: dolbyVision, want: "DV"},
+		{name: "Dolby Vision codec tag", stream: Stream{CodecTag: "dvhe"}, want: "DV"},
+		{name: "HDR10+", stream: hdr10Plus, want: "HDR10+"},
+		{name: "HDR10", stream: Stream{ColorTransfer: "smpte2084"}, want: "HDR10"},
+		{name: "HLG", stream: Stream{ColorTransfer: "arib-std-b67"}, want: "HLG"},
+		{name: "SDR", stream: Stream{ColorTransfer: "bt709"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stream.HDRFormat(); got != tt.want {
+				t.Errorf("HDRFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFrameRateValue verifies the conversion of ffprobe frame rate fractions.
+func TestFrameRateValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{input: "24000/1001", want: 23.976},
+		{input: "25/1", want: 25},
+		{input: "30", want: 30},
+		{input: "0/0", want: 0},
+		{input: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := FrameRateValue(tt.input); got != tt.want {
+			t.Errorf("FrameRateValue(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestFormatDuration verifies the compact duration notation.
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "8160.5", want: "2h16m"},
+		{input: "2700", want: "45m"},
+		{input: "3600", want: "1h00m"},
+		{input: "", want: ""},
+		{input: "N/A", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.input); got != tt.want {
+			t.Errorf("FormatDuration(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestSelectStreams verifies that the first stream of each type is returned.
+func TestSelectStreams(t *testing.T) {
+	info := &VideoInfo{Streams: []Stream{
+		{CodecType: "audio", CodecName: "eac3"},
+		{CodecType: "video", CodecName: "hevc"},
+		{CodecType: "audio", CodecName: "aac"},
+	}}
+
+	if got := info.VideoStream(); got == nil || got.CodecName != "hevc" {
+		t.Errorf("VideoStream() = %v, want hevc stream", got)
+	}
+	if got := info.AudioStream(); got == nil || got.CodecName != "eac3" {
+		t.Errorf("AudioStream() = %v, want eac3 stream", got)
+	}
+	if got := (&VideoInfo{}).AudioStream(); got != nil {
+		t.Errorf("AudioStream() = %v, want nil", got)
+	}
+}
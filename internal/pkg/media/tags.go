@@ -0,0 +1,33 @@
+package media
+
+import "strings"
+
+// Tags returns the tags embedded in the file, with lowercase names. Container
+// tags are combined with the tags of the first video stream, where Matroska
+// muxers sometimes store them; container tags win when both are set. The
+// title of the stream names the track ("Main Video"), not the video, so it is
+// left out.
+//
+// ffprobe reports MP4 tags in lowercase ("show", "season_number") and
+// Matroska tags in uppercase ("TITLE", "IMDB"), so names are normalized.
+func (v *VideoInfo) Tags() map[string]string {
+	tags := make(map[string]string)
+	if v == nil {
+		return tags
+	}
+	if stream := v.VideoStream(); stream != nil {
+		addTags(tags, stream.Tags)
+		delete(tags, "title")
+	}
+	addTags(tags, v.Format.Tags)
+	return tags
+}
+
+// addTags copies non-empty tags into dst under their lowercase names.
+func addTags(dst, src map[string]string) {
+	for name, value := range src {
+		if value = strings.TrimSpace(value); value != "" {
+			dst[strings.ToLower(name)] = value
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package media
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestTags verifies that container and stream tags reported by ffprobe are
+// combined under lowercase names.
+func TestTags(t *testing.T) {
+	output := `{
+		"format": {
+			"format_name": "matroska,webm",
+			"tags": {"TITLE": "Inception", "IMDB": "tt1375666", "ENCODER": " "}
+		},
+		"streams": [
+			{"codec_type": "video", "codec_name": "h264", "tags": {"title": "Main feature", "DATE_RELEASED": "2010"}},
+			{"codec_type": "audio", "codec_name": "aac", "tags": {"language": "eng"}}
+		]
+	}`
+	var info VideoInfo
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatalf("failed to parse ffprobe output: %v", err)
+	}
+
+	want := map[string]string{"title": "Inception", "imdb": "tt1375666", "date_released": "2010"}
+	if got := info.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags() = %v, want %v", got, want)
+	}
+
+	// The title of a stream names the track, not the video
+	info.Format.Tags = nil
+	if got := info.Tags(); got["title"] != "" {
+		t.Errorf("Tags() title = %q, want none from the video stream", got["title"])
+	}
+
+	var empty *VideoInfo
+	if got := empty.Tags(); len(got) != 0 {
+		t.Errorf("Tags() of nil info = %v, want no tags", got)
+	}
+}
@@ -3,7 +3,7 @@
 // detecting video codecs, resolutions, and other stream information.
 //
 // This package is central to VidKit's ability to analyze video files and is used
-// throughout the application to determine video quality, format information, and 
+// throughout the application to determine video quality, format information, and
 // technical properties needed for intelligent file organization.
 package media
 
@@ -18,30 +18,53 @@ import (
 
 // VideoInfo represents the structure of ffprobe JSON output with comprehensive
 // information about a video file's format and streams.
-// 
+//
 // The structure closely follows FFmpeg's ffprobe JSON output format, containing
 // both general format information (file size, duration, bit rate) and detailed
 // stream information (video resolution, codec, audio channels, etc.).
 type VideoInfo struct {
 	Format struct {
-		Filename   string `json:"filename"`   // Complete path to the video file
+		Filename   string `json:"filename"`    // Complete path to the video file
 		FormatName string `json:"format_name"` // Container format (e.g., "mp4", "mkv")
-		Duration   string `json:"duration"`   // Duration in seconds as a string
-		Size       string `json:"size"`       // File size in bytes as a string
-		BitRate    string `json:"bit_rate"`   // Total bit rate in bits/second
+		Duration   string `json:"duration"`    // Duration in seconds as a string
+		Size       string `json:"size"`        // File size in bytes as a string
+		BitRate    string `json:"bit_rate"`    // Total bit rate in bits/second
 		ProbeScore int    `json:"probe_score"` // Confidence score of format detection (higher is better)
+
+		Tags map[string]string `json:"tags,omitempty"` // Container tags (e.g., "title", "show", "IMDB")
 	} `json:"format"`
-	Streams []struct {
-		CodecType     string `json:"codec_type"`     // Type of stream ("video", "audio", "subtitle")
-		CodecName     string `json:"codec_name"`     // Codec name (e.g., "h264", "aac")
-		Width         int    `json:"width,omitempty"`  // Video width in pixels
-		Height        int    `json:"height,omitempty"` // Video height in pixels
-		BitRate       string `json:"bit_rate,omitempty"` // Stream bit rate in bits/second
-		FrameRate     string `json:"r_frame_rate,omitempty"` // Frame rate as a fraction (e.g., "24000/1001")
-		SampleRate    string `json:"sample_rate,omitempty"`  // Audio sample rate in Hz
-		Channels      int    `json:"channels,omitempty"`     // Number of audio channels
-		ChannelLayout string `json:"channel_layout,omitempty"` // Audio channel layout (e.g., "stereo")
-	} `json:"streams"`
+	Streams []Stream `json:"streams"`
+}
+
+// Stream describes a single video, audio or subtitle stream as reported by ffprobe.
+type Stream struct {
+	CodecType     string `json:"codec_type"`               // Type of stream ("video", "audio", "subtitle")
+	CodecName     string `json:"codec_name"`               // Codec name (e.g., "h264", "aac")
+	Width         int    `json:"width,omitempty"`          // Video width in pixels
+	Height        int    `json:"height,omitempty"`         // Video height in pixels
+	BitRate       string `json:"bit_rate,omitempty"`       // Stream bit rate in bits/second
+	FrameRate     string `json:"r_frame_rate,omitempty"`   // Frame rate as a fraction (e.g., "24000/1001")
+	SampleRate    string `json:"sample_rate,omitempty"`    // Audio sample rate in Hz
+	Channels      int    `json:"channels,omitempty"`       // Number of audio channels
+	ChannelLayout string `json:"channel_layout,omitempty"` // Audio channel layout (e.g., "stereo")
+
+	// Additional properties used for release-style naming
+	Profile          string `json:"profile,omitempty"`             // Codec profile (e.g., "Main 10", "DTS-HD MA")
+	CodecTag         string `json:"codec_tag_string,omitempty"`    // Codec tag (e.g., "hvc1", "dvhe")
+	PixFmt           string `json:"pix_fmt,omitempty"`             // Pixel format (e.g., "yuv420p10le")
+	BitsPerRawSample string `json:"bits_per_raw_sample,omitempty"` // Bit depth of the samples as a string
+	ColorTransfer    string `json:"color_transfer,omitempty"`      // Transfer characteristics (e.g., "smpte2084")
+	ColorPrimaries   string `json:"color_primaries,omitempty"`     // Color primaries (e.g., "bt2020")
+
+	// Side data of the stream, and of its first frame for HDR10 video
+	SideDataList []SideData `json:"side_data_list,omitempty"`
+
+	Tags map[string]string `json:"tags,omitempty"` // Stream tags (e.g., "language", "title")
+}
+
+// SideData describes a piece of side data of a stream or frame, such as HDR metadata.
+type SideData struct {
+	SideDataType string `json:"side_data_type"` // Side data type (e.g., "DOVI configuration record")
 }
 
 // GetVideoInfo retrieves detailed video file information using FFmpeg's ffprobe tool.
@@ -82,9 +105,53 @@ func GetVideoInfo(filename string) (*VideoInfo, error) {
 		return nil, fmt.Errorf("failed to parse ffprobe output: %v", err)
 	}
 
+	// HDR10+ metadata is often only carried by the frames, so the side data
+	// of the first frame is read as well when the stream looks like HDR10
+	if stream := info.VideoStream(); stream != nil && stream.HDRFormat() == "HDR10" {
+		stream.SideDataList = append(stream.SideDataList, probeFrameSideData(filename)...)
+	}
+
 	return &info, nil
 }
 
+// probeFrameSideData returns the side data of the first frame of the first
+// video stream, or nil when ffprobe cannot read it.
+func probeFrameSideData(filename string) []SideData {
+	// -select_streams v:0: Only read the first video stream
+	// -read_intervals %+#1: Stop after the first packet
+	// -show_entries frame=side_data_list: Only show the side data of the frames
+	cmd := exec.Command("ffprobe",
+		"-v", "quiet",
+		"-print_format", "json",
+		"-select_streams", "v:0",
+		"-read_intervals", "%+#1",
+		"-show_entries", "frame=side_data_list",
+		filename)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return parseFrameSideData(output)
+}
+
+// parseFrameSideData returns the side data of all frames in ffprobe JSON output.
+func parseFrameSideData(output []byte) []SideData {
+	var probe struct {
+		Frames []struct {
+			SideDataList []SideData `json:"side_data_list"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil
+	}
+	var sideData []SideData
+	for _, frame := range probe.Frames {
+		sideData = append(sideData, frame.SideDataList...)
+	}
+	return sideData
+}
+
 // IsVideoFile checks if a file has a video extension, determining whether
 // VidKit should process it as a video.
 //
@@ -99,7 +166,7 @@ func GetVideoInfo(filename string) (*VideoInfo, error) {
 //
 //	// Using default extensions
 //	isVideo := media.IsVideoFile("movie.mp4", nil)
-//	
+//
 //	// Using custom extensions
 //	customExts := []string{".mp4", ".mkv", ".mov"}
 //	isVideo := media.IsVideoFile("movie.avi", customExts)
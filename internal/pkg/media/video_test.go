@@ -252,3 +252,25 @@ func TestFormatSize(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFrameSideData(t *testing.T) {
+	output := []byte(`{"frames": [{"side_data_list": [
+		{"side_data_type": "Mastering display metadata"},
+		{"side_data_type": "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"}
+	]}]}`)
+	sideData := parseFrameSideData(output)
+	if len(sideData) != 2 || sideData[1].SideDataType != "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)" {
+		t.Fatalf("parseFrameSideData() = %+v, want both side data of the frame", sideData)
+	}
+
+	// HDR10+ signalled only in the frames is found once they are added
+	stream := Stream{ColorTransfer: "smpte2084"}
+	stream.SideDataList = append(stream.SideDataList, sideData...)
+	if got := stream.HDRFormat(); got != "HDR10+" {
+		t.Errorf("HDRFormat() = %q, want HDR10+", got)
+	}
+
+	if got := parseFrameSideData([]byte("not json")); got != nil {
+		t.Errorf("parseFrameSideData() = %+v, want nil for invalid output", got)
+	}
+}
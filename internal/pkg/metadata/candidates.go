@@ -0,0 +1,205 @@
+package metadata
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// Candidate is a movie or show found by searching a provider, scored by how
+// well it matches the search.
+type Candidate struct {
+	ID         string   // ID at the provider: TMDb, TvMaze or TVDb ID, IMDb ID for OMDb
+	Provider   string   // Provider that found the candidate (e.g., "tmdb")
+	Title      string   // Title in the requested language
+	AltTitles  []string // Original and alternative titles
+	Year       int      // Release or premiere year; 0 when unknown
+	Overview   string
+	Network    string
+	Runtime    int     // Runtime in minutes, of an episode for shows; 0 when unknown
+	Popularity float64 // Popularity at the provider; only compared between candidates
+	Votes      int     // Number of votes at the provider
+
+	Score float64 // How well the candidate matches the search, from 0 to 1
+}
+
+// CandidateQuery is what candidates are scored against.
+type CandidateQuery struct {
+	Title    string
+	Year     int // 0 when unknown
+	Duration int // Duration of the video in seconds, of an episode for shows; 0 when unknown
+}
+
+// Weights of the parts of a candidate's score. Parts that cannot be compared,
+// such as the year when the search has none, are left out.
+const (
+	titleWeight      = 0.6
+	yearWeight       = 0.2
+	runtimeWeight    = 0.1
+	popularityWeight = 0.1
+)
+
+// RankCandidates scores candidates against the query and returns them best
+// first, at most limit of them when limit is positive. Candidates are scored
+// by the similarity of their closest title, the distance between the years,
+// the agreement of their runtime with the duration of the video and, to
+// decide between otherwise equal candidates such as an original and its
+// remake, their popularity relative to the other candidates. Candidates with
+// equal scores keep the provider's order.
+func RankCandidates(query CandidateQuery, candidates []Candidate, limit int) []Candidate {
+	maxPopularity, maxVotes := 0.0, 0
+	for _, c := range candidates {
+		maxPopularity = math.Max(maxPopularity, c.Popularity)
+		maxVotes = max(maxVotes, c.Votes)
+	}
+
+	ranked := slices.Clone(candidates)
+	for i := range ranked {
+		ranked[i].Score = scoreCandidate(query, ranked[i], maxPopularity, maxVotes)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// candidateSearch identifies a search of a provider by what its candidates
+// are found and ranked with.
+type candidateSearch struct {
+	kind     string // "movie" or "series"
+	query    CandidateQuery
+	language string
+}
+
+// searchCache keeps the ranked candidates of the searches of a provider, so
+// listing the candidates of a title that was just looked up does not search
+// again.
+type searchCache map[candidateSearch][]Candidate
+
+// ranked returns the candidates of a search, at most limit of them when limit
+// is positive. search finds and ranks all candidates; it only runs when the
+// search is not cached yet. Failed searches are not cached.
+func (c *searchCache) ranked(key candidateSearch, limit int, search func() ([]Candidate, error)) ([]Candidate, error) {
+	ranked, ok := (*c)[key]
+	if !ok {
+		var err error
+		if ranked, err = search(); err != nil {
+			return nil, err
+		}
+		if *c == nil {
+			*c = searchCache{}
+		}
+		(*c)[key] = ranked
+	}
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return slices.Clone(ranked), nil
+}
+
+// rankWithRuntimes ranks candidates like RankCandidates. When the query has a
+// duration, the runtime of at most lookups candidates without one is looked
+// up first so it takes part in the ranking; runtime returns 0 when unknown.
+// A runtime moves a score by less than runtimeWeight, so only candidates that
+// close to the best one are looked up, and none when the best one leads by
+// more.
+func rankWithRuntimes(query CandidateQuery, candidates []Candidate, limit, lookups int, runtime func(Candidate) int) []Candidate {
+	if query.Duration == 0 {
+		return RankCandidates(query, candidates, limit)
+	}
+	ranked := RankCandidates(query, candidates, 0)
+	if len(ranked) < 2 || ranked[0].Score-ranked[1].Score > runtimeWeight {
+		return RankCandidates(query, ranked, limit)
+	}
+	for i := range ranked {
+		if lookups == 0 || ranked[0].Score-ranked[i].Score > runtimeWeight {
+			break
+		}
+		if ranked[i].Runtime == 0 {
+			ranked[i].Runtime = runtime(ranked[i])
+			lookups--
+		}
+	}
+	return RankCandidates(query, ranked, limit)
+}
+
+// scoreCandidate returns the score of a candidate from 0 to 1.
+func scoreCandidate(query CandidateQuery, c Candidate, maxPopularity float64, maxVotes int) float64 {
+	title := bestTitleSimilarity(query.Title, c.Title, c.AltTitles)
+	score, weight := titleWeight*title, titleWeight
+
+	if query.Year > 0 {
+		score += yearWeight * yearScore(query.Year, c.Year)
+		weight += yearWeight
+	}
+	if query.Duration > 0 && c.Runtime > 0 {
+		score += runtimeWeight * runtimeScore(query.Duration, c.Runtime)
+		weight += runtimeWeight
+	}
+	if maxPopularity > 0 || maxVotes > 0 {
+		score += popularityWeight * popularityScore(c, maxPopularity, maxVotes)
+		weight += popularityWeight
+	}
+	return score / weight
+}
+
+// yearScore returns 1 for the same year, falling by 0.2 per year of
+// difference. Candidates without a year score 0.5.
+func yearScore(queryYear, year int) float64 {
+	if year == 0 {
+		return 0.5
+	}
+	diff := queryYear - year
+	if diff < 0 {
+		diff = -diff
+	}
+	return math.Max(0, 1-float64(diff)/5)
+}
+
+// runtimeScore returns 1 when the duration is within 10% of the runtime,
+// falling to 0 at a difference of 50%.
+func runtimeScore(duration, runtime int) float64 {
+	expected := float64(runtime * 60)
+	diff := math.Abs(float64(duration)-expected) / expected
+	return math.Max(0, math.Min(1, (0.5-diff)/0.4))
+}
+
+// popularityScore compares the popularity and votes of a candidate with the
+// highest of all candidates. Votes are compared on a logarithmic scale.
+func popularityScore(c Candidate, maxPopularity float64, maxVotes int) float64 {
+	var sum float64
+	var parts int
+	if maxPopularity > 0 {
+		sum += c.Popularity / maxPopularity
+		parts++
+	}
+	if maxVotes > 0 {
+		sum += math.Log1p(float64(c.Votes)) / math.Log1p(float64(maxVotes))
+		parts++
+	}
+	return sum / float64(parts)
+}
+
+// movieQuery returns what the candidates of a movie search are scored
+// against. The parts of a movie split across files are shorter than its
+// runtime, so their duration is not compared.
+func movieQuery(search MovieSearch) CandidateQuery {
+	query := CandidateQuery{Title: search.Title, Year: search.Year}
+	if search.Part == 0 {
+		query.Duration = search.Duration
+	}
+	return query
+}
+
+// tvShowQuery returns what the candidates of an episode search are scored
+// against. Files holding several episodes compare the runtime of one.
+func tvShowQuery(search TVShowSearch) CandidateQuery {
+	duration := search.Duration
+	if episodes := len(search.EpisodeNumbers()); episodes > 1 {
+		duration /= episodes
+	}
+	return CandidateQuery{Title: search.Title, Year: search.Year, Duration: duration}
+}
@@ -0,0 +1,157 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankCandidates(t *testing.T) {
+	offices := []Candidate{
+		{ID: "uk", Title: "The Office", Year: 2001, Network: "BBC Two", Runtime: 30},
+		{ID: "us", Title: "The Office", Year: 2005, Network: "NBC", Runtime: 22},
+		{ID: "girls", Title: "The Office Girls", Year: 2012, Runtime: 60},
+	}
+	heists := []Candidate{
+		{ID: "house", Title: "Das Haus", Year: 2021},
+		{ID: "heist", Title: "Money Heist", AltTitles: []string{"La casa de papel", "Haus des Geldes"}, Year: 2017},
+	}
+	snatchers := []Candidate{
+		{ID: "1978", Title: "Invasion of the Body Snatchers", Year: 1978, Runtime: 115},
+		{ID: "1956", Title: "Invasion of the Body Snatchers", Year: 1956, Runtime: 80},
+	}
+	remakes := []Candidate{
+		{ID: "remake", Title: "Solaris", Year: 2002, Popularity: 30, Votes: 2000},
+		{ID: "original", Title: "Solaris", Year: 1972, Popularity: 15, Votes: 1500},
+	}
+
+	tests := []struct {
+		name       string
+		query      CandidateQuery
+		candidates []Candidate
+		limit      int
+		want       []string
+	}{
+		{
+			name:       "Year picks the US show",
+			query:      CandidateQuery{Title: "The Office", Year: 2005},
+			candidates: offices,
+			want:       []string{"us", "uk", "girls"},
+		},
+		{
+			name:       "Year picks the UK show",
+			query:      CandidateQuery{Title: "The Office", Year: 2001},
+			candidates: offices,
+			want:       []string{"uk", "us", "girls"},
+		},
+		{
+			name:       "Runtime picks the US show",
+			query:      CandidateQuery{Title: "The Office", Duration: 21*60 + 30},
+			candidates: offices,
+			want:       []string{"us", "uk", "girls"},
+		},
+		{
+			name:       "Alternative title",
+			query:      CandidateQuery{Title: "Haus des Geldes", Year: 2017},
+			candidates: heists,
+			want:       []string{"heist", "house"},
+		},
+		{
+			name:       "Popularity decides without year",
+			query:      CandidateQuery{Title: "Solaris"},
+			candidates: remakes,
+			want:       []string{"remake", "original"},
+		},
+		{
+			name:       "Year beats popularity",
+			query:      CandidateQuery{Title: "Solaris", Year: 1972},
+			candidates: remakes,
+			want:       []string{"original", "remake"},
+		},
+		{
+			name:       "Runtime decides between remakes",
+			query:      CandidateQuery{Title: "Invasion of the Body Snatchers", Duration: 80*60 + 25},
+			candidates: snatchers,
+			want:       []string{"1956", "1978"},
+		},
+		{
+			name:       "Limit",
+			query:      CandidateQuery{Title: "The Office", Year: 2005},
+			candidates: offices,
+			limit:      1,
+			want:       []string{"us"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := RankCandidates(tt.query, tt.candidates, tt.limit)
+			var got []string
+			for i, c := range ranked {
+				got = append(got, c.ID)
+				if c.Score < 0 || c.Score > 1 || (i > 0 && c.Score > ranked[i-1].Score) {
+					t.Errorf("RankCandidates() score of %s = %v, want scores from 0 to 1 in descending order", c.ID, c.Score)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RankCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankCandidatesExactMatch(t *testing.T) {
+	ranked := RankCandidates(CandidateQuery{Title: "The Office", Year: 2005}, []Candidate{{Title: "Office", Year: 2005}}, 0)
+	if ranked[0].Score != 1 {
+		t.Errorf("RankCandidates() score = %v, want 1 for a matching title and year", ranked[0].Score)
+	}
+}
+
+func TestRankWithRuntimes(t *testing.T) {
+	remakes := []Candidate{
+		{ID: "1978", Title: "Invasion of the Body Snatchers", Year: 1978},
+		{ID: "1956", Title: "Invasion of the Body Snatchers", Year: 1956},
+		{ID: "2007", Title: "The Invasion", Year: 2007},
+	}
+	runtimes := map[string]int{"1978": 115, "1956": 80, "2007": 99}
+
+	tests := []struct {
+		name        string
+		query       CandidateQuery
+		lookups     int
+		want        []string
+		wantLookups []string
+	}{
+		{
+			name:  "Close scores look up runtimes",
+			query: CandidateQuery{Title: "Invasion of the Body Snatchers", Duration: 80 * 60}, lookups: 5,
+			want: []string{"1956", "1978", "2007"}, wantLookups: []string{"1978", "1956"},
+		},
+		{
+			name:  "Lookups are limited",
+			query: CandidateQuery{Title: "Invasion of the Body Snatchers", Duration: 80 * 60}, lookups: 1,
+			want: []string{"1956", "1978", "2007"}, wantLookups: []string{"1978"},
+		},
+		{
+			name:  "Clear leader needs no runtimes",
+			query: CandidateQuery{Title: "Invasion of the Body Snatchers", Year: 1978, Duration: 80 * 60}, lookups: 5,
+			want: []string{"1978", "1956", "2007"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var looked []string
+			ranked := rankWithRuntimes(tt.query, remakes, 0, tt.lookups, func(c Candidate) int {
+				looked = append(looked, c.ID)
+				return runtimes[c.ID]
+			})
+			var got []string
+			for _, c := range ranked {
+				got = append(got, c.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(looked, tt.wantLookups) {
+				t.Errorf("rankWithRuntimes() = %v after looking up %v, want %v after %v", got, looked, tt.want, tt.wantLookups)
+			}
+		})
+	}
+}
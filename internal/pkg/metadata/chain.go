@@ -0,0 +1,236 @@
+package metadata
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// MinResultConfidence is the confidence below which a provider chain asks the
+// next provider. A matching title with a wrong year, or a show without the
+// requested episode, falls below it.
+const MinResultConfidence = 0.6
+
+// ProviderChain is a MetadataProvider that asks several providers in order.
+// A provider that fails, finds nothing or answers with a result that does not
+// match the search well is followed by the next one. When no provider gives
+// a good match, the best of the weak results is returned.
+type ProviderChain struct {
+	names     []string
+	providers []MetadataProvider
+}
+
+// Ensure ProviderChain implements MetadataProvider
+var _ MetadataProvider = (*ProviderChain)(nil)
+
+// NewProviderChain creates an empty provider chain.
+func NewProviderChain() *ProviderChain {
+	return &ProviderChain{}
+}
+
+// Add appends a provider to the chain. The name labels its errors.
+func (c *ProviderChain) Add(name string, provider MetadataProvider) {
+	c.names = append(c.names, name)
+	c.providers = append(c.providers, provider)
+}
+
+// SearchMovie asks each provider in turn until one finds a good match.
+// Movies picked at a provider are only looked up there.
+func (c *ProviderChain) SearchMovie(search MovieSearch, language string) (*MovieMetadata, error) {
+	var best *MovieMetadata
+	var errs []string
+	for i, provider := range c.providers {
+		if search.Provider != "" && c.names[i] != search.Provider {
+			continue
+		}
+		movie, err := provider.SearchMovie(search, language)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.names[i], err))
+			continue
+		}
+		if movie == nil {
+			continue
+		}
+		movie.Confidence = MovieResultConfidence(search, movie)
+		if movie.Confidence >= MinResultConfidence {
+			return movie, nil
+		}
+		if best == nil || movie.Confidence > best.Confidence {
+			best = movie
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+	return nil, chainError(search.Title, errs)
+}
+
+// SearchTVShow asks each provider in turn until one finds a good match.
+// Shows picked at a provider are only looked up there.
+func (c *ProviderChain) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
+	var best *TVShowMetadata
+	var errs []string
+	for i, provider := range c.providers {
+		if search.Provider != "" && c.names[i] != search.Provider {
+			continue
+		}
+		show, err := provider.SearchTVShow(search, language)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.names[i], err))
+			continue
+		}
+		if show == nil {
+			continue
+		}
+		show.Confidence = TVShowResultConfidence(search, show)
+		if show.Confidence >= MinResultConfidence {
+			return show, nil
+		}
+		if best == nil || show.Confidence > best.Confidence {
+			best = show
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+	return nil, chainError(search.Title, errs)
+}
+
+// MovieCandidates gathers the candidates of every provider and ranks them
+// together. Providers that fail are left out.
+func (c *ProviderChain) MovieCandidates(search MovieSearch, language string, limit int) ([]Candidate, error) {
+	var candidates []Candidate
+	var errs []string
+	for i, provider := range c.providers {
+		found, err := provider.MovieCandidates(search, language, limit)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.names[i], err))
+			continue
+		}
+		candidates = append(candidates, found...)
+	}
+	if len(candidates) == 0 {
+		return nil, chainError(search.Title, errs)
+	}
+	return RankCandidates(movieQuery(search), candidates, limit), nil
+}
+
+// TVShowCandidates gathers the candidates of every provider and ranks them
+// together. Providers that fail are left out.
+func (c *ProviderChain) TVShowCandidates(search TVShowSearch, language string, limit int) ([]Candidate, error) {
+	var candidates []Candidate
+	var errs []string
+	for i, provider := range c.providers {
+		found, err := provider.TVShowCandidates(search, language, limit)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.names[i], err))
+			continue
+		}
+		candidates = append(candidates, found...)
+	}
+	if len(candidates) == 0 {
+		return nil, chainError(search.Title, errs)
+	}
+	return RankCandidates(tvShowQuery(search), candidates, limit), nil
+}
+
+// chainError combines the errors of every provider of a chain.
+func chainError(title string, errs []string) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("no provider found '%s'", title)
+	}
+	return fmt.Errorf("no provider found '%s' (%s)", title, strings.Join(errs, "; "))
+}
+
+// MovieResultConfidence rates how well a movie found by a provider matches
+// the search, from 0 to 1. Movies looked up by ID always match.
+func MovieResultConfidence(search MovieSearch, movie *MovieMetadata) float64 {
+	if search.IMDbID != "" || search.TMDbID > 0 || search.ProviderID != "" {
+		return 1
+	}
+	return bestTitleSimilarity(search.Title, movie.Title, movie.AltTitles) * yearAgreement(search.Year, movie.Year)
+}
+
+// TVShowResultConfidence rates how well a show found by a provider matches
+// the search, from 0 to 1. A show without the requested episode is only half
+// a match.
+func TVShowResultConfidence(search TVShowSearch, show *TVShowMetadata) float64 {
+	confidence := 1.0
+	if search.IMDbID == "" && search.TMDbID == 0 && search.ProviderID == "" {
+		confidence = bestTitleSimilarity(search.Title, show.Title, show.AltTitles) * yearAgreement(search.Year, show.Year)
+	}
+	if search.IsEpisode() && show.EpisodeTitle == "" {
+		confidence *= 0.5
+	}
+	return confidence
+}
+
+// yearAgreement returns 1 when the years agree, allowing for releases late in
+// the year, or are unknown, and 0.5 otherwise.
+func yearAgreement(searchYear, resultYear int) float64 {
+	if searchYear == 0 || resultYear == 0 {
+		return 1
+	}
+	if diff := searchYear - resultYear; diff >= -1 && diff <= 1 {
+		return 1
+	}
+	return 0.5
+}
+
+// bestTitleSimilarity compares the searched title with the title of a result
+// and with its original and alternative titles, and returns the best match.
+func bestTitleSimilarity(search, title string, altTitles []string) float64 {
+	similarity := TitleSimilarity(search, title)
+	for _, alt := range altTitles {
+		similarity = math.Max(similarity, TitleSimilarity(search, alt))
+	}
+	return similarity
+}
+
+// TitleSimilarity compares two titles, ignoring case, punctuation and
+// leading articles, from 0 for no common word to 1 for the same title.
+//
+// Example:
+//
+//	metadata.TitleSimilarity("The Office", "Office")       // 1
+//	metadata.TitleSimilarity("The Office US", "The Office") // 0.67
+func TitleSimilarity(a, b string) float64 {
+	wordsA, wordsB := titleWords(a), titleWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+	if strings.Join(wordsA, " ") == strings.Join(wordsB, " ") {
+		return 1
+	}
+
+	// Dice coefficient of the words
+	counts := make(map[string]int, len(wordsA))
+	for _, word := range wordsA {
+		counts[word]++
+	}
+	common := 0
+	for _, word := range wordsB {
+		if counts[word] > 0 {
+			counts[word]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wordsA)+len(wordsB))
+}
+
+// titleWords returns the lowercase words of a title without punctuation and
+// leading article.
+func titleWords(title string) []string {
+	title = strings.ReplaceAll(strings.ToLower(title), "&", " and ")
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "'", "")
+	}
+	if len(words) > 1 && (words[0] == "the" || words[0] == "a" || words[0] == "an") {
+		words = words[1:]
+	}
+	return words
+}
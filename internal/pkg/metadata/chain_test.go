@@ -0,0 +1,193 @@
+package metadata
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// fakeProvider answers every search with the same result or error.
+type fakeProvider struct {
+	movie *MovieMetadata
+	show  *TVShowMetadata
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) SearchMovie(search MovieSearch, language string) (*MovieMetadata, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	movie := *p.movie
+	return &movie, nil
+}
+
+func (p *fakeProvider) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	show := *p.show
+	return &show, nil
+}
+
+func (p *fakeProvider) MovieCandidates(search MovieSearch, language string, limit int) ([]Candidate, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []Candidate{{Provider: p.movie.Provider, Title: p.movie.Title, Year: p.movie.Year}}, nil
+}
+
+func (p *fakeProvider) TVShowCandidates(search TVShowSearch, language string, limit int) ([]Candidate, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []Candidate{{Provider: p.show.Provider, Title: p.show.Title, Year: p.show.Year}}, nil
+}
+
+func TestProviderChain_SearchMovie(t *testing.T) {
+	failing := func() *fakeProvider { return &fakeProvider{err: errors.New("daily limit reached")} }
+	answering := func(title string, year int, provider string) *fakeProvider {
+		return &fakeProvider{movie: &MovieMetadata{Title: title, Year: year, Provider: provider}}
+	}
+
+	tests := []struct {
+		name         string
+		providers    []*fakeProvider
+		wantProvider string
+		wantCalls    []int
+		wantErr      bool
+	}{
+		{
+			name:         "First provider matches",
+			providers:    []*fakeProvider{answering("Das Boot", 1981, "tmdb"), answering("Das Boot", 1981, "omdb")},
+			wantProvider: "tmdb",
+			wantCalls:    []int{1, 0},
+		},
+		{
+			name:         "Error moves on to the next provider",
+			providers:    []*fakeProvider{failing(), answering("Das Boot", 1981, "omdb")},
+			wantProvider: "omdb",
+			wantCalls:    []int{1, 1},
+		},
+		{
+			name:         "Other title moves on to the next provider",
+			providers:    []*fakeProvider{answering("The Boat Race", 1981, "tmdb"), answering("Das Boot", 1981, "omdb")},
+			wantProvider: "omdb",
+			wantCalls:    []int{1, 1},
+		},
+		{
+			name: "Original title matches",
+			providers: []*fakeProvider{
+				{movie: &MovieMetadata{Title: "The Boat", AltTitles: []string{"Das Boot"}, Year: 1981, Provider: "tmdb"}},
+				answering("Das Boot", 1981, "omdb"),
+			},
+			wantProvider: "tmdb",
+			wantCalls:    []int{1, 0},
+		},
+		{
+			name:         "Best weak result when nothing matches",
+			providers:    []*fakeProvider{answering("Das Boot", 1985, "tmdb"), answering("Boat", 1981, "omdb")},
+			wantProvider: "tmdb",
+			wantCalls:    []int{1, 1},
+		},
+		{
+			name:      "Every provider fails",
+			providers: []*fakeProvider{failing(), failing()},
+			wantCalls: []int{1, 1},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewProviderChain()
+			for i, p := range tt.providers {
+				chain.Add([]string{"first", "second"}[i], p)
+			}
+			movie, err := chain.SearchMovie(MovieSearch{Title: "Das Boot", Year: 1981}, "en")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchMovie() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && movie.Provider != tt.wantProvider {
+				t.Errorf("SearchMovie() provider = %q, want %q", movie.Provider, tt.wantProvider)
+			}
+			for i, p := range tt.providers {
+				if p.calls != tt.wantCalls[i] {
+					t.Errorf("provider %d called %d times, want %d", i, p.calls, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProviderChain_SearchTVShow(t *testing.T) {
+	withoutEpisode := &fakeProvider{show: &TVShowMetadata{Title: "Kommissar Rex", Provider: "tvmaze"}}
+	withEpisode := &fakeProvider{show: &TVShowMetadata{Title: "Kommissar Rex", Season: 1, Episode: 2, EpisodeTitle: "Ein Mann für Rex", Provider: "tmdb"}}
+
+	chain := NewProviderChain()
+	chain.Add("tvmaze", withoutEpisode)
+	chain.Add("tmdb", withEpisode)
+	show, err := chain.SearchTVShow(TVShowSearch{Title: "Kommissar Rex", Season: 1, Episode: 2}, "de")
+	if err != nil {
+		t.Fatalf("SearchTVShow() error = %v", err)
+	}
+	if show.Provider != "tmdb" || show.Confidence != 1 {
+		t.Errorf("SearchTVShow() = %+v, want the episode from tmdb with confidence 1", show)
+	}
+
+	failing := NewProviderChain()
+	failing.Add("tvmaze", &fakeProvider{err: errors.New("no TV shows found")})
+	failing.Add("tvdb", &fakeProvider{err: errors.New("unauthorized")})
+	_, err = failing.SearchTVShow(TVShowSearch{Title: "Kommissar Rex", Season: 1, Episode: 2}, "de")
+	if want := "no provider found 'Kommissar Rex' (tvmaze: no TV shows found; tvdb: unauthorized)"; err == nil || err.Error() != want {
+		t.Errorf("SearchTVShow() error = %v, want %q", err, want)
+	}
+}
+
+func TestTVShowResultConfidence(t *testing.T) {
+	show := &TVShowMetadata{Title: "Inspector Rex", AltTitles: []string{"Kommissar Rex", "Rex: A Cop's Best Friend"}, Year: 1994}
+	if got := TVShowResultConfidence(TVShowSearch{Title: "Kommissar Rex"}, show); got != 1 {
+		t.Errorf("TVShowResultConfidence() = %v for an alternative title, want 1", got)
+	}
+	if got := TVShowResultConfidence(TVShowSearch{Title: "Baywatch"}, show); got != 0 {
+		t.Errorf("TVShowResultConfidence() = %v for another title, want 0", got)
+	}
+}
+
+func TestProviderChain_PickedProvider(t *testing.T) {
+	tmdb := &fakeProvider{movie: &MovieMetadata{Title: "Solaris", Year: 2002, Provider: "tmdb"}}
+	omdb := &fakeProvider{movie: &MovieMetadata{Title: "Solaris", Year: 1972, Provider: "omdb"}}
+
+	chain := NewProviderChain()
+	chain.Add("tmdb", tmdb)
+	chain.Add("omdb", omdb)
+	movie, err := chain.SearchMovie(MovieSearch{Title: "Solaris", Year: 2002, Provider: "omdb", ProviderID: "tt0069293"}, "en")
+	if err != nil {
+		t.Fatalf("SearchMovie() error = %v", err)
+	}
+	if movie.Provider != "omdb" || movie.Confidence != 1 || tmdb.calls != 0 {
+		t.Errorf("SearchMovie() = %+v after %d calls to tmdb, want the picked movie from omdb only", movie, tmdb.calls)
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"The Office", "Office", 1},
+		{"Marvel's Agents of S.H.I.E.L.D.", "Marvels Agents of S H I E L D", 1},
+		{"Law & Order", "Law and Order", 1},
+		{"The Office US", "The Office", 2.0 / 3},
+		{"Das Boot", "The Boat", 0},
+		{"", "Das Boot", 0},
+	}
+
+	for _, tt := range tests {
+		if got := TitleSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("TitleSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package metadata
+
+import (
+	"testing"
+)
+
+func TestExtractMovieInfoEdition(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     MovieSearch
+	}{
+		{
+			name:     "Director's cut in brackets",
+			filename: "Blade Runner (1982) [Director's Cut].mkv",
+			want:     MovieSearch{Title: "Blade Runner", Year: 1982, Edition: "Director's Cut"},
+		},
+		{
+			name:     "Dotted final cut",
+			filename: "Blade Runner (1982).Final.Cut.1080p.mkv",
+			want:     MovieSearch{Title: "Blade Runner", Year: 1982, Edition: "Final Cut"},
+		},
+		{
+			name:     "Several editions keep their order",
+			filename: "Aliens (1986) Extended Remastered.mkv",
+			want:     MovieSearch{Title: "Aliens", Year: 1986, Edition: "Extended Remastered"},
+		},
+		{
+			name:     "Edition without year",
+			filename: "The.Dark.Knight.IMAX.mkv",
+			want:     MovieSearch{Title: "The Dark Knight", Edition: "IMAX"},
+		},
+		{
+			name:     "Plex edition tag",
+			filename: "Apocalypse Now (1979) {edition-Redux}.mkv",
+			want:     MovieSearch{Title: "Apocalypse Now", Year: 1979, Edition: "Redux"},
+		},
+		{
+			name:     "Criterion collection and unrated",
+			filename: "Brazil (1985) Criterion Collection Unrated.mkv",
+			want:     MovieSearch{Title: "Brazil", Year: 1985, Edition: "Criterion Unrated"},
+		},
+		{
+			name:     "Edition words inside the title are kept",
+			filename: "The Extendedness (2010).mkv",
+			want:     MovieSearch{Title: "The Extendedness", Year: 2010},
+		},
+		{
+			name:     "No edition",
+			filename: "The Matrix (1999).mkv",
+			want:     MovieSearch{Title: "The Matrix", Year: 1999},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Confidence is covered by TestConfidence
+			got := ExtractMovieInfo(tt.filename)
+			got.Confidence = 0
+			if got != tt.want {
+				t.Errorf("ExtractMovieInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package metadata
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/tekenstam/vidkit/internal/pkg/release"
+)
+
+// How tags embedded in video containers are used for identification
+const (
+	EmbeddedTagsAuto   = "auto"   // Tags are weighed against the filename and usually win
+	EmbeddedTagsPrefer = "prefer" // Tags win whenever they identify the video
+	EmbeddedTagsIgnore = "ignore" // Only the filename and directories are used
+)
+
+// EmbeddedTagsModes lists the supported ways of using embedded tags.
+var EmbeddedTagsModes = []string{EmbeddedTagsAuto, EmbeddedTagsPrefer, EmbeddedTagsIgnore}
+
+// Names of the embedded tags written by iTunes-style MP4 taggers and Matroska
+// muxers, lowercase as returned by media.VideoInfo.Tags.
+var (
+	showTags    = []string{"show", "tvshow", "series"}
+	seasonTags  = []string{"season_number", "season"}
+	episodeTags = []string{"episode_sort", "episode_number", "episode"}
+	dateTags    = []string{"date_released", "date", "year"}
+	imdbTags    = []string{"imdb", "imdb_id", "imdbid"}
+	tmdbTags    = []string{"tmdb", "tmdb_id", "tmdbid"}
+)
+
+// iTunes media kinds of the "media_type" tag (stik atom)
+const (
+	mediaTypeMovie  = "9"
+	mediaTypeTVShow = "10"
+)
+
+var (
+	// imdbIDPattern matches IMDb IDs, also inside URLs: tt1375666
+	imdbIDPattern = regexp.MustCompile(`\btt\d{7,}\b`)
+
+	// tmdbIDPattern matches TMDb IDs, optionally with their kind: 27205, movie/27205, tv/1399
+	tmdbIDPattern = regexp.MustCompile(`^(?:(movie|tv)/)?(\d+)$`)
+
+	// tagYearPattern matches the year of a date tag: 2010, 2010-07-16, 2010-07-16T07:00:00Z
+	tagYearPattern = regexp.MustCompile(`^(\d{4})(?:$|\D)`)
+)
+
+// embeddedConfidence is the confidence of episodes and movies identified by
+// an ID or by a complete set of tags.
+const embeddedConfidence = 1.0
+
+// ExtractEmbeddedTVShowInfo identifies an episode from the tags embedded in
+// its container: the show, season and episode number, with the "title" tag
+// naming the episode. It returns an empty search when the tags do not
+// identify an episode.
+func ExtractEmbeddedTVShowInfo(tags map[string]string) TVShowSearch {
+	show := tagValue(tags, showTags...)
+	if show == "" {
+		return TVShowSearch{}
+	}
+	search := TVShowSearch{
+		Title:      show,
+		IMDbID:     imdbID(tags),
+		TMDbID:     tmdbID(tags, "tv"),
+		Confidence: embeddedConfidence,
+	}
+
+	season, seasonErr := strconv.Atoi(tagValue(tags, seasonTags...))
+	episode, episodeErr := strconv.Atoi(tagValue(tags, episodeTags...))
+	if seasonErr == nil && episodeErr == nil {
+		search.Season, search.Special, search.Episode = season, season == 0, episode
+	} else if id := tags["episode_id"]; id != "" {
+		// iTunes sometimes only has an episode ID such as "S01E02"
+		parsed := release.Parse(id)
+		search.Season, search.Special, search.Episode = parsed.Season, parsed.Special, parsed.Episode()
+	}
+	if !search.IsEpisode() {
+		return TVShowSearch{}
+	}
+
+	if title := tags["title"]; title != show {
+		search.EpisodeTitle = title
+	}
+	return search
+}
+
+// ExtractEmbeddedMovieInfo identifies a movie from the tags embedded in its
+// container: an IMDb or TMDb ID, or the title and release date. Titles are
+// parsed like filenames, as some muxers store the release name. It returns an
+// empty search when the tags describe an episode or do not name a movie.
+func ExtractEmbeddedMovieInfo(tags map[string]string) MovieSearch {
+	if tagValue(tags, showTags...) != "" || tags["media_type"] == mediaTypeTVShow {
+		return MovieSearch{}
+	}
+
+	// Release names of episodes describe no movie
+	parsed := release.Parse(tags["title"])
+	if parsed.IsEpisode() {
+		return MovieSearch{}
+	}
+	search := MovieSearch{
+		Title:   parsed.Title,
+		Year:    parsed.Year,
+		Edition: parsed.Edition,
+		IMDbID:  imdbID(tags),
+		TMDbID:  tmdbID(tags, "movie"),
+	}
+	if search.Year == 0 {
+		if m := tagYearPattern.FindStringSubmatch(tagValue(tags, dateTags...)); m != nil {
+			search.Year, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	// A title alone is no better than a filename, unless the tags say the
+	// file is a movie
+	switch {
+	case search.IMDbID != "" || search.TMDbID > 0:
+		search.Confidence = embeddedConfidence
+	case search.Title == "":
+		return MovieSearch{}
+	case search.Year > 0 || tags["media_type"] == mediaTypeMovie:
+		search.Confidence = 0.9
+	default:
+		search.Confidence = 0.5
+	}
+	return search
+}
+
+// tagValue returns the value of the first of the named tags that is set.
+func tagValue(tags map[string]string, names ...string) string {
+	for _, name := range names {
+		if value := tags[name]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// imdbID returns the IMDb ID of the tags, or an empty string when there is
+// none.
+func imdbID(tags map[string]string) string {
+	return imdbIDPattern.FindString(tagValue(tags, imdbTags...))
+}
+
+// tmdbID returns the TMDb ID of the tags for the given kind ("movie" or
+// "tv"), or 0 when there is none. IDs without a kind are accepted for both.
+func tmdbID(tags map[string]string, kind string) int {
+	m := tmdbIDPattern.FindStringSubmatch(tagValue(tags, tmdbTags...))
+	if m == nil || (m[1] != "" && m[1] != kind) {
+		return 0
+	}
+	id, _ := strconv.Atoi(m[2])
+	return id
+}
@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractEmbeddedTVShowInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string]string
+		want TVShowSearch
+	}{
+		{
+			name: "iTunes episode",
+			tags: map[string]string{"show": "Breaking Bad", "season_number": "1", "episode_sort": "2", "title": "Cat's in the Bag...", "media_type": "10"},
+			want: TVShowSearch{Title: "Breaking Bad", Season: 1, Episode: 2, EpisodeTitle: "Cat's in the Bag...", Confidence: 1},
+		},
+		{
+			name: "Episode ID only",
+			tags: map[string]string{"show": "Breaking Bad", "episode_id": "S01E03", "imdb": "tt0903747"},
+			want: TVShowSearch{Title: "Breaking Bad", Season: 1, Episode: 3, IMDbID: "tt0903747", Confidence: 1},
+		},
+		{
+			name: "Special",
+			tags: map[string]string{"show": "Doctor Who", "season_number": "0", "episode_sort": "5", "tmdb": "tv/57243"},
+			want: TVShowSearch{Title: "Doctor Who", Special: true, Episode: 5, TMDbID: 57243, Confidence: 1},
+		},
+		{
+			name: "Show without episode",
+			tags: map[string]string{"show": "Breaking Bad", "title": "Breaking Bad"},
+		},
+		{
+			name: "Movie",
+			tags: map[string]string{"title": "Inception", "date": "2010"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractEmbeddedTVShowInfo(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractEmbeddedTVShowInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractEmbeddedMovieInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string]string
+		want MovieSearch
+	}{
+		{
+			name: "Title and date",
+			tags: map[string]string{"title": "Inception", "date": "2010-07-16"},
+			want: MovieSearch{Title: "Inception", Year: 2010, Confidence: 0.9},
+		},
+		{
+			name: "Matroska IDs",
+			tags: map[string]string{"title": "Inception", "imdb": "https://www.imdb.com/title/tt1375666/", "tmdb": "movie/27205"},
+			want: MovieSearch{Title: "Inception", IMDbID: "tt1375666", TMDbID: 27205, Confidence: 1},
+		},
+		{
+			name: "Release name as title",
+			tags: map[string]string{"title": "Blade.Runner.1982.Final.Cut.1080p.BluRay.x264-GRP"},
+			want: MovieSearch{Title: "Blade Runner", Year: 1982, Edition: "Final Cut", Confidence: 0.9},
+		},
+		{
+			name: "Title only",
+			tags: map[string]string{"title": "Inception"},
+			want: MovieSearch{Title: "Inception", Confidence: 0.5},
+		},
+		{
+			name: "TMDb ID of a show",
+			tags: map[string]string{"title": "Inception", "date": "2010", "tmdb": "tv/1399"},
+			want: MovieSearch{Title: "Inception", Year: 2010, Confidence: 0.9},
+		},
+		{
+			name: "Episode",
+			tags: map[string]string{"show": "Breaking Bad", "title": "Pilot"},
+		},
+		{
+			name: "Release name of an episode",
+			tags: map[string]string{"title": "Breaking.Bad.S01E05.Gray.Matter.720p.BluRay.x264-DEMAND"},
+		},
+		{
+			name: "TV show media type",
+			tags: map[string]string{"title": "Pilot", "media_type": "10"},
+		},
+		{
+			name: "No tags",
+			tags: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractEmbeddedMovieInfo(tt.tags); got != tt.want {
+				t.Errorf("ExtractEmbeddedMovieInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Episode title styles for files containing more than one episode
+const (
+	EpisodeTitleJoin     = "join"     // "Pilot & Cat's in the Bag..."
+	EpisodeTitleFirst    = "first"    // "Pilot"
+	EpisodeTitleCollapse = "collapse" // "Finale (1)" and "Finale (2)" become "Finale"; others are joined
+)
+
+// EpisodeTitleStyles lists the supported episode title styles.
+var EpisodeTitleStyles = []string{EpisodeTitleJoin, EpisodeTitleFirst, EpisodeTitleCollapse}
+
+// episodeTitleSeparator is placed between joined episode titles.
+const episodeTitleSeparator = " & "
+
+// EpisodeNumbers returns every episode contained in the file, in order.
+// Single-episode searches return just Episode; an empty result means the
+// episode is unknown.
+func (s TVShowSearch) EpisodeNumbers() []int {
+	return episodeNumbers(s.Episode, s.Episodes)
+}
+
+// IsEpisode reports whether the search identifies a single TV episode, by
+// season and episode, by air date or by absolute episode number. Specials
+// are episodes of season 0.
+func (s TVShowSearch) IsEpisode() bool {
+	return ((s.Season > 0 || s.Special) && s.Episode > 0) || s.AirDate != "" || s.AbsoluteEpisode > 0
+}
+
+// EpisodeNumbers returns every episode the metadata describes, in order.
+func (m TVShowMetadata) EpisodeNumbers() []int {
+	return episodeNumbers(m.Episode, m.Episodes)
+}
+
+func episodeNumbers(episode int, episodes []int) []int {
+	if len(episodes) > 0 {
+		return episodes
+	}
+	if episode > 0 {
+		return []int{episode}
+	}
+	return nil
+}
+
+// setEpisodeTitles stores the episode titles fetched for a search. For
+// multi-episode files the episode list and every title are kept, and the
+// episode title combines them in the default style.
+func setEpisodeTitles(metadata *TVShowMetadata, search TVShowSearch, titles []string) {
+	if len(search.Episodes) > 1 {
+		metadata.Episodes = search.Episodes
+		metadata.EpisodeTitles = titles
+	}
+	metadata.EpisodeTitle = JoinEpisodeTitles(titles, EpisodeTitleCollapse)
+}
+
+// FormatEpisodeRange formats a season and its episodes as an SxxEyy marker.
+// Consecutive episodes are written as a range, others are chained.
+//
+// Example:
+//
+//	metadata.FormatEpisodeRange(1, []int{1, 2, 3}) // "S01E01-E03"
+//	metadata.FormatEpisodeRange(1, []int{1, 3})    // "S01E01E03"
+//	metadata.FormatEpisodeRange(1, []int{5})       // "S01E05"
+func FormatEpisodeRange(season int, episodes []int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "S%02d", season)
+	if len(episodes) == 0 {
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "E%02d", episodes[0])
+	if len(episodes) == 1 {
+		return sb.String()
+	}
+
+	consecutive := true
+	for i := 1; i < len(episodes); i++ {
+		if episodes[i] != episodes[i-1]+1 {
+			consecutive = false
+			break
+		}
+	}
+	if consecutive {
+		fmt.Fprintf(&sb, "-E%02d", episodes[len(episodes)-1])
+		return sb.String()
+	}
+	for _, episode := range episodes[1:] {
+		fmt.Fprintf(&sb, "E%02d", episode)
+	}
+	return sb.String()
+}
+
+// partSuffix matches part markers at the end of episode titles, such as
+// "Finale (1)", "Finale, Part 2" or "Finale Pt. 3".
+var partSuffix = regexp.MustCompile(`(?i)(?:\s*\(\d+\)|,?\s+(?:part|pt\.?)\s*(?:\d+|[ivx]+|one|two|three))$`)
+
+// JoinEpisodeTitles combines the titles of a multi-episode file into a single
+// episode title according to style. An empty style selects "collapse".
+//
+// Example:
+//
+//	metadata.JoinEpisodeTitles([]string{"Finale (1)", "Finale (2)"}, "collapse") // "Finale"
+//	metadata.JoinEpisodeTitles([]string{"Pilot", "Fire"}, "join")                // "Pilot & Fire"
+//	metadata.JoinEpisodeTitles([]string{"Pilot", "Fire"}, "first")               // "Pilot"
+func JoinEpisodeTitles(titles []string, style string) string {
+	nonEmpty := make([]string, 0, len(titles))
+	for _, title := range titles {
+		if title != "" {
+			nonEmpty = append(nonEmpty, title)
+		}
+	}
+	switch len(nonEmpty) {
+	case 0:
+		return ""
+	case 1:
+		return nonEmpty[0]
+	}
+
+	switch style {
+	case EpisodeTitleFirst:
+		return nonEmpty[0]
+	case EpisodeTitleJoin:
+		return strings.Join(nonEmpty, episodeTitleSeparator)
+	}
+
+	// Collapse titles that only differ in their part number
+	base := partSuffix.ReplaceAllString(nonEmpty[0], "")
+	for _, title := range nonEmpty[1:] {
+		if partSuffix.ReplaceAllString(title, "") != base {
+			return strings.Join(nonEmpty, episodeTitleSeparator)
+		}
+	}
+	return base
+}
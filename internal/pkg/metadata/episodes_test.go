@@ -0,0 +1,291 @@
+package metadata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestExtractTVShowInfoMultiEpisode(t *testing.T) {
+	tests := []struct {
+		name             string
+		filename         string
+		wantEpisode      int
+		wantEpisodes     []int
+		wantEpisodeTitle string
+	}{
+		{name: "Chained episodes", filename: "Show.S01E01E02.mkv", wantEpisode: 1, wantEpisodes: []int{1, 2}},
+		{name: "Range with E prefix", filename: "Show.S01E01-E03.mkv", wantEpisode: 1, wantEpisodes: []int{1, 2, 3}},
+		{name: "Range without E prefix", filename: "Show.S01E01-03.mkv", wantEpisode: 1, wantEpisodes: []int{1, 2, 3}},
+		{name: "Dotted chain", filename: "Show.S02E09.E10.720p.mkv", wantEpisode: 9, wantEpisodes: []int{9, 10}},
+		{name: "With episode title", filename: "Show S01E01E02 Pilot.mkv", wantEpisode: 1, wantEpisodes: []int{1, 2}, wantEpisodeTitle: "Pilot"},
+		{name: "Single episode", filename: "Show.S01E05.mkv", wantEpisode: 5},
+		{name: "Quality tag is no range", filename: "Show.S01E05-1080p.mkv", wantEpisode: 5},
+		{name: "Descending numbers are no range", filename: "Show.S01E05-02.mkv", wantEpisode: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractTVShowInfo(tt.filename)
+			if got.Title != "Show" {
+				t.Errorf("ExtractTVShowInfo() title = %q, want %q", got.Title, "Show")
+			}
+			if got.Episode != tt.wantEpisode {
+				t.Errorf("ExtractTVShowInfo() episode = %d, want %d", got.Episode, tt.wantEpisode)
+			}
+			if !reflect.DeepEqual(got.Episodes, tt.wantEpisodes) {
+				t.Errorf("ExtractTVShowInfo() episodes = %v, want %v", got.Episodes, tt.wantEpisodes)
+			}
+			if got.EpisodeTitle != tt.wantEpisodeTitle {
+				t.Errorf("ExtractTVShowInfo() episodeTitle = %q, want %q", got.EpisodeTitle, tt.wantEpisodeTitle)
+			}
+		})
+	}
+}
+
+func TestFormatEpisodeRange(t *testing.T) {
+	tests := []struct {
+		season   int
+		episodes []int
+		want     string
+	}{
+		{season: 1, episodes: []int{5}, want: "S01E05"},
+		{season: 1, episodes: []int{1, 2}, want: "S01E01-E02"},
+		{season: 2, episodes: []int{1, 2, 3}, want: "S02E01-E03"},
+		{season: 1, episodes: []int{1, 3}, want: "S01E01E03"},
+		{season: 10, episodes: []int{100, 101}, want: "S10E100-E101"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatEpisodeRange(tt.season, tt.episodes); got != tt.want {
+			t.Errorf("FormatEpisodeRange(%d, %v) = %q, want %q", tt.season, tt.episodes, got, tt.want)
+		}
+	}
+}
+
+func TestJoinEpisodeTitles(t *testing.T) {
+	tests := []struct {
+		name   string
+		titles []string
+		style  string
+		want   string
+	}{
+		{name: "Collapse numbered parts", titles: []string{"Finale (1)", "Finale (2)"}, style: EpisodeTitleCollapse, want: "Finale"},
+		{name: "Collapse named parts", titles: []string{"The Beginning, Part 1", "The Beginning, Part 2"}, style: EpisodeTitleCollapse, want: "The Beginning"},
+		{name: "Collapse joins different titles", titles: []string{"Pilot", "Cat's in the Bag"}, style: EpisodeTitleCollapse, want: "Pilot & Cat's in the Bag"},
+		{name: "Default style collapses", titles: []string{"Finale (1)", "Finale (2)"}, want: "Finale"},
+		{name: "Join", titles: []string{"Finale (1)", "Finale (2)"}, style: EpisodeTitleJoin, want: "Finale (1) & Finale (2)"},
+		{name: "First", titles: []string{"Pilot", "Cat's in the Bag"}, style: EpisodeTitleFirst, want: "Pilot"},
+		{name: "Missing titles are skipped", titles: []string{"", "Pilot (2)"}, style: EpisodeTitleCollapse, want: "Pilot (2)"},
+		{name: "No titles", titles: nil, style: EpisodeTitleJoin, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinEpisodeTitles(tt.titles, tt.style); got != tt.want {
+				t.Errorf("JoinEpisodeTitles(%q, %q) = %q, want %q", tt.titles, tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTvMazeProvider_SearchTVShowMultiEpisode(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/search/shows":
+			w.Write([]byte(`[{"score": 0.9, "show": {"id": 1, "name": "Show", "premiered": "2010-01-01"}}]`))
+		case r.URL.Path == "/shows/1":
+			w.Write([]byte(`{"id": 1, "name": "Show", "premiered": "2010-01-01"}`))
+		case r.URL.Path == "/shows/1/episodebynumber" && r.URL.Query().Get("number") == "1":
+			w.Write([]byte(`{"name": "Finale (1)", "season": 3, "number": 1, "airdate": "2012-05-01"}`))
+		case r.URL.Path == "/shows/1/episodebynumber" && r.URL.Query().Get("number") == "2":
+			w.Write([]byte(`{"name": "Finale (2)", "season": 3, "number": 2, "airdate": "2012-05-01"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	provider := &TvMazeProvider{
+		baseURL: mockServer.URL,
+		client:  mockServer.Client(),
+	}
+
+	got, err := provider.SearchTVShow(TVShowSearch{Title: "Show", Season: 3, Episode: 1, Episodes: []int{1, 2}}, "en")
+	if err != nil {
+		t.Fatalf("SearchTVShow() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Episodes, []int{1, 2}) {
+		t.Errorf("SearchTVShow() episodes = %v, want [1 2]", got.Episodes)
+	}
+	if !reflect.DeepEqual(got.EpisodeTitles, []string{"Finale (1)", "Finale (2)"}) {
+		t.Errorf("SearchTVShow() episodeTitles = %q, want both episode titles", got.EpisodeTitles)
+	}
+	if got.EpisodeTitle != "Finale" {
+		t.Errorf("SearchTVShow() episodeTitle = %q, want %q", got.EpisodeTitle, "Finale")
+	}
+	if got.Season != 3 || got.Episode != 1 || got.AirDate != "2012-05-01" {
+		t.Errorf("SearchTVShow() = S%dE%d aired %s, want first episode details", got.Season, got.Episode, got.AirDate)
+	}
+}
+
+func TestExtractTVShowInfoDateAndAbsolute(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     TVShowSearch
+	}{
+		{
+			name:     "Daily show with dots",
+			filename: "The.Daily.Show.2024.03.14.mkv",
+			want:     TVShowSearch{Title: "The Daily Show", AirDate: "2024-03-14"},
+		},
+		{
+			name:     "Daily show with guest and quality",
+			filename: "The Tonight Show 2023-11-02 Guest Name 720p.mkv",
+			want:     TVShowSearch{Title: "The Tonight Show", AirDate: "2023-11-02", EpisodeTitle: "Guest Name"},
+		},
+		{
+			name:     "Invalid date is no daily show",
+			filename: "Show.2024.13.45.mkv",
+			want:     TVShowSearch{Title: "Show 2024 13 45"},
+		},
+		{
+			name:     "Absolute number with group and tags",
+			filename: "[Group] One Piece - 143 [1080p].mkv",
+			want:     TVShowSearch{Title: "One Piece", AbsoluteEpisode: 143},
+		},
+		{
+			name:     "Absolute number with version and title",
+			filename: "Naruto - 007v2 - The Assassin of the Mist [720p].mkv",
+			want:     TVShowSearch{Title: "Naruto", AbsoluteEpisode: 7, EpisodeTitle: "The Assassin of the Mist"},
+		},
+		{
+			name:     "Year after dash is no episode number",
+			filename: "Blade Runner - 2049.mkv",
+			want:     TVShowSearch{Title: "Blade Runner - 2049"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Confidence is covered by TestConfidence
+			got := ExtractTVShowInfo(tt.filename)
+			got.Confidence = 0
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractTVShowInfo() = %+v, want %+v", got, tt.want)
+			}
+			if got.IsEpisode() != (tt.want.AirDate != "" || tt.want.AbsoluteEpisode > 0) {
+				t.Errorf("IsEpisode() = %v for %+v", got.IsEpisode(), got)
+			}
+		})
+	}
+
+	if movie := ExtractMovieInfo("The.Daily.Show.2024.03.14.mkv"); movie.Title != "" {
+		t.Errorf("ExtractMovieInfo() title = %q, want daily show to be skipped", movie.Title)
+	}
+}
+
+func TestTvMazeProvider_SearchTVShowByDateAndAbsolute(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/search/shows":
+			w.Write([]byte(`[{"score": 0.9, "show": {"id": 1, "name": "Show"}}]`))
+		case r.URL.Path == "/shows/1":
+			w.Write([]byte(`{"id": 1, "name": "Show"}`))
+		case r.URL.Path == "/shows/1/episodesbydate" && r.URL.Query().Get("date") == "2024-03-14":
+			w.Write([]byte(`[{"name": "Guest Night", "season": 29, "number": 55, "airdate": "2024-03-14"}]`))
+		case r.URL.Path == "/shows/1/episodes":
+			w.Write([]byte(`[
+				{"name": "One", "season": 1, "number": 1},
+				{"name": "Two", "season": 1, "number": 2},
+				{"name": "Three", "season": 2, "number": 1, "airdate": "2001-01-01"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	provider := &TvMazeProvider{
+		baseURL: mockServer.URL,
+		client:  mockServer.Client(),
+	}
+
+	tests := []struct {
+		name   string
+		search TVShowSearch
+		want   TVShowMetadata
+	}{
+		{
+			name:   "By air date",
+			search: TVShowSearch{Title: "Show", AirDate: "2024-03-14"},
+			want:   TVShowMetadata{Season: 29, Episode: 55, EpisodeTitle: "Guest Night", AirDate: "2024-03-14"},
+		},
+		{
+			name:   "Unknown air date",
+			search: TVShowSearch{Title: "Show", AirDate: "2024-03-15"},
+			want:   TVShowMetadata{AirDate: "2024-03-15"},
+		},
+		{
+			name:   "By absolute number",
+			search: TVShowSearch{Title: "Show", AbsoluteEpisode: 3},
+			want:   TVShowMetadata{Season: 2, Episode: 1, EpisodeTitle: "Three", AirDate: "2001-01-01", AbsoluteEpisode: 3},
+		},
+		{
+			name:   "Absolute number out of range",
+			search: TVShowSearch{Title: "Show", AbsoluteEpisode: 4},
+			want:   TVShowMetadata{AbsoluteEpisode: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.SearchTVShow(tt.search, "en")
+			if err != nil {
+				t.Fatalf("SearchTVShow() error = %v", err)
+			}
+			if got.Season != tt.want.Season || got.Episode != tt.want.Episode || got.EpisodeTitle != tt.want.EpisodeTitle ||
+				got.AirDate != tt.want.AirDate || got.AbsoluteEpisode != tt.want.AbsoluteEpisode {
+				t.Errorf("SearchTVShow() = S%dE%d %q aired %q absolute %d, want S%dE%d %q aired %q absolute %d",
+					got.Season, got.Episode, got.EpisodeTitle, got.AirDate, got.AbsoluteEpisode,
+					tt.want.Season, tt.want.Episode, tt.want.EpisodeTitle, tt.want.AirDate, tt.want.AbsoluteEpisode)
+			}
+		})
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	tests := []struct {
+		name      string
+		filename  string
+		wantTV    bool
+		wantTitle string // Movie title, empty when the name is an episode
+	}{
+		{name: "Episode", filename: "Show.Name.1x02.720p.mkv", wantTV: true},
+		{name: "Resolution pair", filename: "Some.Movie.2020.1920x1080.WEB-DL.mkv", wantTitle: "Some Movie"},
+		{name: "Resolution without year", filename: "Screen Recording 1280x720.mp4", wantTitle: "Screen Recording"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tv := ExtractTVShowInfo(tt.filename)
+			movie := ExtractMovieInfo(tt.filename)
+			if tv.IsEpisode() != tt.wantTV {
+				t.Errorf("IsEpisode() = %v, want %v", tv.IsEpisode(), tt.wantTV)
+			}
+			if movie.Title != tt.wantTitle {
+				t.Errorf("ExtractMovieInfo() title = %q, want %q", movie.Title, tt.wantTitle)
+			}
+			if tt.wantTV && tv.Confidence <= movie.Confidence {
+				t.Errorf("episode confidence %v, want above movie confidence %v", tv.Confidence, movie.Confidence)
+			}
+			if !tt.wantTV && movie.Confidence <= tv.Confidence {
+				t.Errorf("movie confidence %v, want above episode confidence %v", movie.Confidence, tv.Confidence)
+			}
+		})
+	}
+}
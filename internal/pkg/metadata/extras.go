@@ -0,0 +1,28 @@
+package metadata
+
+import "github.com/tekenstam/vidkit/internal/pkg/release"
+
+// ExtraSearch describes an extra such as a trailer or featurette, together
+// with the movie or show it belongs to.
+type ExtraSearch struct {
+	Kind  string // Extras folder of the kind: "Trailers", "Featurettes"...
+	Title string // Title of the movie or show the extra belongs to
+	Year  int
+	TV    bool // The extra belongs to a TV show or season
+}
+
+// ExtractExtraInfo identifies extras by their keywords ("Movie.2010.Featurette",
+// "Movie (2010)-trailer") or by the extras folder they are kept in. It returns
+// an empty Kind for regular videos.
+func ExtractExtraInfo(filename string, rules ...release.Rule) ExtraSearch {
+	parsed, _ := release.ParsePathWithRules(filename, release.ScopeMovie, rules)
+	if parsed.Extra == "" || parsed.IsEpisode() {
+		return ExtraSearch{}
+	}
+	return ExtraSearch{
+		Kind:  parsed.Extra,
+		Title: parsed.Title,
+		Year:  parsed.Year,
+		TV:    parsed.HasSeason(),
+	}
+}
@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractExtraInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     ExtraSearch
+	}{
+		{
+			name:     "Keyword after year",
+			filename: "Inception.2010.Featurette.Dreams.1080p.mkv",
+			want:     ExtraSearch{Kind: "Featurettes", Title: "Inception", Year: 2010},
+		},
+		{
+			name:     "Plex suffix",
+			filename: "Inception (2010)-trailer.mp4",
+			want:     ExtraSearch{Kind: "Trailers", Title: "Inception", Year: 2010},
+		},
+		{
+			name:     "Behind the scenes without year",
+			filename: "Inception Behind the Scenes.mkv",
+			want:     ExtraSearch{Kind: "Behind The Scenes", Title: "Inception"},
+		},
+		{
+			name:     "Extras folder",
+			filename: "/movies/Inception (2010)/Interviews/Christopher Nolan.mkv",
+			want:     ExtraSearch{Kind: "Interviews", Title: "Inception", Year: 2010},
+		},
+		{
+			name:     "Extras of a show",
+			filename: "/tv/Show Name/Season 01/Deleted Scenes/Cut Ending.mkv",
+			want:     ExtraSearch{Kind: "Deleted Scenes", Title: "Show Name", TV: true},
+		},
+		{
+			name:     "Title containing a keyword",
+			filename: "The.Interview.2014.1080p.BluRay.mkv",
+			want:     ExtraSearch{},
+		},
+		{
+			name:     "Title of a keyword after an article",
+			filename: "The.Interview.mkv",
+			want:     ExtraSearch{},
+		},
+		{
+			name:     "Title of a keyword after an article with release tags",
+			filename: "The.Interview.1080p.BluRay.mkv",
+			want:     ExtraSearch{},
+		},
+		{
+			name:     "Title of a keyword in a movie folder",
+			filename: "/movies/The Interview (2014)/The Interview.mkv",
+			want:     ExtraSearch{},
+		},
+		{
+			name:     "Plex suffix after a title containing a keyword",
+			filename: "/movies/The Interview (2014)/The Interview-trailer.mkv",
+			want:     ExtraSearch{Kind: "Trailers", Title: "The Interview", Year: 2014},
+		},
+		{
+			name:     "Keyword after an article in an extras folder",
+			filename: "/movies/The Interview (2014)/Trailers/The Trailer.mkv",
+			want:     ExtraSearch{Kind: "Trailers", Title: "The Interview", Year: 2014},
+		},
+		{
+			name:     "Episode",
+			filename: "Show.S01E05.Trailer.Park.mkv",
+			want:     ExtraSearch{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractExtraInfo(filepath.FromSlash(tt.filename)); got != tt.want {
+				t.Errorf("ExtractExtraInfo(%q) = %+v, want %+v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMovieInfoSkipsExtras(t *testing.T) {
+	if got := ExtractMovieInfo("Inception (2010)-trailer.mp4"); got.Title != "" {
+		t.Errorf("ExtractMovieInfo() title = %q, want no movie for an extra", got.Title)
+	}
+}
@@ -8,13 +8,16 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
 )
 
-// OMDbProvider implements movie metadata lookup using the Open Movie Database API
+// OMDbProvider implements movie and TV show metadata lookup using the Open Movie Database API
 type OMDbProvider struct {
-	apiKey  string
-	baseURL string
-	client  *http.Client
+	apiKey   string
+	baseURL  string
+	client   *http.Client
+	searches searchCache // Ranked candidates of earlier searches
 }
 
 // OMDbResponse represents the response from the OMDb API
@@ -46,8 +49,15 @@ type OMDbResponse struct {
 	BoxOffice  string `json:"BoxOffice"`
 	Production string `json:"Production"`
 	Website    string `json:"Website"`
-	Response   string `json:"Response"`
-	Error      string `json:"Error,omitempty"`
+
+	// Series and episode details
+	TotalSeasons string `json:"totalSeasons"`
+	Season       string `json:"Season"`
+	Episode      string `json:"Episode"`
+	SeriesID     string `json:"seriesID"`
+
+	Response string `json:"Response"`
+	Error    string `json:"Error,omitempty"`
 }
 
 // OMDbSearchResponse represents the search response from the OMDb API
@@ -58,6 +68,24 @@ type OMDbSearchResponse struct {
 	Error        string             `json:"Error,omitempty"`
 }
 
+// OMDbSeasonResponse represents the episode list of a season from the OMDb API
+type OMDbSeasonResponse struct {
+	Title        string              `json:"Title"`
+	Season       string              `json:"Season"`
+	TotalSeasons string              `json:"totalSeasons"`
+	Episodes     []OMDbSeasonEpisode `json:"Episodes"`
+	Response     string              `json:"Response"`
+	Error        string              `json:"Error,omitempty"`
+}
+
+// OMDbSeasonEpisode represents an episode in the episode list of a season
+type OMDbSeasonEpisode struct {
+	Title    string `json:"Title"`
+	Released string `json:"Released"`
+	Episode  string `json:"Episode"`
+	ImdbID   string `json:"imdbID"`
+}
+
 // OMDbSearchResult represents a search result from the OMDb API
 type OMDbSearchResult struct {
 	Title  string `json:"Title"`
@@ -85,7 +113,129 @@ var _ MetadataProvider = (*OMDbProvider)(nil)
 
 // SearchMovie searches for a movie using OMDb
 func (p *OMDbProvider) SearchMovie(search MovieSearch, language string) (*MovieMetadata, error) {
-	// First, search by title to get the IMDb ID
+	// Picked titles and IDs from the embedded tags need no search
+	imdbID := search.IMDbID
+	if search.Provider == string(config.ProviderOMDb) {
+		imdbID = search.ProviderID
+	}
+	if imdbID == "" {
+		candidates, err := p.MovieCandidates(search, language, 1)
+		if err != nil {
+			return nil, err
+		}
+		imdbID = candidates[0].ID
+	}
+
+	// Now get the detailed information using the IMDb ID
+	detailURL, _ := url.Parse(p.baseURL)
+	detailQuery := detailURL.Query()
+	detailQuery.Set("apikey", p.apiKey)
+	detailQuery.Set("i", imdbID)
+	detailQuery.Set("plot", "full")
+	detailURL.RawQuery = detailQuery.Encode()
+
+	// Execute the detail request
+	detailResp, err := p.client.Get(detailURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie details: %v", err)
+	}
+	defer detailResp.Body.Close()
+
+	// Parse the detail response
+	var movie OMDbResponse
+	if err := json.NewDecoder(detailResp.Body).Decode(&movie); err != nil {
+		return nil, fmt.Errorf("failed to decode movie details: %v", err)
+	}
+
+	// Check if the response was successful
+	if movie.Response != "True" {
+		return nil, fmt.Errorf("failed to get movie details: %s", movie.Error)
+	}
+
+	return &MovieMetadata{
+		Title:    movie.Title,
+		Year:     parseOMDbYear(movie.Year),
+		Overview: movie.Plot,
+		Edition:  search.Edition,
+		Part:     search.Part,
+		Provider: string(config.ProviderOMDb),
+		ID:       imdbID,
+	}, nil
+}
+
+// parseOMDbYear returns the first year of an OMDb year field, or 0 when it
+// has none.
+func parseOMDbYear(value string) int {
+	// OMDb returns year ranges like "2008–2013" for series, we only want the first year
+	yearPart := strings.Split(value, "–")[0]   // Note: this is an en dash, not a hyphen
+	yearPart = strings.Split(yearPart, "-")[0] // Also handle regular hyphens
+	year, _ := strconv.Atoi(yearPart)
+	return year
+}
+
+// parseOMDbRuntime returns the minutes of an OMDb runtime field ("136 min"),
+// or 0 when it has none.
+func parseOMDbRuntime(value string) int {
+	minutes, _ := strconv.Atoi(strings.TrimSuffix(value, " min"))
+	return minutes
+}
+
+// parseOMDbDate converts an OMDb release date, "20 Jan 2008" in details or
+// "2008-01-20" in episode lists, into YYYY-MM-DD. Unknown dates ("N/A")
+// return an empty string.
+func parseOMDbDate(value string) string {
+	for _, layout := range []string{"02 Jan 2006", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// omdbRuntimeLookups is the number of movie details looked up for their
+// runtime when ranking the results of a search. It is lower than for TMDb,
+// as OMDb keys have a small daily request limit.
+const omdbRuntimeLookups = 2
+
+// MovieCandidates searches OMDb for the title and ranks the results.
+func (p *OMDbProvider) MovieCandidates(search MovieSearch, language string, limit int) ([]Candidate, error) {
+	key := candidateSearch{kind: "movie", query: movieQuery(search), language: language}
+	return p.searches.ranked(key, limit, func() ([]Candidate, error) {
+		candidates, err := p.searchCandidates(search.Title, search.Year, "movie")
+		if err != nil {
+			return nil, err
+		}
+		// Search results lack the runtime, which the details have
+		return rankWithRuntimes(movieQuery(search), candidates, 0, omdbRuntimeLookups, func(c Candidate) int {
+			var movie OMDbResponse
+			if err := p.getJSON(url.Values{"i": {c.ID}}, &movie); err != nil {
+				return 0
+			}
+			return parseOMDbRuntime(movie.Runtime)
+		}), nil
+	})
+}
+
+// TVShowCandidates searches OMDb for the series and ranks the results.
+func (p *OMDbProvider) TVShowCandidates(search TVShowSearch, language string, limit int) ([]Candidate, error) {
+	key := candidateSearch{kind: "series", query: tvShowQuery(search), language: language}
+	return p.searches.ranked(key, limit, func() ([]Candidate, error) {
+		candidates, err := p.searchCandidates(search.Title, search.Year, "series")
+		if err != nil {
+			return nil, err
+		}
+		return RankCandidates(tvShowQuery(search), candidates, 0), nil
+	})
+}
+
+// searchCandidates searches OMDb for a title of the given type ("movie" or
+// "series") and returns the results as candidates identified by IMDb ID.
+func (p *OMDbProvider) searchCandidates(title string, year int, kind string) ([]Candidate, error) {
+	notFound := fmt.Errorf("no movies found matching '%s'", title)
+	if kind == "series" {
+		notFound = fmt.Errorf("no TV shows found matching '%s'", title)
+	}
+
 	searchURL, err := url.Parse(p.baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse OMDb URL: %v", err)
@@ -93,12 +243,12 @@ func (p *OMDbProvider) SearchMovie(search MovieSearch, language string) (*MovieM
 
 	query := searchURL.Query()
 	query.Set("apikey", p.apiKey)
-	query.Set("s", search.Title)
-	query.Set("type", "movie")
+	query.Set("s", title)
+	query.Set("type", kind)
 
 	// If we have a year, add it to improve search accuracy
-	if search.Year > 0 {
-		query.Set("y", strconv.Itoa(search.Year))
+	if year > 0 {
+		query.Set("y", strconv.Itoa(year))
 	}
 
 	searchURL.RawQuery = query.Encode()
@@ -106,7 +256,7 @@ func (p *OMDbProvider) SearchMovie(search MovieSearch, language string) (*MovieM
 	// Execute the search request
 	resp, err := p.client.Get(searchURL.String())
 	if err != nil {
-		return nil, fmt.Errorf("failed to search movie: %v", err)
+		return nil, fmt.Errorf("failed to search %s: %v", kind, err)
 	}
 	defer resp.Body.Close()
 
@@ -119,14 +269,14 @@ func (p *OMDbProvider) SearchMovie(search MovieSearch, language string) (*MovieM
 	// Check if the search was successful
 	if searchResp.Response != "True" {
 		// Try a more forgiving search without year if we had one
-		if search.Year > 0 {
+		if year > 0 {
 			// Remove year and try again
 			query.Del("y")
 			searchURL.RawQuery = query.Encode()
 
 			resp, err := p.client.Get(searchURL.String())
 			if err != nil {
-				return nil, fmt.Errorf("failed to search movie without year: %v", err)
+				return nil, fmt.Errorf("failed to search %s without year: %v", kind, err)
 			}
 			defer resp.Body.Close()
 
@@ -135,67 +285,155 @@ func (p *OMDbProvider) SearchMovie(search MovieSearch, language string) (*MovieM
 			}
 
 			if searchResp.Response != "True" {
-				return nil, fmt.Errorf("no movies found matching '%s'", search.Title)
+				return nil, notFound
 			}
 		} else {
-			return nil, fmt.Errorf("no movies found matching '%s'", search.Title)
+			return nil, notFound
 		}
 	}
 
 	if len(searchResp.Search) == 0 {
-		return nil, fmt.Errorf("no movies found matching '%s'", search.Title)
+		return nil, notFound
 	}
 
-	// Get the first result's IMDb ID
-	imdbID := searchResp.Search[0].ImdbID
-
-	// Now get the detailed information using the IMDb ID
-	detailURL, _ := url.Parse(p.baseURL)
-	detailQuery := detailURL.Query()
-	detailQuery.Set("apikey", p.apiKey)
-	detailQuery.Set("i", imdbID)
-	detailQuery.Set("plot", "full")
-	detailURL.RawQuery = detailQuery.Encode()
+	candidates := make([]Candidate, 0, len(searchResp.Search))
+	for _, result := range searchResp.Search {
+		candidates = append(candidates, Candidate{
+			ID:       result.ImdbID,
+			Provider: string(config.ProviderOMDb),
+			Title:    result.Title,
+			Year:     parseOMDbYear(result.Year),
+		})
+	}
+	return candidates, nil
+}
 
-	// Execute the detail request
-	detailResp, err := p.client.Get(detailURL.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get movie details: %v", err)
+// SearchTVShow searches for a TV series using OMDb. Episodes are looked up
+// by season and episode number; OMDb has no specials and only English titles.
+func (p *OMDbProvider) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
+	// Picked titles and IDs from the embedded tags need no search
+	imdbID := search.IMDbID
+	if search.Provider == string(config.ProviderOMDb) {
+		imdbID = search.ProviderID
+	}
+	if imdbID == "" {
+		candidates, err := p.TVShowCandidates(search, language, 1)
+		if err != nil {
+			return nil, err
+		}
+		imdbID = candidates[0].ID
 	}
-	defer detailResp.Body.Close()
 
-	// Parse the detail response
-	var movie OMDbResponse
-	if err := json.NewDecoder(detailResp.Body).Decode(&movie); err != nil {
-		return nil, fmt.Errorf("failed to decode movie details: %v", err)
+	var series OMDbResponse
+	if err := p.getJSON(url.Values{"i": {imdbID}, "plot": {"full"}}, &series); err != nil {
+		return nil, fmt.Errorf("failed to get series details: %v", err)
+	}
+	if series.Response != "True" {
+		return nil, fmt.Errorf("failed to get series details: %s", series.Error)
 	}
 
-	// Check if the response was successful
-	if movie.Response != "True" {
-		return nil, fmt.Errorf("failed to get movie details: %s", movie.Error)
+	// Basic metadata without episode info
+	metadata := &TVShowMetadata{
+		Title:    series.Title,
+		Year:     parseOMDbYear(series.Year),
+		Overview: series.Plot,
+		Provider: string(config.ProviderOMDb),
+		ID:       imdbID,
+	}
+	metadata.SeasonCount, _ = strconv.Atoi(series.TotalSeasons)
+	if series.Genre != "" && series.Genre != "N/A" {
+		metadata.Genres = strings.Split(series.Genre, ", ")
 	}
 
-	// Extract the year from the year field
-	year := 0
-	if movie.Year != "" {
-		// OMDb sometimes returns year ranges like "2008-2013", we only want the first year
-		yearPart := strings.Split(movie.Year, "–")[0] // Note: this is an en dash, not a hyphen
-		yearPart = strings.Split(yearPart, "-")[0]    // Also handle regular hyphens
-		if y, err := strconv.Atoi(yearPart); err == nil {
-			year = y
+	switch {
+	case search.Season > 0 && search.Episode > 0:
+		// If season and episode are provided, get episode details
+		var titles []string
+		for i, number := range search.EpisodeNumbers() {
+			var episode OMDbResponse
+			query := url.Values{"i": {imdbID}, "Season": {strconv.Itoa(search.Season)}, "Episode": {strconv.Itoa(number)}}
+			if err := p.getJSON(query, &episode); err != nil || episode.Response != "True" {
+				if i == 0 {
+					// Return show info without episode details
+					return metadata, nil
+				}
+				titles = append(titles, "")
+				continue
+			}
+
+			// The first episode provides the episode details
+			if i == 0 {
+				metadata.Season, metadata.Episode = search.Season, number
+				metadata.AirDate = parseOMDbDate(episode.Released)
+			}
+			titles = append(titles, episode.Title)
 		}
+
+		setEpisodeTitles(metadata, search, titles)
+
+	case search.AirDate != "":
+		// Daily shows are looked up by air date in the episode lists
+		metadata.AirDate = search.AirDate
+		p.findSeasonEpisode(imdbID, metadata.SeasonCount, metadata, func(_ int, episode OMDbSeasonEpisode) bool {
+			return parseOMDbDate(episode.Released) == search.AirDate
+		})
+
+	case search.AbsoluteEpisode > 0:
+		// Absolute numbers count the episodes of all seasons in order
+		metadata.AbsoluteEpisode = search.AbsoluteEpisode
+		p.findSeasonEpisode(imdbID, metadata.SeasonCount, metadata, func(index int, _ OMDbSeasonEpisode) bool {
+			return index == search.AbsoluteEpisode
+		})
 	}
 
-	return &MovieMetadata{
-		Title:    movie.Title,
-		Year:     year,
-		Overview: movie.Plot,
-	}, nil
+	return metadata, nil
 }
 
-// SearchTVShow attempts to search for a TV show using OMDb
-func (p *OMDbProvider) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
-	// OMDb is not ideal for TV show episode lookup
-	// For a more complete implementation, it would be better to use a dedicated TV API
-	return nil, fmt.Errorf("TV show search is not fully supported in OMDb. Use TVMaze or TVDb provider instead")
+// findSeasonEpisode walks the episode lists of the seasons of a series in
+// order and copies the details of the first episode accepted by match into
+// the metadata. Episodes are passed along with their index across all
+// seasons, starting at 1.
+func (p *OMDbProvider) findSeasonEpisode(imdbID string, seasons int, metadata *TVShowMetadata, match func(index int, episode OMDbSeasonEpisode) bool) {
+	index := 0
+	for season := 1; season <= seasons; season++ {
+		var list OMDbSeasonResponse
+		if err := p.getJSON(url.Values{"i": {imdbID}, "Season": {strconv.Itoa(season)}}, &list); err != nil || list.Response != "True" {
+			return
+		}
+		for _, episode := range list.Episodes {
+			index++
+			if match(index, episode) {
+				metadata.Season = season
+				metadata.Episode, _ = strconv.Atoi(episode.Episode)
+				metadata.AirDate = parseOMDbDate(episode.Released)
+				metadata.EpisodeTitle = episode.Title
+				return
+			}
+		}
+	}
+}
+
+// getJSON requests the OMDb API with the query and the API key, and decodes
+// the JSON response into v
+func (p *OMDbProvider) getJSON(query url.Values, v interface{}) error {
+	apiURL, err := url.Parse(p.baseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse OMDb URL: %v", err)
+	}
+	query.Set("apikey", p.apiKey)
+	apiURL.RawQuery = query.Encode()
+
+	resp, err := p.client.Get(apiURL.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	return nil
 }
@@ -3,6 +3,7 @@ package metadata
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -143,6 +144,17 @@ func TestOMDbProvider_SearchMovie(t *testing.T) {
 			wantYear:  1999,
 			wantErr:   false,
 		},
+		{
+			name: "IMDb ID from embedded tags",
+			search: MovieSearch{
+				Title:  "title_t00",
+				IMDbID: "tt0133093",
+			},
+			language:  "en",
+			wantTitle: "The Matrix",
+			wantYear:  1999,
+			wantErr:   false,
+		},
 		{
 			name: "Movie not found",
 			search: MovieSearch{
@@ -197,6 +209,171 @@ func TestOMDbProvider_SearchMovie(t *testing.T) {
 	}
 }
 
+func TestOMDbProvider_MovieCandidatesRuntime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case q.Get("s") != "":
+			w.Write([]byte(`{"Search": [
+				{"Title": "Invasion of the Body Snatchers", "Year": "1978", "imdbID": "tt0077745", "Type": "movie"},
+				{"Title": "Invasion of the Body Snatchers", "Year": "1956", "imdbID": "tt0049366", "Type": "movie"}
+			], "totalResults": "2", "Response": "True"}`))
+		case q.Get("i") == "tt0077745":
+			w.Write([]byte(`{"Title": "Invasion of the Body Snatchers", "Runtime": "115 min", "Response": "True"}`))
+		case q.Get("i") == "tt0049366":
+			w.Write([]byte(`{"Title": "Invasion of the Body Snatchers", "Runtime": "80 min", "Response": "True"}`))
+		default:
+			w.Write([]byte(`{"Response": "False", "Error": "Movie not found!"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := &OMDbProvider{apiKey: "test_api_key", baseURL: server.URL, client: server.Client()}
+	candidates, err := provider.MovieCandidates(MovieSearch{Title: "Invasion of the Body Snatchers", Duration: 80*60 + 25}, "en", 0)
+	if err != nil {
+		t.Fatalf("MovieCandidates() error = %v", err)
+	}
+	if candidates[0].ID != "tt0049366" || candidates[0].Runtime != 80 || candidates[1].Runtime != 115 {
+		t.Errorf("MovieCandidates() = %+v, want the 1956 movie of 80 minutes first", candidates)
+	}
+}
+
+func TestOMDbProvider_SearchTVShow(t *testing.T) {
+	// Breaking Bad with two seasons of two episodes
+	seasons := map[string]string{
+		"1": `{"Title": "Breaking Bad", "Season": "1", "totalSeasons": "2", "Response": "True", "Episodes": [
+			{"Title": "Pilot", "Released": "2008-01-20", "Episode": "1", "imdbID": "tt0959621"},
+			{"Title": "Cat's in the Bag...", "Released": "2008-01-27", "Episode": "2", "imdbID": "tt1054724"}]}`,
+		"2": `{"Title": "Breaking Bad", "Season": "2", "totalSeasons": "2", "Response": "True", "Episodes": [
+			{"Title": "Seven Thirty-Seven", "Released": "2009-03-08", "Episode": "1", "imdbID": "tt1232244"},
+			{"Title": "Grilled", "Released": "2009-03-15", "Episode": "2", "imdbID": "tt1232249"}]}`,
+	}
+	episodes := map[string]string{
+		"1x1": `{"Title": "Pilot", "Released": "20 Jan 2008", "Season": "1", "Episode": "1", "seriesID": "tt0903747", "Type": "episode", "Response": "True"}`,
+		"1x2": `{"Title": "Cat's in the Bag...", "Released": "27 Jan 2008", "Season": "1", "Episode": "2", "seriesID": "tt0903747", "Type": "episode", "Response": "True"}`,
+	}
+	notFound := `{"Response": "False", "Error": "Series or episode not found!"}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case q.Get("apikey") == "":
+			http.Error(w, "No API key provided", http.StatusUnauthorized)
+		case q.Get("s") == "Breaking Bad" && q.Get("type") == "series" && (q.Get("y") == "" || q.Get("y") == "2008"):
+			w.Write([]byte(`{"Search": [{"Title": "Breaking Bad", "Year": "2008–2013", "imdbID": "tt0903747", "Type": "series"}], "totalResults": "1", "Response": "True"}`))
+		case q.Get("s") != "":
+			w.Write([]byte(notFound))
+		case q.Get("i") != "tt0903747":
+			w.Write([]byte(notFound))
+		case q.Get("Episode") != "":
+			if response, ok := episodes[q.Get("Season")+"x"+q.Get("Episode")]; ok {
+				w.Write([]byte(response))
+				return
+			}
+			w.Write([]byte(notFound))
+		case q.Get("Season") != "":
+			if response, ok := seasons[q.Get("Season")]; ok {
+				w.Write([]byte(response))
+				return
+			}
+			w.Write([]byte(notFound))
+		default:
+			w.Write([]byte(`{"Title": "Breaking Bad", "Year": "2008–2013", "Genre": "Crime, Drama, Thriller",
+				"Plot": "A chemistry teacher diagnosed with cancer...", "totalSeasons": "2", "Type": "series", "Response": "True"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := &OMDbProvider{
+		apiKey:  "test_api_key",
+		baseURL: server.URL,
+		client:  server.Client(),
+	}
+
+	show := TVShowMetadata{
+		Title:       "Breaking Bad",
+		Year:        2008,
+		Overview:    "A chemistry teacher diagnosed with cancer...",
+		SeasonCount: 2,
+		Genres:      []string{"Crime", "Drama", "Thriller"},
+		Provider:    "omdb",
+		ID:          "tt0903747",
+	}
+	episode := func(season, number int, airDate, title string) TVShowMetadata {
+		m := show
+		m.Season, m.Episode, m.AirDate, m.EpisodeTitle = season, number, airDate, title
+		return m
+	}
+
+	tests := []struct {
+		name   string
+		search TVShowSearch
+		want   TVShowMetadata
+	}{
+		{
+			name:   "Season and episode",
+			search: TVShowSearch{Title: "Breaking Bad", Year: 2008, Season: 1, Episode: 2},
+			want:   episode(1, 2, "2008-01-27", "Cat's in the Bag..."),
+		},
+		{
+			name:   "Year that does not match falls back to the title",
+			search: TVShowSearch{Title: "Breaking Bad", Year: 2010, Season: 1, Episode: 1},
+			want:   episode(1, 1, "2008-01-20", "Pilot"),
+		},
+		{
+			name:   "Multi-episode file",
+			search: TVShowSearch{Title: "Breaking Bad", Season: 1, Episode: 1, Episodes: []int{1, 2}},
+			want: func() TVShowMetadata {
+				m := episode(1, 1, "2008-01-20", "Pilot & Cat's in the Bag...")
+				m.Episodes, m.EpisodeTitles = []int{1, 2}, []string{"Pilot", "Cat's in the Bag..."}
+				return m
+			}(),
+		},
+		{
+			name:   "Air date",
+			search: TVShowSearch{Title: "Breaking Bad", AirDate: "2009-03-08"},
+			want:   episode(2, 1, "2009-03-08", "Seven Thirty-Seven"),
+		},
+		{
+			name:   "Absolute episode",
+			search: TVShowSearch{Title: "Breaking Bad", AbsoluteEpisode: 4},
+			want: func() TVShowMetadata {
+				m := episode(2, 2, "2009-03-15", "Grilled")
+				m.AbsoluteEpisode = 4
+				return m
+			}(),
+		},
+		{
+			name:   "IMDb ID from embedded tags",
+			search: TVShowSearch{Title: "bb", IMDbID: "tt0903747", Season: 1, Episode: 1},
+			want:   episode(1, 1, "2008-01-20", "Pilot"),
+		},
+		{
+			name:   "Unknown episode",
+			search: TVShowSearch{Title: "Breaking Bad", Season: 5, Episode: 1},
+			want:   show,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.SearchTVShow(tt.search, "en")
+			if err != nil {
+				t.Fatalf("SearchTVShow() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("SearchTVShow() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	if _, err := provider.SearchTVShow(TVShowSearch{Title: "Unknown Show", Season: 1, Episode: 1}, "en"); err == nil {
+		t.Error("SearchTVShow() of an unknown show error = nil, want error")
+	}
+}
+
 func TestNewOMDbProvider(t *testing.T) {
 	tests := []struct {
 		name    string
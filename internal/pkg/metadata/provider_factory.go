@@ -6,28 +6,61 @@ import (
 	"github.com/tekenstam/vidkit/internal/pkg/config"
 )
 
-// CreateMovieProvider creates the appropriate movie metadata provider based on configuration
+// CreateMovieProvider creates the appropriate movie metadata provider based on configuration.
+// Several configured providers are combined into a ProviderChain.
 func CreateMovieProvider(cfg *config.Config) (MetadataProvider, error) {
-	switch cfg.MovieProvider {
+	return createChain(cfg.MovieProviderChain(), cfg, createMovieProvider)
+}
+
+// createMovieProvider creates a single movie metadata provider
+func createMovieProvider(provider config.ProviderType, cfg *config.Config) (MetadataProvider, error) {
+	switch provider {
 	case config.ProviderTMDb:
 		return NewTMDbProvider(cfg.TMDbAPIKey)
 	case config.ProviderOMDb:
 		return NewOMDbProvider(cfg.OMDbAPIKey)
 	default:
-		return nil, fmt.Errorf("unsupported movie provider: %s", cfg.MovieProvider)
+		return nil, fmt.Errorf("unsupported movie provider: %s", provider)
 	}
 }
 
-// CreateTVShowProvider creates the appropriate TV show metadata provider based on configuration
+// CreateTVShowProvider creates the appropriate TV show metadata provider based on configuration.
+// Several configured providers are combined into a ProviderChain.
 func CreateTVShowProvider(cfg *config.Config) (MetadataProvider, error) {
-	switch cfg.TVProvider {
+	return createChain(cfg.TVProviderChain(), cfg, createTVShowProvider)
+}
+
+// createTVShowProvider creates a single TV show metadata provider
+func createTVShowProvider(provider config.ProviderType, cfg *config.Config) (MetadataProvider, error) {
+	switch provider {
 	case config.ProviderTVMaze:
 		return NewTvMazeProvider(), nil
 	case config.ProviderTVDb:
 		return NewTVDbProvider(cfg.TVDbAPIKey)
+	case config.ProviderTMDb:
+		return NewTMDbProvider(cfg.TMDbAPIKey)
+	case config.ProviderOMDb:
+		return NewOMDbProvider(cfg.OMDbAPIKey)
 	default:
-		return nil, fmt.Errorf("unsupported TV show provider: %s", cfg.TVProvider)
+		return nil, fmt.Errorf("unsupported TV show provider: %s", provider)
+	}
+}
+
+// createChain creates the providers of a list. A single provider is returned
+// as it is, several are asked in order by a ProviderChain.
+func createChain(providers []config.ProviderType, cfg *config.Config, create func(config.ProviderType, *config.Config) (MetadataProvider, error)) (MetadataProvider, error) {
+	if len(providers) == 1 {
+		return create(providers[0], cfg)
+	}
+	chain := NewProviderChain()
+	for _, provider := range providers {
+		p, err := create(provider, cfg)
+		if err != nil {
+			return nil, err
+		}
+		chain.Add(string(provider), p)
 	}
+	return chain, nil
 }
 
 // GetProvider returns the appropriate provider for the type of content
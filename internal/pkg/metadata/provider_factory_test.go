@@ -119,6 +119,27 @@ func TestCreateTVShowProvider(t *testing.T) {
 			expectError:  true,
 			expectedType: "",
 		},
+		{
+			name:         "TMDb Provider",
+			providerType: config.ProviderTMDb,
+			apiKey:       "test_tmdb_key",
+			expectError:  false,
+			expectedType: "*metadata.TMDbProvider",
+		},
+		{
+			name:         "TMDb Provider with empty key",
+			providerType: config.ProviderTMDb,
+			apiKey:       "",
+			expectError:  true,
+			expectedType: "",
+		},
+		{
+			name:         "OMDb Provider",
+			providerType: config.ProviderOMDb,
+			apiKey:       "test_omdb_key",
+			expectError:  false,
+			expectedType: "*metadata.OMDbProvider",
+		},
 		{
 			name:         "Unknown Provider",
 			providerType: "unknown",
@@ -136,8 +157,13 @@ func TestCreateTVShowProvider(t *testing.T) {
 			}
 
 			// Set the appropriate API key based on provider type
-			if tt.providerType == config.ProviderTVDb {
+			switch tt.providerType {
+			case config.ProviderTVDb:
 				cfg.TVDbAPIKey = tt.apiKey
+			case config.ProviderTMDb:
+				cfg.TMDbAPIKey = tt.apiKey
+			case config.ProviderOMDb:
+				cfg.OMDbAPIKey = tt.apiKey
 			}
 
 			// Call the factory function
@@ -223,3 +249,37 @@ func TestGetProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateProviderChain(t *testing.T) {
+	cfg := &config.Config{
+		MovieProvider:  config.ProviderTMDb,
+		MovieProviders: []config.ProviderType{config.ProviderTMDb, config.ProviderOMDb},
+		TVProviders:    []config.ProviderType{config.ProviderTVMaze},
+		TMDbAPIKey:     "test_tmdb_key",
+		OMDbAPIKey:     "test_omdb_key",
+	}
+
+	movieProvider, err := CreateMovieProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateMovieProvider() error = %v", err)
+	}
+	chain, ok := movieProvider.(*ProviderChain)
+	if !ok || len(chain.providers) != 2 || chain.names[0] != "tmdb" || chain.names[1] != "omdb" {
+		t.Errorf("CreateMovieProvider() = %#v, want a chain of tmdb and omdb", movieProvider)
+	}
+
+	// A list of one provider needs no chain
+	tvProvider, err := CreateTVShowProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateTVShowProvider() error = %v", err)
+	}
+	if providerType := fmt.Sprintf("%T", tvProvider); providerType != "*metadata.TvMazeProvider" {
+		t.Errorf("CreateTVShowProvider() provider type = %v, want *metadata.TvMazeProvider", providerType)
+	}
+
+	// Providers without their API key fail the whole chain
+	cfg.OMDbAPIKey = ""
+	if _, err := CreateMovieProvider(cfg); err == nil {
+		t.Error("CreateMovieProvider() error = nil, want error for the missing OMDb key")
+	}
+}
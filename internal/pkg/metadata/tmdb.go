@@ -2,27 +2,45 @@ package metadata
 
 import (
 	"fmt"
-	"path/filepath"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	tmdb "github.com/cyruzin/golang-tmdb"
+	"github.com/tekenstam/vidkit/internal/pkg/config"
+	"github.com/tekenstam/vidkit/internal/pkg/release"
 )
 
 // MovieSearch represents a movie search request
 type MovieSearch struct {
-	Title string
-	Year  int
+	Title   string
+	Year    int
+	Edition string // Edition named in the filename (e.g., "Director's Cut")
+	Part    int    // Part of a movie split across files (CD1, Part 2); 0 for complete movies
+	IMDbID  string // IMDb ID from the embedded tags (e.g., "tt1375666")
+	TMDbID  int    // TMDb movie ID from the embedded tags
+
+	// Title picked by the user: the named provider looks it up by its own ID,
+	// other providers ignore it
+	Provider   string
+	ProviderID string
+
+	Duration   int     // Duration of the video in seconds, compared with runtimes; 0 when unknown
+	Confidence float64 // How likely the file is this movie, from 0 to 1
 }
 
 // MovieMetadata represents movie metadata from TMDb
 type MovieMetadata struct {
-	Title    string
-	Year     int
-	Overview string
-	Genres   []string
+	Title     string
+	AltTitles []string // Original and alternative titles
+	Year      int
+	Overview  string
+	Genres    []string
+	Edition   string // Edition of the file, passed through from the search
+	Part      int    // Part of the file, passed through from the search
+
+	Provider   string  // Provider that answered (e.g., "tmdb")
+	ID         string  // ID of the movie at the provider
+	Confidence float64 // How well the result matches the search, from 0 to 1; set by provider chains
 }
 
 // TVShowSearch represents a TV show search request
@@ -30,29 +48,57 @@ type TVShowSearch struct {
 	Title        string
 	Year         int
 	Season       int
+	Special      bool // Episode of the specials, season 0 (S00E05)
 	Episode      int
+	Episodes     []int // All episodes of a multi-episode file (including Episode); nil for single episodes
 	EpisodeTitle string
+
+	AirDate         string // Air date of a daily show episode (YYYY-MM-DD)
+	AbsoluteEpisode int    // Absolute episode number, counted across all seasons
+	IMDbID          string // IMDb ID of the show from the embedded tags
+	TMDbID          int    // TMDb ID of the show from the embedded tags
+
+	// Show picked by the user: the named provider looks it up by its own ID,
+	// other providers ignore it
+	Provider   string
+	ProviderID string
+
+	Duration   int     // Duration of the video in seconds, compared with runtimes; 0 when unknown
+	Confidence float64 // How likely the file is this episode, from 0 to 1
 }
 
 // TVShowMetadata represents TV show metadata from TMDb
 type TVShowMetadata struct {
-	Title        string
-	Year         int
-	Overview     string
-	Season       int
-	Episode      int
-	EpisodeTitle string
-	SeasonCount  int
-	Network      string
-	AirDate      string
-	Status       string
-	Genres       []string
+	Title           string
+	AltTitles       []string // Original and alternative titles
+	Year            int
+	Overview        string
+	Season          int
+	Episode         int
+	Episodes        []int // All episodes of a multi-episode file (including Episode); nil for single episodes
+	EpisodeTitle    string
+	EpisodeTitles   []string // Titles of every episode of a multi-episode file; EpisodeTitle holds them combined
+	AbsoluteEpisode int      // Absolute episode number, counted across all seasons
+	SeasonCount     int
+	Network         string
+	AirDate         string
+	Status          string
+	Genres          []string
+
+	Provider   string  // Provider that answered (e.g., "tvmaze")
+	ID         string  // ID of the show at the provider
+	Confidence float64 // How well the result matches the search, from 0 to 1; set by provider chains
 }
 
 // MetadataProvider defines the interface for metadata providers
 type MetadataProvider interface {
 	SearchMovie(search MovieSearch, language string) (*MovieMetadata, error)
 	SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error)
+
+	// MovieCandidates and TVShowCandidates return the best matches of a
+	// title search, ranked by RankCandidates, at most limit of them
+	MovieCandidates(search MovieSearch, language string, limit int) ([]Candidate, error)
+	TVShowCandidates(search TVShowSearch, language string, limit int) ([]Candidate, error)
 }
 
 // TMDbClient defines the interface for TMDb operations
@@ -60,11 +106,16 @@ type MetadataProvider interface {
 type TMDbClient interface {
 	GetSearchMovies(query string, urlOptions map[string]string) (*tmdb.SearchMovies, error)
 	GetMovieDetails(id int, urlOptions map[string]string) (*tmdb.MovieDetails, error)
+	GetFindByID(id string, urlOptions map[string]string) (*tmdb.FindByID, error)
+	GetSearchTVShow(query string, urlOptions map[string]string) (*tmdb.SearchTVShows, error)
+	GetTVDetails(id int, urlOptions map[string]string) (*tmdb.TVDetails, error)
+	GetTVSeasonDetails(id int, seasonNumber int, urlOptions map[string]string) (*tmdb.TVSeasonDetails, error)
 }
 
-// TMDbProvider implements movie metadata lookup using TMDb
+// TMDbProvider implements movie and TV show metadata lookup using TMDb
 type TMDbProvider struct {
-	client TMDbClient
+	client   TMDbClient
+	searches searchCache // Ranked candidates of earlier searches
 }
 
 // Ensure TMDbProvider implements MetadataProvider
@@ -87,33 +138,16 @@ func (p *TMDbProvider) SearchMovie(search MovieSearch, language string) (*MovieM
 		"language": language,
 	}
 
-	// If we have a year, add it to improve search accuracy
-	if search.Year > 0 {
-		options["year"] = strconv.Itoa(search.Year)
-	}
-
-	searchResults, err := p.client.GetSearchMovies(search.Title, options)
+	movieID, err := p.findMovieID(search, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search movie: %v", err)
+		return nil, err
 	}
 
-	if len(searchResults.Results) == 0 {
-		// If no results with year, try without year
-		if search.Year > 0 {
-			delete(options, "year")
-			searchResults, err = p.client.GetSearchMovies(search.Title, options)
-			if err != nil {
-				return nil, fmt.Errorf("failed to search movie: %v", err)
-			}
-		}
-		if len(searchResults.Results) == 0 {
-			return nil, fmt.Errorf("no movies found matching '%s'", search.Title)
-		}
-	}
-
-	// Get the first result's details
-	movieID := int(searchResults.Results[0].ID)
-	movie, err := p.client.GetMovieDetails(movieID, options)
+	// Get the movie's details
+	movie, err := p.client.GetMovieDetails(movieID, map[string]string{
+		"language":           language,
+		"append_to_response": "alternative_titles",
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get movie details: %v", err)
 	}
@@ -131,204 +165,413 @@ func (p *TMDbProvider) SearchMovie(search MovieSearch, language string) (*MovieM
 		genreNames = append(genreNames, genre.Name)
 	}
 
+	var altTitles []string
+	if movie.OriginalTitle != "" && movie.OriginalTitle != movie.Title {
+		altTitles = append(altTitles, movie.OriginalTitle)
+	}
+	if movie.MovieAlternativeTitlesAppend != nil && movie.AlternativeTitles != nil {
+		for _, alt := range movie.AlternativeTitles.Titles {
+			altTitles = append(altTitles, alt.Title)
+		}
+	}
+
 	return &MovieMetadata{
-		Title:    movie.Title,
-		Year:     year,
-		Overview: movie.Overview,
-		Genres:   genreNames,
+		Title:     movie.Title,
+		AltTitles: altTitles,
+		Year:      year,
+		Overview:  movie.Overview,
+		Genres:    genreNames,
+		Edition:   search.Edition,
+		Part:      search.Part,
+		Provider:  string(config.ProviderTMDb),
+		ID:        strconv.Itoa(movieID),
 	}, nil
 }
 
-// SearchTVShow attempts to search for a TV show using TMDb (not fully implemented)
-func (p *TMDbProvider) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
-	// This is a stub implementation since TMDb's API for TV shows works differently
-	// In a real implementation, we would use GetSearchTV and GetTVDetails
-	return nil, fmt.Errorf("TMDb TV show search not fully implemented. Use TvMaze provider instead")
+// findMovieID returns the TMDb ID of the movie. Picked movies and IDs from
+// the embedded tags are used directly; otherwise the best ranked search
+// result for the title is taken.
+func (p *TMDbProvider) findMovieID(search MovieSearch, options map[string]string) (int, error) {
+	if search.Provider == string(config.ProviderTMDb) {
+		return strconv.Atoi(search.ProviderID)
+	}
+	if search.TMDbID > 0 {
+		return search.TMDbID, nil
+	}
+	if search.IMDbID != "" {
+		found, err := p.client.GetFindByID(search.IMDbID, map[string]string{
+			"external_source": "imdb_id",
+			"language":        options["language"],
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to find movie %s: %v", search.IMDbID, err)
+		}
+		if len(found.MovieResults) > 0 {
+			return int(found.MovieResults[0].ID), nil
+		}
+		// Unknown IDs fall back to searching by title
+	}
+
+	candidates, err := p.MovieCandidates(search, options["language"], 1)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(candidates[0].ID)
+}
+
+// MovieCandidates searches TMDb for the title and ranks the results.
+func (p *TMDbProvider) MovieCandidates(search MovieSearch, language string, limit int) ([]Candidate, error) {
+	key := candidateSearch{kind: "movie", query: movieQuery(search), language: language}
+	return p.searches.ranked(key, limit, func() ([]Candidate, error) {
+		return p.searchMovies(search, language)
+	})
 }
 
-// ExtractMovieInfo extracts movie information from a filename
-func ExtractMovieInfo(filename string) MovieSearch {
-	// Extract base name without extension
-	basename := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+// tmdbRuntimeLookups is the number of movie details looked up for their
+// runtime when ranking the results of a search.
+const tmdbRuntimeLookups = 5
 
-	// Check if it's a TV show pattern first
-	tvInfo := ExtractTVShowInfo(filename)
-	if tvInfo.Season > 0 && tvInfo.Episode > 0 {
-		// This is a TV show filename, not a movie
-		return MovieSearch{Title: "", Year: 0}
+// searchMovies searches TMDb for the title and ranks all results.
+func (p *TMDbProvider) searchMovies(search MovieSearch, language string) ([]Candidate, error) {
+	// If we have a year, add it to improve search accuracy
+	searchOptions := map[string]string{"language": language}
+	if search.Year > 0 {
+		searchOptions["year"] = strconv.Itoa(search.Year)
 	}
 
-	// Look for year pattern (YYYY) in filename, but only in delimiters
-	// Only accept years in parentheses or square brackets
-	yearPattern := regexp.MustCompile(`\((\d{4})\)|\[(\d{4})\]`)
-	year := 0
-	title := basename
-
-	// Try all possible year patterns
-	for _, matches := range yearPattern.FindAllStringSubmatch(basename, -1) {
-		for i := 1; i < len(matches); i++ {
-			if matches[i] != "" {
-				if y, err := strconv.Atoi(matches[i]); err == nil {
-					year = y
-					// Remove year from title
-					title = yearPattern.ReplaceAllString(basename, " ")
-					break
-				}
+	searchResults, err := p.client.GetSearchMovies(search.Title, searchOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search movie: %v", err)
+	}
+
+	if len(searchResults.Results) == 0 {
+		// If no results with year, try without year
+		if search.Year > 0 {
+			delete(searchOptions, "year")
+			searchResults, err = p.client.GetSearchMovies(search.Title, searchOptions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search movie: %v", err)
 			}
 		}
-		if year != 0 {
-			break
+		if len(searchResults.Results) == 0 {
+			return nil, fmt.Errorf("no movies found matching '%s'", search.Title)
 		}
 	}
 
-	// Only clean up the title if we found a year in valid delimiters
-	if year > 0 {
-		// Clean up the title by removing common patterns
-		title = cleanTitle(title)
-	} else {
-		// For files without valid delimited years, just return the original
-		// This preserves formats like "The.Matrix.1999.mp4"
-		return MovieSearch{
-			Title: basename,
-			Year:  0,
+	candidates := make([]Candidate, 0, len(searchResults.Results))
+	for _, result := range searchResults.Results {
+		candidates = append(candidates, Candidate{
+			ID:         strconv.FormatInt(result.ID, 10),
+			Provider:   string(config.ProviderTMDb),
+			Title:      result.Title,
+			AltTitles:  []string{result.OriginalTitle},
+			Year:       dateYear(result.ReleaseDate),
+			Overview:   result.Overview,
+			Popularity: float64(result.Popularity),
+			Votes:      int(result.VoteCount),
+		})
+	}
+	// Search results lack the runtime, which the details have
+	return rankWithRuntimes(movieQuery(search), candidates, 0, tmdbRuntimeLookups, func(c Candidate) int {
+		id, _ := strconv.Atoi(c.ID)
+		details, err := p.client.GetMovieDetails(id, map[string]string{"language": language})
+		if err != nil {
+			return 0
 		}
+		return details.Runtime
+	}), nil
+}
+
+// SearchTVShow searches for a TV show using TMDb. Episode titles are read
+// from the details of their season, in the requested language.
+func (p *TMDbProvider) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
+	options := map[string]string{
+		"language": language,
 	}
 
-	return MovieSearch{
-		Title: title,
-		Year:  year,
+	showID, err := p.findShowID(search, options)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// ExtractTVShowInfo extracts TV show information from a filename
-func ExtractTVShowInfo(filename string) TVShowSearch {
-	// Extract base name without extension
-	basename := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	// Get the show's details
+	show, err := p.client.GetTVDetails(showID, map[string]string{
+		"language":           language,
+		"append_to_response": "alternative_titles",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get show details: %v", err)
+	}
 
-	// Look for common TV show patterns
-	// Pattern 1: ShowName.S01E02
-	seasonEpisodePattern1 := regexp.MustCompile(`(?i)(.*?)[\s\._-]*s(\d{1,2})[\s\._-]*e(\d{1,2})(?:[\s\._-]*(.*))?`)
+	year := 0
+	if show.FirstAirDate != "" {
+		if t, err := time.Parse("2006-01-02", show.FirstAirDate); err == nil {
+			year = t.Year()
+		}
+	}
 
-	// Pattern 2: ShowName.1x02
-	seasonEpisodePattern2 := regexp.MustCompile(`(?i)(.*?)[\s\._-]*(\d{1,2})x(\d{1,2})(?:[\s\._-]*(.*))?`)
+	genreNames := make([]string, 0, len(show.Genres))
+	for _, genre := range show.Genres {
+		genreNames = append(genreNames, genre.Name)
+	}
 
-	// Pattern 3: ShowName.Season.1.Episode.2
-	seasonEpisodePattern3 := regexp.MustCompile(`(?i)(.*?)[\s\._-]*(?:season|s)[\s\._-]*(\d{1,2})[\s\._-]*(?:episode|ep|e)[\s\._-]*(\d{1,2})(?:[\s\._-]*(.*))?`)
+	var altTitles []string
+	if show.OriginalName != "" && show.OriginalName != show.Name {
+		altTitles = append(altTitles, show.OriginalName)
+	}
+	if show.TVAlternativeTitlesAppend != nil && show.AlternativeTitles != nil && show.AlternativeTitles.TVAlternativeTitlesResults != nil {
+		for _, alt := range show.AlternativeTitles.Results {
+			altTitles = append(altTitles, alt.Title)
+		}
+	}
 
-	// Try all season/episode patterns
-	var title string
-	var season, episode int
-	var episodeTitle string
+	// Basic metadata without episode info
+	metadata := &TVShowMetadata{
+		Title:       show.Name,
+		AltTitles:   altTitles,
+		Year:        year,
+		Overview:    show.Overview,
+		SeasonCount: show.NumberOfSeasons,
+		Status:      show.Status,
+		Genres:      genreNames,
+		Provider:    string(config.ProviderTMDb),
+		ID:          strconv.Itoa(showID),
+	}
+	if len(show.Networks) > 0 {
+		metadata.Network = show.Networks[0].Name
+	}
 
-	if m := seasonEpisodePattern1.FindStringSubmatch(basename); len(m) >= 4 {
-		title = m[1]
-		season, _ = strconv.Atoi(m[2])
-		episode, _ = strconv.Atoi(m[3])
-		if len(m) > 4 {
-			episodeTitle = m[4]
-		}
-	} else if m := seasonEpisodePattern2.FindStringSubmatch(basename); len(m) >= 4 {
-		title = m[1]
-		season, _ = strconv.Atoi(m[2])
-		episode, _ = strconv.Atoi(m[3])
-		if len(m) > 4 {
-			episodeTitle = m[4]
+	switch {
+	case (search.Season > 0 || search.Special) && search.Episode > 0:
+		// Specials are season 0 on TMDb
+		season := search.Season
+		if search.Special {
+			season = 0
 		}
-	} else if m := seasonEpisodePattern3.FindStringSubmatch(basename); len(m) >= 4 {
-		title = m[1]
-		season, _ = strconv.Atoi(m[2])
-		episode, _ = strconv.Atoi(m[3])
-		if len(m) > 4 {
-			episodeTitle = m[4]
+		details, err := p.client.GetTVSeasonDetails(showID, season, options)
+		if err != nil {
+			// Return show info without episode details
+			return metadata, nil
 		}
-	} else {
-		// If no TV pattern is found, just return the title
-		return TVShowSearch{
-			Title: cleanTitle(basename),
+		var titles []string
+		for i, number := range search.EpisodeNumbers() {
+			index := tmdbEpisodeIndex(details, number)
+			if index < 0 {
+				if i == 0 {
+					return metadata, nil
+				}
+				titles = append(titles, "")
+				continue
+			}
+
+			// The first episode provides the episode details
+			episode := details.Episodes[index]
+			if i == 0 {
+				metadata.Season, metadata.Episode, metadata.AirDate = season, episode.EpisodeNumber, episode.AirDate
+			}
+			titles = append(titles, episode.Name)
 		}
-	}
 
-	// Look for year pattern (YYYY) in title, but only in delimiters
-	// Only accept years in parentheses or square brackets
-	yearPattern := regexp.MustCompile(`\((\d{4})\)|\[(\d{4})\]`)
-	year := 0
+		setEpisodeTitles(metadata, search, titles)
 
-	// Try all possible year patterns
-	for _, matches := range yearPattern.FindAllStringSubmatch(title, -1) {
-		for i := 1; i < len(matches); i++ {
-			if matches[i] != "" {
-				if y, err := strconv.Atoi(matches[i]); err == nil {
-					year = y
-					// Remove year from title
-					title = yearPattern.ReplaceAllString(title, " ")
-					break
+	case search.AirDate != "":
+		// Daily shows are looked up by air date, starting with the last season
+		// that began before it
+		metadata.AirDate = search.AirDate
+		for i := len(show.Seasons) - 1; i >= 0; i-- {
+			season := show.Seasons[i]
+			if season.SeasonNumber == 0 || season.AirDate == "" || season.AirDate > search.AirDate {
+				continue
+			}
+			details, err := p.client.GetTVSeasonDetails(showID, season.SeasonNumber, options)
+			if err != nil {
+				break
+			}
+			for _, episode := range details.Episodes {
+				if episode.AirDate == search.AirDate {
+					metadata.Season, metadata.Episode = season.SeasonNumber, episode.EpisodeNumber
+					metadata.EpisodeTitle = episode.Name
+					return metadata, nil
 				}
 			}
 		}
-		if year != 0 {
+
+	case search.AbsoluteEpisode > 0:
+		// Absolute numbers count the regular episodes of all seasons in order
+		metadata.AbsoluteEpisode = search.AbsoluteEpisode
+		number := search.AbsoluteEpisode
+		for _, season := range show.Seasons {
+			if season.SeasonNumber == 0 {
+				continue
+			}
+			if number > season.EpisodeCount {
+				number -= season.EpisodeCount
+				continue
+			}
+			details, err := p.client.GetTVSeasonDetails(showID, season.SeasonNumber, options)
+			if err != nil {
+				break
+			}
+			if index := tmdbEpisodeIndex(details, number); index >= 0 {
+				episode := details.Episodes[index]
+				metadata.Season, metadata.Episode, metadata.AirDate = season.SeasonNumber, episode.EpisodeNumber, episode.AirDate
+				metadata.EpisodeTitle = episode.Name
+			}
 			break
 		}
 	}
 
-	// Clean up the titles
-	title = cleanTitle(title)
+	return metadata, nil
+}
 
-	// Clean up episode title - filter out quality and technical info
-	if episodeTitle != "" {
-		// List of common quality/codec terms to filter out
-		qualityTerms := []string{"1080p", "720p", "480p", "HEVC", "h264", "x264", "HDRip", "BRRip", "BluRay", "WEB-DL", "HDTV"}
+// findShowID returns the TMDb ID of the show. Picked shows and IDs from the
+// embedded tags are used directly; otherwise the best ranked search result
+// for the title is taken.
+func (p *TMDbProvider) findShowID(search TVShowSearch, options map[string]string) (int, error) {
+	if search.Provider == string(config.ProviderTMDb) {
+		return strconv.Atoi(search.ProviderID)
+	}
+	if search.TMDbID > 0 {
+		return search.TMDbID, nil
+	}
+	if search.IMDbID != "" {
+		found, err := p.client.GetFindByID(search.IMDbID, map[string]string{
+			"external_source": "imdb_id",
+			"language":        options["language"],
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to find TV show %s: %v", search.IMDbID, err)
+		}
+		if len(found.TvResults) > 0 {
+			return int(found.TvResults[0].ID), nil
+		}
+		// Unknown IDs fall back to searching by title
+	}
+
+	candidates, err := p.TVShowCandidates(search, options["language"], 1)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(candidates[0].ID)
+}
+
+// TVShowCandidates searches TMDb for the show and ranks the results.
+func (p *TMDbProvider) TVShowCandidates(search TVShowSearch, language string, limit int) ([]Candidate, error) {
+	key := candidateSearch{kind: "series", query: tvShowQuery(search), language: language}
+	return p.searches.ranked(key, limit, func() ([]Candidate, error) {
+		return p.searchShows(search, language)
+	})
+}
+
+// searchShows searches TMDb for the show and ranks all results.
+func (p *TMDbProvider) searchShows(search TVShowSearch, language string) ([]Candidate, error) {
+	// If we have a year, add it to improve search accuracy
+	searchOptions := map[string]string{"language": language}
+	if search.Year > 0 {
+		searchOptions["first_air_date_year"] = strconv.Itoa(search.Year)
+	}
 
-		cleanEpisodeTitle := episodeTitle
+	searchResults, err := p.client.GetSearchTVShow(search.Title, searchOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search TV show: %v", err)
+	}
 
-		for _, term := range qualityTerms {
-			if strings.Contains(strings.ToLower(episodeTitle), strings.ToLower(term)) {
-				// If episode title is just a quality indicator, set it to empty
-				cleanEpisodeTitle = regexp.MustCompile(`(?i)`+term).ReplaceAllString(cleanEpisodeTitle, "")
+	if len(searchResults.Results) == 0 {
+		// If no results with year, try without year
+		if search.Year > 0 {
+			delete(searchOptions, "first_air_date_year")
+			searchResults, err = p.client.GetSearchTVShow(search.Title, searchOptions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search TV show: %v", err)
 			}
 		}
+		if len(searchResults.Results) == 0 {
+			return nil, fmt.Errorf("no TV shows found matching '%s'", search.Title)
+		}
+	}
+
+	candidates := make([]Candidate, 0, len(searchResults.Results))
+	for _, result := range searchResults.Results {
+		candidates = append(candidates, Candidate{
+			ID:         strconv.FormatInt(result.ID, 10),
+			Provider:   string(config.ProviderTMDb),
+			Title:      result.Name,
+			AltTitles:  []string{result.OriginalName},
+			Year:       dateYear(result.FirstAirDate),
+			Overview:   result.Overview,
+			Popularity: float64(result.Popularity),
+			Votes:      int(result.VoteCount),
+		})
+	}
+	return RankCandidates(tvShowQuery(search), candidates, 0), nil
+}
+
+// dateYear returns the year of a YYYY-MM-DD date, or 0 when it has none.
+func dateYear(date string) int {
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return t.Year()
+	}
+	return 0
+}
 
-		// If after removing all quality terms, only spaces remain, consider it empty
-		if strings.TrimSpace(cleanEpisodeTitle) == "" {
-			episodeTitle = ""
-		} else {
-			episodeTitle = cleanTitle(episodeTitle)
+// tmdbEpisodeIndex returns the index of an episode in the details of its
+// season, or -1 when the season does not have it.
+func tmdbEpisodeIndex(season *tmdb.TVSeasonDetails, number int) int {
+	for i, episode := range season.Episodes {
+		if episode.EpisodeNumber == number {
+			return i
 		}
 	}
+	return -1
+}
+
+// ExtractMovieInfo extracts movie information from a filename. Directory
+// names in the path fill in what a poorly named file lacks. User-defined
+// parsing rules are tried before the built-in patterns.
+//
+// Names that are more likely TV episodes than movies, judged by the
+// confidence of both interpretations, yield an empty search.
+func ExtractMovieInfo(filename string, rules ...release.Rule) MovieSearch {
+	parsed, _ := release.ParsePathWithRules(filename, release.ScopeMovie, rules)
+	if parsed.Extra != "" {
+		// Extras are not movies of their own, see ExtractExtraInfo
+		return MovieSearch{}
+	}
+	search := MovieSearch{
+		Title:      parsed.Title,
+		Year:       parsed.Year,
+		Edition:    parsed.Edition,
+		Part:       parsed.Part,
+		Confidence: parsed.MovieConfidence(),
+	}
 
-	return TVShowSearch{
-		Title:        title,
-		Year:         year,
-		Season:       season,
-		Episode:      episode,
-		EpisodeTitle: episodeTitle,
+	// Check whether the TV show interpretation is more convincing
+	if tvInfo := ExtractTVShowInfo(filename, rules...); tvInfo.IsEpisode() && tvInfo.Confidence >= search.Confidence {
+		return MovieSearch{}
 	}
+	return search
 }
 
-// Helper to clean up titles
-func cleanTitle(title string) string {
-	// Clean up the title by removing common patterns
-	title = strings.NewReplacer(
-		"1080p", "",
-		"720p", "",
-		"480p", "",
-		"360p", "",
-		"h264", "",
-		"x264", "",
-		"HDRip", "",
-		"BRRip", "",
-		"BluRay", "",
-		"WEB-DL", "",
-		"HDTV", "",
-		"[", " ",
-		"]", " ",
-		"(", " ",
-		")", " ",
-		".", " ",
-		"_", " ",
-	).Replace(title)
-
-	// Clean up extra spaces
-	title = regexp.MustCompile(`\s+`).ReplaceAllString(strings.TrimSpace(title), " ")
-
-	return title
+// ExtractTVShowInfo extracts TV show information from a filename. Directory
+// names in the path fill in what a poorly named file lacks, such as the show
+// of a file inside "Show Name (2010)/Season 02". User-defined parsing rules
+// are tried before the built-in patterns.
+func ExtractTVShowInfo(filename string, rules ...release.Rule) TVShowSearch {
+	parsed, _ := release.ParsePathWithRules(filename, release.ScopeTV, rules)
+
+	search := TVShowSearch{
+		Title:           parsed.Title,
+		Year:            parsed.Year,
+		Season:          parsed.Season,
+		Special:         parsed.Special,
+		Episode:         parsed.Episode(),
+		EpisodeTitle:    parsed.EpisodeTitle,
+		AirDate:         parsed.AirDate,
+		AbsoluteEpisode: parsed.AbsoluteEpisode,
+		Confidence:      parsed.EpisodeConfidence(),
+	}
+	if len(parsed.Episodes) > 1 {
+		search.Episodes = parsed.Episodes
+	}
+	return search
 }
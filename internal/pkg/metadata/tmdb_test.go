@@ -1,6 +1,9 @@
 package metadata
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"testing"
 
 	tmdb "github.com/cyruzin/golang-tmdb"
@@ -9,6 +12,10 @@ import (
 type mockTMDbClient struct {
 	searchMoviesFunc func(query string, urlOptions map[string]string) (*tmdb.SearchMovies, error)
 	movieDetailsFunc func(id int, urlOptions map[string]string) (*tmdb.MovieDetails, error)
+	findByIDFunc     func(id string, urlOptions map[string]string) (*tmdb.FindByID, error)
+	searchTVFunc     func(query string, urlOptions map[string]string) (*tmdb.SearchTVShows, error)
+	tvDetailsFunc    func(id int, urlOptions map[string]string) (*tmdb.TVDetails, error)
+	seasonFunc       func(id int, seasonNumber int, urlOptions map[string]string) (*tmdb.TVSeasonDetails, error)
 }
 
 func (m *mockTMDbClient) GetSearchMovies(query string, urlOptions map[string]string) (*tmdb.SearchMovies, error) {
@@ -19,6 +26,22 @@ func (m *mockTMDbClient) GetMovieDetails(id int, urlOptions map[string]string) (
 	return m.movieDetailsFunc(id, urlOptions)
 }
 
+func (m *mockTMDbClient) GetFindByID(id string, urlOptions map[string]string) (*tmdb.FindByID, error) {
+	return m.findByIDFunc(id, urlOptions)
+}
+
+func (m *mockTMDbClient) GetSearchTVShow(query string, urlOptions map[string]string) (*tmdb.SearchTVShows, error) {
+	return m.searchTVFunc(query, urlOptions)
+}
+
+func (m *mockTMDbClient) GetTVDetails(id int, urlOptions map[string]string) (*tmdb.TVDetails, error) {
+	return m.tvDetailsFunc(id, urlOptions)
+}
+
+func (m *mockTMDbClient) GetTVSeasonDetails(id int, seasonNumber int, urlOptions map[string]string) (*tmdb.TVSeasonDetails, error) {
+	return m.seasonFunc(id, seasonNumber, urlOptions)
+}
+
 func TestExtractMovieInfo(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -50,11 +73,11 @@ func TestExtractMovieInfo(t *testing.T) {
 			},
 		},
 		{
-			name:     "Movie with year in dots (should not extract year)",
+			name:     "Movie with year in dots",
 			filename: "The.Matrix.1999.mp4",
 			want: MovieSearch{
-				Title: "The.Matrix.1999",
-				Year:  0,
+				Title: "The Matrix",
+				Year:  1999,
 			},
 		},
 		{
@@ -66,11 +89,52 @@ func TestExtractMovieInfo(t *testing.T) {
 			},
 		},
 		{
-			name:     "Movie with dots and quality (should not extract year)",
+			name:     "Movie with dots and quality",
 			filename: "The.Matrix.1999.1080p.BluRay.x264.mp4",
 			want: MovieSearch{
-				Title: "The.Matrix.1999.1080p.BluRay.x264",
-				Year:  0,
+				Title: "The Matrix",
+				Year:  1999,
+			},
+		},
+		{
+			name:     "Number in the title before the year",
+			filename: "Blade.Runner.2049.2017.1080p.WEB-DL.mkv",
+			want: MovieSearch{
+				Title: "Blade Runner 2049",
+				Year:  2017,
+			},
+		},
+		{
+			name:     "Title that is a number",
+			filename: "1917.2019.2160p.mkv",
+			want: MovieSearch{
+				Title: "1917",
+				Year:  2019,
+			},
+		},
+		{
+			name:     "Release tags are not part of the title",
+			filename: "Dune (2021) 2160p.HMAX.WEB-DL.DDP5.1.Atmos.HDR10.H.265-GRP.mkv",
+			want: MovieSearch{
+				Title: "Dune",
+				Year:  2021,
+			},
+		},
+		{
+			name:     "Obfuscated file in release directory",
+			filename: "Downloads/The.Matrix.1999.1080p.BluRay.x264-GRP/grp-matrix.mkv",
+			want: MovieSearch{
+				Title: "The Matrix",
+				Year:  1999,
+			},
+		},
+		{
+			name:     "Movie split across discs",
+			filename: "Old.Movie.2004.CD2.DVDRip.XviD-GRP.avi",
+			want: MovieSearch{
+				Title: "Old Movie",
+				Year:  2004,
+				Part:  2,
 			},
 		},
 		{
@@ -92,6 +156,9 @@ func TestExtractMovieInfo(t *testing.T) {
 			if got.Year != tt.want.Year {
 				t.Errorf("ExtractMovieInfo() year = %v, want %v", got.Year, tt.want.Year)
 			}
+			if got.Part != tt.want.Part {
+				t.Errorf("ExtractMovieInfo() part = %v, want %v", got.Part, tt.want.Part)
+			}
 		})
 	}
 }
@@ -141,6 +208,335 @@ func TestTMDbProvider_SearchMovie(t *testing.T) {
 	*/
 }
 
+func TestTMDbProvider_SearchMovieByID(t *testing.T) {
+	var searched bool
+	var detailsID int
+	client := &mockTMDbClient{
+		searchMoviesFunc: func(query string, urlOptions map[string]string) (*tmdb.SearchMovies, error) {
+			searched = true
+			return &tmdb.SearchMovies{}, nil
+		},
+		movieDetailsFunc: func(id int, urlOptions map[string]string) (*tmdb.MovieDetails, error) {
+			detailsID = id
+			return &tmdb.MovieDetails{Title: "Inception", ReleaseDate: "2010-07-15"}, nil
+		},
+		findByIDFunc: func(id string, urlOptions map[string]string) (*tmdb.FindByID, error) {
+			found := &tmdb.FindByID{}
+			if id == "tt1375666" && urlOptions["external_source"] == "imdb_id" {
+				if err := json.Unmarshal([]byte(`{"movie_results": [{"id": 27205}]}`), found); err != nil {
+					return nil, err
+				}
+			}
+			return found, nil
+		},
+	}
+	provider := &TMDbProvider{client: client}
+
+	tests := []struct {
+		name   string
+		search MovieSearch
+	}{
+		{name: "TMDb ID", search: MovieSearch{Title: "title_t00", TMDbID: 27205}},
+		{name: "IMDb ID", search: MovieSearch{Title: "title_t00", IMDbID: "tt1375666"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searched, detailsID = false, 0
+			movie, err := provider.SearchMovie(tt.search, "en")
+			if err != nil {
+				t.Fatalf("SearchMovie() error = %v", err)
+			}
+			if searched {
+				t.Error("SearchMovie() searched by title, want a lookup by ID")
+			}
+			if detailsID != 27205 || movie.Title != "Inception" || movie.Year != 2010 {
+				t.Errorf("SearchMovie() = %+v for ID %d, want Inception (2010) for ID 27205", movie, detailsID)
+			}
+		})
+	}
+}
+
+func TestTMDbProvider_MovieCandidates(t *testing.T) {
+	client := &mockTMDbClient{
+		searchMoviesFunc: func(query string, urlOptions map[string]string) (*tmdb.SearchMovies, error) {
+			// TMDb lists the popular remake first, whatever the year
+			results := &tmdb.SearchMovies{}
+			err := json.Unmarshal([]byte(`{"results": [
+				{"id": 2103, "title": "Solaris", "original_title": "Solaris", "release_date": "2002-11-27", "popularity": 30.5, "vote_count": 2100},
+				{"id": 593, "title": "Solaris", "original_title": "Солярис", "release_date": "1972-02-05", "popularity": 15.2, "vote_count": 1500},
+				{"id": 40410, "title": "Solaris 2.0", "release_date": "2019-01-01", "popularity": 0.6, "vote_count": 2}
+			]}`), results)
+			return results, err
+		},
+	}
+	provider := &TMDbProvider{client: client}
+
+	tests := []struct {
+		name   string
+		search MovieSearch
+		want   []string
+	}{
+		{name: "Without year", search: MovieSearch{Title: "Solaris"}, want: []string{"2103", "593"}},
+		{name: "Year of the original", search: MovieSearch{Title: "Solaris", Year: 1972}, want: []string{"593", "2103"}},
+		{name: "Original title", search: MovieSearch{Title: "Солярис"}, want: []string{"593", "2103"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, err := provider.MovieCandidates(tt.search, "en", 2)
+			if err != nil {
+				t.Fatalf("MovieCandidates() error = %v", err)
+			}
+			var got []string
+			for _, c := range candidates {
+				got = append(got, c.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MovieCandidates() = %v, want %v", got, tt.want)
+			}
+			if candidates[0].Provider != "tmdb" {
+				t.Errorf("MovieCandidates() provider = %q, want tmdb", candidates[0].Provider)
+			}
+		})
+	}
+}
+
+func TestTMDbProvider_MovieCandidatesRuntime(t *testing.T) {
+	runtimes := map[int]int{11850: 115, 11549: 80}
+	var details int
+	client := &mockTMDbClient{
+		searchMoviesFunc: func(query string, urlOptions map[string]string) (*tmdb.SearchMovies, error) {
+			results := &tmdb.SearchMovies{}
+			err := json.Unmarshal([]byte(`{"results": [
+				{"id": 11850, "title": "Invasion of the Body Snatchers", "release_date": "1978-12-20"},
+				{"id": 11549, "title": "Invasion of the Body Snatchers", "release_date": "1956-02-05"}
+			]}`), results)
+			return results, err
+		},
+		movieDetailsFunc: func(id int, urlOptions map[string]string) (*tmdb.MovieDetails, error) {
+			details++
+			return &tmdb.MovieDetails{ID: int64(id), Runtime: runtimes[id]}, nil
+		},
+	}
+	provider := &TMDbProvider{client: client}
+
+	// The search results lack the runtime, so the details are looked up
+	candidates, err := provider.MovieCandidates(MovieSearch{Title: "Invasion of the Body Snatchers", Duration: 80*60 + 25}, "en", 0)
+	if err != nil {
+		t.Fatalf("MovieCandidates() error = %v", err)
+	}
+	if candidates[0].ID != "11549" || candidates[0].Runtime != 80 || details != 2 {
+		t.Errorf("MovieCandidates() = %+v after %d details, want the 1956 movie of 80 minutes first", candidates, details)
+	}
+
+	// Without a duration, there is nothing to compare
+	details = 0
+	if _, err := provider.MovieCandidates(MovieSearch{Title: "Invasion of the Body Snatchers"}, "en", 0); err != nil || details != 0 {
+		t.Errorf("MovieCandidates() without duration looked up %d details, error %v; want none", details, err)
+	}
+}
+
+func TestTMDbProvider_MovieCandidatesAfterSearch(t *testing.T) {
+	var searches int
+	client := &mockTMDbClient{
+		searchMoviesFunc: func(query string, urlOptions map[string]string) (*tmdb.SearchMovies, error) {
+			searches++
+			results := &tmdb.SearchMovies{}
+			err := json.Unmarshal([]byte(`{"results": [
+				{"id": 2103, "title": "Solaris", "release_date": "2002-11-27"},
+				{"id": 593, "title": "Solaris", "release_date": "1972-02-05"}
+			]}`), results)
+			return results, err
+		},
+		movieDetailsFunc: func(id int, urlOptions map[string]string) (*tmdb.MovieDetails, error) {
+			return &tmdb.MovieDetails{ID: int64(id), Title: "Solaris", ReleaseDate: "2002-11-27"}, nil
+		},
+	}
+	provider := &TMDbProvider{client: client}
+
+	// Listing the candidates of a title that was just looked up reuses its search
+	search := MovieSearch{Title: "Solaris", Year: 2002}
+	if _, err := provider.SearchMovie(search, "en"); err != nil {
+		t.Fatalf("SearchMovie() error = %v", err)
+	}
+	candidates, err := provider.MovieCandidates(search, "en", 5)
+	if err != nil {
+		t.Fatalf("MovieCandidates() error = %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].ID != "2103" || searches != 1 {
+		t.Errorf("MovieCandidates() = %+v after %d searches, want both movies after one search", candidates, searches)
+	}
+
+	// Other searches are not cached
+	if _, err := provider.MovieCandidates(MovieSearch{Title: "Solaris", Year: 1972}, "en", 5); err != nil || searches != 2 {
+		t.Errorf("MovieCandidates() of another year searched %d times, error %v; want 2 searches", searches, err)
+	}
+}
+
+// newMockTMDbTVClient returns a client serving Breaking Bad (TMDb ID 1396)
+// with two seasons of three episodes and one special. Requests not in the
+// requested language fail.
+func newMockTMDbTVClient(t *testing.T) *mockTMDbClient {
+	decode := func(data string, v interface{}) {
+		if err := json.Unmarshal([]byte(data), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkLanguage := func(urlOptions map[string]string) error {
+		if urlOptions["language"] != "de" {
+			return fmt.Errorf("language = %q, want de", urlOptions["language"])
+		}
+		return nil
+	}
+	seasons := map[int]string{
+		0: `{"episodes": [{"episode_number": 1, "name": "Good Cop Bad Cop", "air_date": "2009-02-17"}]}`,
+		1: `{"episodes": [
+			{"episode_number": 1, "name": "Der Einstieg", "air_date": "2008-01-20"},
+			{"episode_number": 2, "name": "Die Katze ist im Sack", "air_date": "2008-01-27"},
+			{"episode_number": 3, "name": "Die Tasche", "air_date": "2008-02-10"}]}`,
+		2: `{"episodes": [
+			{"episode_number": 1, "name": "Sieben Dreißig Sieben", "air_date": "2009-03-08"},
+			{"episode_number": 2, "name": "Grilled", "air_date": "2009-03-15"},
+			{"episode_number": 3, "name": "Bit by a Dead Bee", "air_date": "2009-03-22"}]}`,
+	}
+
+	return &mockTMDbClient{
+		searchTVFunc: func(query string, urlOptions map[string]string) (*tmdb.SearchTVShows, error) {
+			results := &tmdb.SearchTVShows{}
+			if query == "Breaking Bad" && urlOptions["first_air_date_year"] != "2010" {
+				decode(`{"results": [{"id": 1396, "name": "Breaking Bad"}]}`, results)
+			} else {
+				decode(`{"results": []}`, results)
+			}
+			return results, checkLanguage(urlOptions)
+		},
+		tvDetailsFunc: func(id int, urlOptions map[string]string) (*tmdb.TVDetails, error) {
+			if id != 1396 {
+				return nil, fmt.Errorf("unknown show %d", id)
+			}
+			details := &tmdb.TVDetails{}
+			decode(`{
+				"name": "Breaking Bad", "first_air_date": "2008-01-20", "overview": "Ein Chemielehrer...",
+				"number_of_seasons": 2, "status": "Ended",
+				"networks": [{"name": "AMC"}], "genres": [{"name": "Drama"}, {"name": "Krimi"}],
+				"seasons": [
+					{"season_number": 0, "episode_count": 1, "air_date": "2009-02-17"},
+					{"season_number": 1, "episode_count": 3, "air_date": "2008-01-20"},
+					{"season_number": 2, "episode_count": 3, "air_date": "2009-03-08"}]}`, details)
+			return details, checkLanguage(urlOptions)
+		},
+		seasonFunc: func(id int, seasonNumber int, urlOptions map[string]string) (*tmdb.TVSeasonDetails, error) {
+			data, ok := seasons[seasonNumber]
+			if id != 1396 || !ok {
+				return nil, fmt.Errorf("unknown season %d of show %d", seasonNumber, id)
+			}
+			details := &tmdb.TVSeasonDetails{}
+			decode(data, details)
+			return details, checkLanguage(urlOptions)
+		},
+		findByIDFunc: func(id string, urlOptions map[string]string) (*tmdb.FindByID, error) {
+			found := &tmdb.FindByID{}
+			if id == "tt0903747" && urlOptions["external_source"] == "imdb_id" {
+				decode(`{"tv_results": [{"id": 1396}]}`, found)
+			}
+			return found, nil
+		},
+	}
+}
+
+func TestTMDbProvider_SearchTVShow(t *testing.T) {
+	provider := &TMDbProvider{client: newMockTMDbTVClient(t)}
+
+	show := TVShowMetadata{
+		Title:       "Breaking Bad",
+		Year:        2008,
+		Overview:    "Ein Chemielehrer...",
+		SeasonCount: 2,
+		Network:     "AMC",
+		Status:      "Ended",
+		Genres:      []string{"Drama", "Krimi"},
+		Provider:    "tmdb",
+		ID:          "1396",
+	}
+	episode := func(season, number int, airDate, title string) TVShowMetadata {
+		m := show
+		m.Season, m.Episode, m.AirDate, m.EpisodeTitle = season, number, airDate, title
+		return m
+	}
+
+	tests := []struct {
+		name   string
+		search TVShowSearch
+		want   TVShowMetadata
+	}{
+		{
+			name:   "Season and episode",
+			search: TVShowSearch{Title: "Breaking Bad", Season: 1, Episode: 2},
+			want:   episode(1, 2, "2008-01-27", "Die Katze ist im Sack"),
+		},
+		{
+			name:   "Year that does not match falls back to the title",
+			search: TVShowSearch{Title: "Breaking Bad", Year: 2010, Season: 1, Episode: 2},
+			want:   episode(1, 2, "2008-01-27", "Die Katze ist im Sack"),
+		},
+		{
+			name:   "Multi-episode file",
+			search: TVShowSearch{Title: "Breaking Bad", Season: 2, Episode: 1, Episodes: []int{1, 2}},
+			want: func() TVShowMetadata {
+				m := episode(2, 1, "2009-03-08", "Sieben Dreißig Sieben & Grilled")
+				m.Episodes, m.EpisodeTitles = []int{1, 2}, []string{"Sieben Dreißig Sieben", "Grilled"}
+				return m
+			}(),
+		},
+		{
+			name:   "Special",
+			search: TVShowSearch{Title: "Breaking Bad", Special: true, Episode: 1},
+			want:   episode(0, 1, "2009-02-17", "Good Cop Bad Cop"),
+		},
+		{
+			name:   "Air date",
+			search: TVShowSearch{Title: "Breaking Bad", AirDate: "2008-02-10"},
+			want:   episode(1, 3, "2008-02-10", "Die Tasche"),
+		},
+		{
+			name:   "Absolute episode",
+			search: TVShowSearch{Title: "Breaking Bad", AbsoluteEpisode: 5},
+			want: func() TVShowMetadata {
+				m := episode(2, 2, "2009-03-15", "Grilled")
+				m.AbsoluteEpisode = 5
+				return m
+			}(),
+		},
+		{
+			name:   "IMDb ID",
+			search: TVShowSearch{Title: "bb", IMDbID: "tt0903747", Season: 1, Episode: 1},
+			want:   episode(1, 1, "2008-01-20", "Der Einstieg"),
+		},
+		{
+			name:   "Unknown episode",
+			search: TVShowSearch{Title: "Breaking Bad", Season: 3, Episode: 1},
+			want:   show,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.SearchTVShow(tt.search, "de")
+			if err != nil {
+				t.Fatalf("SearchTVShow() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("SearchTVShow() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	if _, err := provider.SearchTVShow(TVShowSearch{Title: "Unknown Show", Season: 1, Episode: 1}, "de"); err == nil {
+		t.Error("SearchTVShow() of an unknown show error = nil, want error")
+	}
+}
+
 func TestNewTMDbProvider(t *testing.T) {
 	// Skip this test since we can't easily mock tmdb.Init
 	t.Skip("Skipping NewTMDbProvider test")
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
 )
 
 // TVDbProvider implements TV show metadata lookup using TheTVDB API
@@ -16,6 +19,7 @@ type TVDbProvider struct {
 	apiToken    string
 	client      *http.Client
 	tokenExpiry time.Time
+	searches    searchCache // Ranked candidates of earlier searches
 }
 
 // TVDbLoginRequest represents a login request to the TVDb API
@@ -33,6 +37,7 @@ type TVDbSeriesResponse struct {
 	Data struct {
 		ID         int      `json:"id"`
 		SeriesName string   `json:"seriesName"`
+		Aliases    []string `json:"aliases"`
 		FirstAired string   `json:"firstAired"`
 		Status     string   `json:"status"`
 		Network    string   `json:"network"`
@@ -46,12 +51,13 @@ type TVDbSeriesResponse struct {
 // TVDbSearchResponse represents the search response from the TVDb API
 type TVDbSearchResponse struct {
 	Data []struct {
-		ID         int    `json:"id"`
-		SeriesName string `json:"seriesName"`
-		FirstAired string `json:"firstAired"`
-		Status     string `json:"status"`
-		Network    string `json:"network"`
-		Overview   string `json:"overview"`
+		ID         int      `json:"id"`
+		SeriesName string   `json:"seriesName"`
+		Aliases    []string `json:"aliases"`
+		FirstAired string   `json:"firstAired"`
+		Status     string   `json:"status"`
+		Network    string   `json:"network"`
+		Overview   string   `json:"overview"`
 	} `json:"data"`
 }
 
@@ -64,6 +70,7 @@ type TVDbEpisodeResponse struct {
 		Overview           string `json:"overview"`
 		AiredSeason        int    `json:"airedSeason"`
 		AiredEpisodeNumber int    `json:"airedEpisodeNumber"`
+		AbsoluteNumber     int    `json:"absoluteNumber"`
 	} `json:"data"`
 }
 
@@ -187,36 +194,27 @@ func (p *TVDbProvider) SearchMovie(search MovieSearch, language string) (*MovieM
 	return nil, fmt.Errorf("movie search is not supported by TVDb. Use TMDb or OMDb provider instead")
 }
 
+// MovieCandidates returns an error because TVDb is for TV shows, not movies
+func (p *TVDbProvider) MovieCandidates(search MovieSearch, language string, limit int) ([]Candidate, error) {
+	return nil, fmt.Errorf("movie search is not supported by TVDb. Use TMDb or OMDb provider instead")
+}
+
 // SearchTVShow searches for a TV show using TVDb
 func (p *TVDbProvider) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
-	// Search for the TV show
-	searchPath := fmt.Sprintf("/search/series?name=%s", url.QueryEscape(search.Title))
-
-	// Add year if available
-	if search.Year > 0 {
-		searchPath = fmt.Sprintf("%s&year=%d", searchPath, search.Year)
+	// Picked shows need no search
+	id := search.ProviderID
+	if search.Provider != string(config.ProviderTVDb) {
+		candidates, err := p.TVShowCandidates(search, language, 1)
+		if err != nil {
+			return nil, err
+		}
+		id = candidates[0].ID
 	}
-
-	resp, err := p.sendRequest("GET", searchPath, nil)
+	seriesID, err := strconv.Atoi(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search TV show: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Parse search response
-	var searchResp TVDbSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
-		return nil, fmt.Errorf("failed to decode search response: %v", err)
-	}
-
-	// Check if we found anything
-	if len(searchResp.Data) == 0 {
-		return nil, fmt.Errorf("no TV shows found matching '%s'", search.Title)
+		return nil, err
 	}
 
-	// Get the first result
-	seriesID := searchResp.Data[0].ID
-
 	// Get detailed series information
 	seriesPath := fmt.Sprintf("/series/%d", seriesID)
 	seriesResp, err := p.sendRequest("GET", seriesPath, nil)
@@ -262,6 +260,7 @@ func (p *TVDbProvider) SearchTVShow(search TVShowSearch, language string) (*TVSh
 	// Create the result metadata
 	metadata := &TVShowMetadata{
 		Title:       series.Data.SeriesName,
+		AltTitles:   series.Data.Aliases,
 		Year:        year,
 		Overview:    series.Data.Overview,
 		Network:     series.Data.Network,
@@ -270,31 +269,141 @@ func (p *TVDbProvider) SearchTVShow(search TVShowSearch, language string) (*TVSh
 		Genres:      series.Data.Genre,
 		Season:      search.Season,
 		Episode:     search.Episode,
+		Provider:    string(config.ProviderTVDb),
+		ID:          strconv.Itoa(seriesID),
 	}
 
-	// If we have season and episode information, get episode details
-	if search.Season > 0 && search.Episode > 0 {
-		episodePath := fmt.Sprintf("/series/%d/episodes/query?airedSeason=%d&airedEpisode=%d",
-			seriesID, search.Season, search.Episode)
-
-		episodeResp, err := p.sendRequest("GET", episodePath, nil)
-		if err != nil {
-			return metadata, nil // Return what we have so far, episode info is optional
+	switch {
+	case (search.Season > 0 || search.Special) && search.Episode > 0:
+		// If we have season and episode information, get episode details.
+		// TVDb keeps specials in season 0.
+		var titles []string
+		for i, number := range search.EpisodeNumbers() {
+			episode, err := p.getEpisode(seriesID, search.Season, number)
+			if err != nil {
+				if i == 0 {
+					return metadata, nil // Return what we have so far, episode info is optional
+				}
+				titles = append(titles, "")
+				continue
+			}
+
+			// The first episode provides the air date
+			if i == 0 && episode.Data.FirstAired != "" {
+				metadata.AirDate = episode.Data.FirstAired
+			}
+			if i == 0 && episode.Data.AbsoluteNumber > 0 {
+				metadata.AbsoluteEpisode = episode.Data.AbsoluteNumber
+			}
+			titles = append(titles, episode.Data.EpisodeName)
 		}
-		defer episodeResp.Body.Close()
 
-		// Parse episode response
-		var episode TVDbEpisodeResponse
-		if err := json.NewDecoder(episodeResp.Body).Decode(&episode); err != nil {
-			return metadata, nil // Return what we have so far
+		setEpisodeTitles(metadata, search, titles)
+
+	case search.AirDate != "":
+		// Daily shows are looked up by air date
+		metadata.AirDate = search.AirDate
+		if episode, err := p.queryEpisode(seriesID, "firstAired="+url.QueryEscape(search.AirDate)); err == nil {
+			setTVDbEpisode(metadata, episode)
 		}
 
-		// Update metadata with episode information
-		metadata.EpisodeTitle = episode.Data.EpisodeName
-		if episode.Data.FirstAired != "" {
-			metadata.AirDate = episode.Data.FirstAired
+	case search.AbsoluteEpisode > 0:
+		// Absolute numbers are mapped to the aired season and episode
+		metadata.AbsoluteEpisode = search.AbsoluteEpisode
+		if episode, err := p.queryEpisode(seriesID, fmt.Sprintf("absoluteNumber=%d", search.AbsoluteEpisode)); err == nil {
+			setTVDbEpisode(metadata, episode)
 		}
 	}
 
 	return metadata, nil
 }
+
+// TVShowCandidates searches TVDb for the show and ranks the results
+func (p *TVDbProvider) TVShowCandidates(search TVShowSearch, language string, limit int) ([]Candidate, error) {
+	key := candidateSearch{kind: "series", query: tvShowQuery(search), language: language}
+	return p.searches.ranked(key, limit, func() ([]Candidate, error) {
+		return p.searchShows(search)
+	})
+}
+
+// searchShows searches TVDb for the show and ranks all results
+func (p *TVDbProvider) searchShows(search TVShowSearch) ([]Candidate, error) {
+	// Search for the TV show
+	searchPath := fmt.Sprintf("/search/series?name=%s", url.QueryEscape(search.Title))
+
+	// Add year if available
+	if search.Year > 0 {
+		searchPath = fmt.Sprintf("%s&year=%d", searchPath, search.Year)
+	}
+
+	resp, err := p.sendRequest("GET", searchPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search TV show: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Parse search response
+	var searchResp TVDbSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %v", err)
+	}
+
+	// Check if we found anything
+	if len(searchResp.Data) == 0 {
+		return nil, fmt.Errorf("no TV shows found matching '%s'", search.Title)
+	}
+
+	candidates := make([]Candidate, 0, len(searchResp.Data))
+	for _, series := range searchResp.Data {
+		candidates = append(candidates, Candidate{
+			ID:        strconv.Itoa(series.ID),
+			Provider:  string(config.ProviderTVDb),
+			Title:     series.SeriesName,
+			AltTitles: series.Aliases,
+			Year:      dateYear(series.FirstAired),
+			Overview:  series.Overview,
+			Network:   series.Network,
+		})
+	}
+	return RankCandidates(tvShowQuery(search), candidates, 0), nil
+}
+
+// getEpisode fetches a single episode of a series by aired season and episode number
+func (p *TVDbProvider) getEpisode(seriesID, season, number int) (*TVDbEpisodeResponse, error) {
+	return p.queryEpisode(seriesID, fmt.Sprintf("airedSeason=%d&airedEpisode=%d", season, number))
+}
+
+// queryEpisode fetches the episode of a series matching the given query parameters
+func (p *TVDbProvider) queryEpisode(seriesID int, query string) (*TVDbEpisodeResponse, error) {
+	episodePath := fmt.Sprintf("/series/%d/episodes/query?%s", seriesID, query)
+
+	episodeResp, err := p.sendRequest("GET", episodePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer episodeResp.Body.Close()
+
+	if episodeResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get episode details: %s", episodeResp.Status)
+	}
+
+	// Parse episode response
+	var episode TVDbEpisodeResponse
+	if err := json.NewDecoder(episodeResp.Body).Decode(&episode); err != nil {
+		return nil, err
+	}
+	return &episode, nil
+}
+
+// setTVDbEpisode copies the details of an episode into the metadata
+func setTVDbEpisode(metadata *TVShowMetadata, episode *TVDbEpisodeResponse) {
+	metadata.Season = episode.Data.AiredSeason
+	metadata.Episode = episode.Data.AiredEpisodeNumber
+	metadata.EpisodeTitle = episode.Data.EpisodeName
+	if episode.Data.FirstAired != "" {
+		metadata.AirDate = episode.Data.FirstAired
+	}
+	if episode.Data.AbsoluteNumber > 0 {
+		metadata.AbsoluteEpisode = episode.Data.AbsoluteNumber
+	}
+}
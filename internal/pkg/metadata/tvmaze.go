@@ -6,14 +6,18 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
 )
 
 // TvMazeProvider implements TV show metadata lookup using TvMaze API
 type TvMazeProvider struct {
-	baseURL string
-	client  *http.Client
+	baseURL  string
+	client   *http.Client
+	searches searchCache // Ranked candidates of earlier searches
 }
 
 // Ensure TvMazeProvider implements MetadataProvider
@@ -29,6 +33,7 @@ type TvMazeShow struct {
 	Status       string   `json:"status"`
 	Runtime      int      `json:"runtime"`
 	Premiered    string   `json:"premiered"`
+	Weight       int      `json:"weight"`
 	OfficialSite string   `json:"officialSite"`
 	Network      struct {
 		ID      int    `json:"id"`
@@ -51,6 +56,9 @@ type TvMazeShow struct {
 			Name         string `json:"name"`
 			EpisodeOrder int    `json:"episodeOrder"`
 		} `json:"seasons"`
+		Akas []struct {
+			Name string `json:"name"`
+		} `json:"akas"`
 	} `json:"_embedded"`
 }
 
@@ -85,44 +93,20 @@ func (p *TvMazeProvider) SearchMovie(search MovieSearch, language string) (*Movi
 	return nil, fmt.Errorf("TvMaze does not support movie lookups")
 }
 
+// MovieCandidates returns an error because TvMaze is for TV shows, not movies
+func (p *TvMazeProvider) MovieCandidates(search MovieSearch, language string, limit int) ([]Candidate, error) {
+	return nil, fmt.Errorf("TvMaze does not support movie lookups")
+}
+
 // SearchTVShow searches for a TV show using TvMaze API
 func (p *TvMazeProvider) SearchTVShow(search TVShowSearch, language string) (*TVShowMetadata, error) {
-	// Construct search URL
-	query := url.QueryEscape(search.Title)
-	searchURL := fmt.Sprintf("%s/search/shows?q=%s", p.baseURL, query)
-
-	// Make HTTP request
-	resp, err := p.client.Get(searchURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search TV show: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to search TV show: %s", resp.Status)
-	}
-
-	// Parse response
-	var searchResults []TvMazeSearchResult
-	body, err := io.ReadAll(resp.Body)
+	showID, err := p.findShowID(search)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	err = json.Unmarshal(body, &searchResults)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+		return nil, err
 	}
 
-	if len(searchResults) == 0 {
-		return nil, fmt.Errorf("no TV shows found matching '%s'", search.Title)
-	}
-
-	// Get the first result
-	showID := searchResults[0].Show.ID
-
-	// Get show details with seasons information
-	showURL := fmt.Sprintf("%s/shows/%d?embed=seasons", p.baseURL, showID)
+	// Get show details with seasons information and alternative titles
+	showURL := fmt.Sprintf("%s/shows/%d?embed[]=seasons&embed[]=akas", p.baseURL, showID)
 	showResp, err := p.client.Get(showURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get show details: %v", err)
@@ -156,56 +140,237 @@ func (p *TvMazeProvider) SearchTVShow(search TVShowSearch, language string) (*TV
 	// Clean HTML tags from summary
 	summary := cleanHtmlTags(show.Summary)
 
+	var altTitles []string
+	for _, aka := range show.Embedded.Akas {
+		altTitles = append(altTitles, aka.Name)
+	}
+
 	// Basic metadata without episode info
 	metadata := &TVShowMetadata{
 		Title:       show.Name,
+		AltTitles:   altTitles,
 		Year:        year,
 		Overview:    summary,
 		SeasonCount: len(show.Embedded.Seasons),
 		Network:     show.Network.Name,
 		Status:      show.Status,
 		Genres:      show.Genres,
+		Provider:    string(config.ProviderTVMaze),
+		ID:          strconv.Itoa(showID),
 	}
 
-	// If season and episode are provided, get episode details
-	if search.Season > 0 && search.Episode > 0 {
-		episodeURL := fmt.Sprintf("%s/shows/%d/episodebynumber?season=%d&number=%d", p.baseURL, showID, search.Season, search.Episode)
-		episodeResp, err := p.client.Get(episodeURL)
+	switch {
+	case search.Special && search.Episode > 0:
+		// TvMaze does not number specials; they are counted in airing order,
+		// like season 0 of other databases
+		metadata.Season, metadata.Episode = 0, search.Episode
+		specials, err := p.getSpecials(showID)
 		if err != nil {
-			// Return show info without episode details
 			return metadata, nil
 		}
-		defer episodeResp.Body.Close()
-
-		if episodeResp.StatusCode != http.StatusOK {
-			// Return show info without episode details
-			return metadata, nil
+		var titles []string
+		for i, number := range search.EpisodeNumbers() {
+			if number > len(specials) {
+				if i == 0 {
+					return metadata, nil
+				}
+				titles = append(titles, "")
+				continue
+			}
+			if i == 0 {
+				metadata.AirDate = specials[number-1].Airdate
+			}
+			titles = append(titles, specials[number-1].Name)
 		}
 
-		// Parse episode details
-		var episode TvMazeEpisode
-		episodeBody, err := io.ReadAll(episodeResp.Body)
-		if err != nil {
-			// Return show info without episode details
-			return metadata, nil
+		setEpisodeTitles(metadata, search, titles)
+
+	case search.Season > 0 && search.Episode > 0:
+		// If season and episode are provided, get episode details
+		var titles []string
+		for i, number := range search.EpisodeNumbers() {
+			episode, err := p.getEpisode(showID, search.Season, number)
+			if err != nil {
+				if i == 0 {
+					// Return show info without episode details
+					return metadata, nil
+				}
+				titles = append(titles, "")
+				continue
+			}
+
+			// The first episode provides the episode details
+			if i == 0 {
+				setTvMazeEpisode(metadata, episode)
+			}
+			titles = append(titles, episode.Name)
 		}
 
-		err = json.Unmarshal(episodeBody, &episode)
-		if err != nil {
-			// Return show info without episode details
-			return metadata, nil
+		setEpisodeTitles(metadata, search, titles)
+
+	case search.AirDate != "":
+		// Daily shows are looked up by air date
+		metadata.AirDate = search.AirDate
+		var episodes []TvMazeEpisode
+		episodesURL := fmt.Sprintf("%s/shows/%d/episodesbydate?date=%s", p.baseURL, showID, url.QueryEscape(search.AirDate))
+		if err := p.getJSON(episodesURL, &episodes); err == nil && len(episodes) > 0 {
+			setTvMazeEpisode(metadata, &episodes[0])
+			metadata.EpisodeTitle = episodes[0].Name
 		}
 
-		// Add episode details to metadata
-		metadata.Season = episode.Season
-		metadata.Episode = episode.Number
-		metadata.EpisodeTitle = episode.Name
-		metadata.AirDate = episode.Airdate
+	case search.AbsoluteEpisode > 0:
+		// Absolute numbers count the regular episodes of all seasons in airing order
+		metadata.AbsoluteEpisode = search.AbsoluteEpisode
+		var episodes []TvMazeEpisode
+		episodesURL := fmt.Sprintf("%s/shows/%d/episodes", p.baseURL, showID)
+		if err := p.getJSON(episodesURL, &episodes); err == nil && search.AbsoluteEpisode <= len(episodes) {
+			episode := &episodes[search.AbsoluteEpisode-1]
+			setTvMazeEpisode(metadata, episode)
+			metadata.EpisodeTitle = episode.Name
+		}
 	}
 
 	return metadata, nil
 }
 
+// setTvMazeEpisode copies the details of an episode into the metadata
+func setTvMazeEpisode(metadata *TVShowMetadata, episode *TvMazeEpisode) {
+	metadata.Season = episode.Season
+	metadata.Episode = episode.Number
+	metadata.AirDate = episode.Airdate
+}
+
+// findShowID returns the TvMaze ID of the show. Picked shows are used
+// directly and shows with an IMDb ID from the embedded tags are looked up by
+// it; otherwise the best ranked search result for the title is taken.
+func (p *TvMazeProvider) findShowID(search TVShowSearch) (int, error) {
+	if search.Provider == string(config.ProviderTVMaze) {
+		return strconv.Atoi(search.ProviderID)
+	}
+	if search.IMDbID != "" {
+		var show TvMazeShow
+		lookupURL := fmt.Sprintf("%s/lookup/shows?imdb=%s", p.baseURL, url.QueryEscape(search.IMDbID))
+		if err := p.getJSON(lookupURL, &show); err == nil && show.ID > 0 {
+			return show.ID, nil
+		}
+		// Unknown IDs fall back to searching by title
+	}
+
+	candidates, err := p.TVShowCandidates(search, "", 1)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(candidates[0].ID)
+}
+
+// TVShowCandidates searches TvMaze for the show and ranks the results.
+// TvMaze does not filter by year, so the year only takes part in the ranking.
+func (p *TvMazeProvider) TVShowCandidates(search TVShowSearch, language string, limit int) ([]Candidate, error) {
+	// TvMaze has no languages, so searches in any language are the same
+	key := candidateSearch{kind: "series", query: tvShowQuery(search)}
+	return p.searches.ranked(key, limit, func() ([]Candidate, error) {
+		return p.searchShows(search)
+	})
+}
+
+// searchShows searches TvMaze for the show and ranks all results.
+func (p *TvMazeProvider) searchShows(search TVShowSearch) ([]Candidate, error) {
+	// Construct search URL
+	query := url.QueryEscape(search.Title)
+	searchURL := fmt.Sprintf("%s/search/shows?q=%s", p.baseURL, query)
+
+	// Make HTTP request
+	resp, err := p.client.Get(searchURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search TV show: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to search TV show: %s", resp.Status)
+	}
+
+	// Parse response
+	var searchResults []TvMazeSearchResult
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	err = json.Unmarshal(body, &searchResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	if len(searchResults) == 0 {
+		return nil, fmt.Errorf("no TV shows found matching '%s'", search.Title)
+	}
+
+	candidates := make([]Candidate, 0, len(searchResults))
+	for _, result := range searchResults {
+		candidates = append(candidates, Candidate{
+			ID:         strconv.Itoa(result.Show.ID),
+			Provider:   string(config.ProviderTVMaze),
+			Title:      result.Show.Name,
+			Year:       dateYear(result.Show.Premiered),
+			Overview:   cleanHtmlTags(result.Show.Summary),
+			Network:    result.Show.Network.Name,
+			Runtime:    result.Show.Runtime,
+			Popularity: float64(result.Show.Weight),
+		})
+	}
+	return RankCandidates(tvShowQuery(search), candidates, 0), nil
+}
+
+// getEpisode fetches a single episode of a show by season and episode number
+func (p *TvMazeProvider) getEpisode(showID, season, number int) (*TvMazeEpisode, error) {
+	episodeURL := fmt.Sprintf("%s/shows/%d/episodebynumber?season=%d&number=%d", p.baseURL, showID, season, number)
+	var episode TvMazeEpisode
+	if err := p.getJSON(episodeURL, &episode); err != nil {
+		return nil, err
+	}
+	return &episode, nil
+}
+
+// getSpecials fetches the specials of a show in airing order. TvMaze lists
+// them among the regular episodes, but without an episode number.
+func (p *TvMazeProvider) getSpecials(showID int) ([]TvMazeEpisode, error) {
+	var episodes []TvMazeEpisode
+	episodesURL := fmt.Sprintf("%s/shows/%d/episodes?specials=1", p.baseURL, showID)
+	if err := p.getJSON(episodesURL, &episodes); err != nil {
+		return nil, err
+	}
+	var specials []TvMazeEpisode
+	for _, episode := range episodes {
+		if episode.Number == 0 {
+			specials = append(specials, episode)
+		}
+	}
+	return specials, nil
+}
+
+// getJSON fetches a TvMaze API URL and decodes the JSON response into v
+func (p *TvMazeProvider) getJSON(apiURL string, v interface{}) error {
+	resp, err := p.client.Get(apiURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	return nil
+}
+
 // Helper function to clean HTML tags from text
 func cleanHtmlTags(html string) string {
 	// Remove the <p> and </p> tags that are common in TvMaze responses
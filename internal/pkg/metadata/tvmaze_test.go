@@ -3,6 +3,7 @@ package metadata
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -80,6 +81,27 @@ func TestExtractTVShowInfo(t *testing.T) {
 				EpisodeTitle: "",
 			},
 		},
+		{
+			name:     "Scene release tags",
+			filename: "The.Office.US.S02E03.The.Fire.720p.NF.WEB-DL.DDP5.1.x264-NTb.mkv",
+			want: TVShowSearch{
+				Title:        "The Office US",
+				Season:       2,
+				Episode:      3,
+				EpisodeTitle: "The Fire",
+			},
+		},
+		{
+			name:     "Show and season from directories",
+			filename: "TV/Breaking Bad (2008)/Season 01/05 - Gray Matter.mkv",
+			want: TVShowSearch{
+				Title:        "Breaking Bad",
+				Year:         2008,
+				Season:       1,
+				Episode:      5,
+				EpisodeTitle: "Gray Matter",
+			},
+		},
 		{
 			name:     "No TV pattern",
 			filename: "Breaking Bad.mp4",
@@ -87,6 +109,26 @@ func TestExtractTVShowInfo(t *testing.T) {
 				Title: "Breaking Bad",
 			},
 		},
+		{
+			name:     "Special in season 0",
+			filename: "Doctor.Who.S00E05.The.Christmas.Invasion.mkv",
+			want: TVShowSearch{
+				Title:        "Doctor Who",
+				Special:      true,
+				Episode:      5,
+				EpisodeTitle: "The Christmas Invasion",
+			},
+		},
+		{
+			name:     "Special in specials directory",
+			filename: "/tv/Doctor Who/Specials/05 - The Christmas Invasion.mkv",
+			want: TVShowSearch{
+				Title:        "Doctor Who",
+				Special:      true,
+				Episode:      5,
+				EpisodeTitle: "The Christmas Invasion",
+			},
+		},
 		{
 			name:     "With year in dots (should not extract year)",
 			filename: "Breaking.Bad.2008.S01E05.mp4",
@@ -111,6 +153,9 @@ func TestExtractTVShowInfo(t *testing.T) {
 			if got.Season != tt.want.Season {
 				t.Errorf("ExtractTVShowInfo() season = %v, want %v", got.Season, tt.want.Season)
 			}
+			if got.Special != tt.want.Special {
+				t.Errorf("ExtractTVShowInfo() special = %v, want %v", got.Special, tt.want.Special)
+			}
 			if got.Episode != tt.want.Episode {
 				t.Errorf("ExtractTVShowInfo() episode = %v, want %v", got.Episode, tt.want.Episode)
 			}
@@ -150,7 +195,7 @@ func TestTvMazeProvider_SearchTVShow(t *testing.T) {
 					}
 				}
 			]`))
-		case r.URL.Path == "/shows/169" && strings.Contains(r.URL.RawQuery, "embed=seasons"):
+		case r.URL.Path == "/shows/169" && strings.Contains(r.URL.RawQuery, "seasons"):
 			// Return show details with seasons
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
@@ -303,3 +348,137 @@ func TestTvMazeProvider_SearchTVShow(t *testing.T) {
 		})
 	}
 }
+
+func TestTvMazeProvider_SearchTVShowSpecial(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/search/shows":
+			w.Write([]byte(`[{"score": 0.9, "show": {"id": 210, "name": "Doctor Who", "premiered": "2005-03-26"}}]`))
+		case r.URL.Path == "/shows/210":
+			w.Write([]byte(`{"id": 210, "name": "Doctor Who", "premiered": "2005-03-26"}`))
+		case r.URL.Path == "/shows/210/episodes" && strings.Contains(r.URL.RawQuery, "specials=1"):
+			// Specials are listed among the regular episodes without a number
+			w.Write([]byte(`[
+				{"id": 1, "name": "Rose", "season": 1, "number": 1, "airdate": "2005-03-26", "type": "regular"},
+				{"id": 2, "name": "Children in Need Special", "season": 2, "number": null, "airdate": "2005-11-18", "type": "insignificant_special"},
+				{"id": 3, "name": "The Christmas Invasion", "season": 2, "number": null, "airdate": "2005-12-25", "type": "significant_special"},
+				{"id": 4, "name": "New Earth", "season": 2, "number": 1, "airdate": "2006-04-15", "type": "regular"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	provider := &TvMazeProvider{
+		baseURL: mockServer.URL,
+		client:  mockServer.Client(),
+	}
+
+	got, err := provider.SearchTVShow(TVShowSearch{Title: "Doctor Who", Special: true, Episode: 2}, "en")
+	if err != nil {
+		t.Fatalf("TvMazeProvider.SearchTVShow() error = %v", err)
+	}
+	if got.Season != 0 || got.Episode != 2 {
+		t.Errorf("TvMazeProvider.SearchTVShow() = S%02dE%02d, want S00E02", got.Season, got.Episode)
+	}
+	if got.EpisodeTitle != "The Christmas Invasion" {
+		t.Errorf("TvMazeProvider.SearchTVShow() episodeTitle = %v, want %v", got.EpisodeTitle, "The Christmas Invasion")
+	}
+	if got.AirDate != "2005-12-25" {
+		t.Errorf("TvMazeProvider.SearchTVShow() airDate = %v, want %v", got.AirDate, "2005-12-25")
+	}
+}
+
+func TestTvMazeProvider_SearchTVShowByIMDbID(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/lookup/shows" && r.URL.Query().Get("imdb") == "tt0903747":
+			w.Write([]byte(`{"id": 169, "name": "Breaking Bad", "premiered": "2008-01-20"}`))
+		case r.URL.Path == "/shows/169":
+			w.Write([]byte(`{"id": 169, "name": "Breaking Bad", "premiered": "2008-01-20"}`))
+		case r.URL.Path == "/shows/169/episodebynumber":
+			w.Write([]byte(`{"id": 1, "name": "Pilot", "season": 1, "number": 1, "airdate": "2008-01-20"}`))
+		default:
+			// Searching by title would find nothing
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	provider := &TvMazeProvider{
+		baseURL: mockServer.URL,
+		client:  mockServer.Client(),
+	}
+
+	got, err := provider.SearchTVShow(TVShowSearch{Title: "title_t00", Season: 1, Episode: 1, IMDbID: "tt0903747"}, "en")
+	if err != nil {
+		t.Fatalf("TvMazeProvider.SearchTVShow() error = %v", err)
+	}
+	if got.Title != "Breaking Bad" || got.EpisodeTitle != "Pilot" {
+		t.Errorf("TvMazeProvider.SearchTVShow() = %q - %q, want %q - %q", got.Title, got.EpisodeTitle, "Breaking Bad", "Pilot")
+	}
+}
+
+func TestTvMazeProvider_TVShowCandidates(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/search/shows" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[
+			{"score": 0.9, "show": {"id": 530, "name": "The Office", "premiered": "2001-07-09", "runtime": 30, "weight": 92, "network": {"name": "BBC Two"}}},
+			{"score": 0.9, "show": {"id": 526, "name": "The Office", "premiered": "2005-03-24", "runtime": 22, "weight": 99, "network": {"name": "NBC"}, "summary": "<p>A mockumentary.</p>"}}
+		]`))
+	}))
+	defer mockServer.Close()
+
+	provider := &TvMazeProvider{
+		baseURL: mockServer.URL,
+		client:  mockServer.Client(),
+	}
+
+	got, err := provider.TVShowCandidates(TVShowSearch{Title: "The Office", Year: 2005, Season: 1, Episode: 1}, "en", 0)
+	if err != nil {
+		t.Fatalf("TvMazeProvider.TVShowCandidates() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "526" || got[1].ID != "530" {
+		t.Fatalf("TvMazeProvider.TVShowCandidates() = %+v, want the NBC show first", got)
+	}
+	if got[0].Network != "NBC" || got[0].Year != 2005 || got[0].Overview != "A mockumentary." || got[0].Provider != "tvmaze" {
+		t.Errorf("TvMazeProvider.TVShowCandidates() first = %+v", got[0])
+	}
+}
+
+func TestTvMazeProvider_SearchTVShowPicked(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/shows/530":
+			w.Write([]byte(`{"id": 530, "name": "The Office", "premiered": "2001-07-09", "network": {"name": "BBC Two"},
+				"_embedded": {"akas": [{"name": "The Office UK"}]}}`))
+		case r.URL.Path == "/shows/530/episodebynumber":
+			w.Write([]byte(`{"id": 1, "name": "Downsize", "season": 1, "number": 1, "airdate": "2001-07-09"}`))
+		default:
+			// Searching by title would find nothing
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	provider := &TvMazeProvider{
+		baseURL: mockServer.URL,
+		client:  mockServer.Client(),
+	}
+
+	got, err := provider.SearchTVShow(TVShowSearch{Title: "The Office", Season: 1, Episode: 1, Provider: "tvmaze", ProviderID: "530"}, "en")
+	if err != nil {
+		t.Fatalf("TvMazeProvider.SearchTVShow() error = %v", err)
+	}
+	if got.ID != "530" || got.Network != "BBC Two" || got.EpisodeTitle != "Downsize" || !reflect.DeepEqual(got.AltTitles, []string{"The Office UK"}) {
+		t.Errorf("TvMazeProvider.SearchTVShow() = %+v, want Downsize of the picked show 530, also known as The Office UK", got)
+	}
+}
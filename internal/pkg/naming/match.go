@@ -0,0 +1,322 @@
+package naming
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tekenstam/vidkit/internal/pkg/media"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+// Matcher recognizes names rendered from a filename template and, optionally,
+// a directory template, and recovers the values of their placeholders. It
+// lets VidKit identify the files it named itself, whatever the templates.
+//
+// Only plain placeholders are recovered; placeholders with an index or
+// filters, such as {title[0]} or {genre|upper}, match any text.
+type Matcher struct {
+	filenameTemplate  string
+	directoryTemplate string
+	opts              Options
+
+	file *namePattern
+	dirs []*namePattern // Components of the directory template, outermost first
+}
+
+// namePattern matches a single name component and knows the placeholder
+// captured by each group.
+type namePattern struct {
+	re     *regexp.Regexp
+	fields []string
+}
+
+// wordBreakPattern matches a word break of a rendered name. Word breaks are
+// replaced by the separator and tidied up, so any amount of separator
+// characters is accepted, including none next to brackets.
+const wordBreakPattern = `[\s._-]*`
+
+// fieldPatterns lists the patterns of placeholders with a fixed form. Text
+// placeholders match lazily, so optional groups after them are preferred;
+// a year must end at a word break, so "1080p" is never taken for one.
+var fieldPatterns = map[string]string{
+	"year":             `\d{4}\b`,
+	"season":           `\d+`,
+	"episode":          `\d+`,
+	"absolute_episode": `\d+`,
+	"episode_range":    `(?i:S\d+E\d+(?:-?E\d+)*)`,
+	"air_date":         `\d{4}-\d{2}-\d{2}`,
+	"part":             `(?i:part\d+)`,
+	"title":            `.+?`,
+	"title_sort":       `.+?`,
+	"first_letter":     `.`,
+}
+
+// technicalFields lists placeholders holding single release tags, which
+// never contain a separator.
+var technicalFields = map[string]bool{
+	"resolution": true, "codec": true, "video_codec": true, "audio_codec": true,
+	"audio_channels": true, "video_bitrate": true, "bit_depth": true, "hdr": true,
+	"container": true, "duration": true, "filesize": true, "framerate": true,
+}
+
+// NewMatcher builds a matcher for names rendered from the templates with the
+// given options. The directory template may be empty to match filenames
+// only.
+func NewMatcher(filenameTemplate, directoryTemplate string, opts Options) (*Matcher, error) {
+	m := &Matcher{filenameTemplate: filenameTemplate, directoryTemplate: directoryTemplate, opts: opts}
+	var err error
+	if m.file, err = compileName(filenameTemplate, opts); err != nil {
+		return nil, err
+	}
+	for _, component := range strings.Split(directoryTemplate, "/") {
+		if component == "" {
+			continue
+		}
+		dir, err := compileName(component, opts)
+		if err != nil {
+			return nil, err
+		}
+		m.dirs = append(m.dirs, dir)
+	}
+	return m, nil
+}
+
+// compileName compiles the template of a single name component.
+func compileName(text string, opts Options) (*namePattern, error) {
+	t, err := Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// Release tags end at a separator or bracket
+	token := `[^\s` + regexp.QuoteMeta(opts.separator()) + `\[\](){}]+`
+
+	p := &namePattern{}
+	var sb strings.Builder
+	if opts.Lowercase {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("^")
+	p.writeNodes(&sb, t.nodes, token)
+	sb.WriteString("$")
+
+	if p.re, err = regexp.Compile(sb.String()); err != nil {
+		return nil, fmt.Errorf("template %q: %v", text, err)
+	}
+	return p, nil
+}
+
+// writeNodes writes the pattern of a node list. Optional groups become
+// optional in the pattern too.
+func (p *namePattern) writeNodes(sb *strings.Builder, nodes []node, token string) {
+	for _, n := range nodes {
+		switch {
+		case n.placeholder != nil:
+			p.writePlaceholder(sb, n.placeholder, token)
+		case n.group != nil:
+			sb.WriteString("(?:")
+			p.writeNodes(sb, n.group, token)
+			sb.WriteString(")?")
+		default:
+			writeLiteral(sb, n.literal)
+		}
+	}
+}
+
+func (p *namePattern) writePlaceholder(sb *strings.Builder, ph *placeholder, token string) {
+	pattern, ok := fieldPatterns[ph.name]
+	switch {
+	case technicalFields[ph.name]:
+		pattern = token
+	case !ok:
+		pattern = `.*?`
+	}
+	if ph.index >= 0 || len(ph.filters) > 0 {
+		sb.WriteString("(?:" + pattern + ")")
+		return
+	}
+	sb.WriteString("(" + pattern + ")")
+	p.fields = append(p.fields, ph.name)
+}
+
+// writeLiteral writes the pattern of literal template text.
+func writeLiteral(sb *strings.Builder, literal string) {
+	last := 0
+	for _, loc := range wordBreak.FindAllStringIndex(literal, -1) {
+		sb.WriteString(regexp.QuoteMeta(literal[last:loc[0]]))
+		sb.WriteString(wordBreakPattern)
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(literal[last:]))
+}
+
+// match matches a name component and adds the recovered values. It fails
+// when a value differs from one recovered from another component.
+func (p *namePattern) match(name, separator string, values Values) bool {
+	m := p.re.FindStringSubmatch(name)
+	if m == nil {
+		return false
+	}
+	for i, field := range p.fields {
+		value := parseValue(field, m[i+1], separator)
+		if value == nil {
+			continue
+		}
+		if existing, ok := values[field]; ok {
+			if !strings.EqualFold(formatValue(existing), formatValue(value)) {
+				return false
+			}
+			continue
+		}
+		values[field] = value
+	}
+	return true
+}
+
+// parseValue converts matched text back into a template value, or nil for
+// empty text.
+func parseValue(field, text, separator string) interface{} {
+	if text = strings.Trim(text, " ._-"); text == "" {
+		return nil
+	}
+	switch field {
+	case "year", "season", "episode", "absolute_episode":
+		n, _ := strconv.Atoi(text)
+		return n
+	}
+	if separator != "" && separator != " " && !technicalFields[field] {
+		text = strings.ReplaceAll(text, separator, " ")
+	}
+	return text
+}
+
+// Match matches the path of a file against the templates and returns the
+// recovered values. With a directory template, the directories above the
+// file must match it too and agree with the filename.
+//
+// A name matches only when rendering the templates with the recovered values
+// gives the name back, with the technical values taken from info when it is
+// not nil. Release names that merely resemble a template, such as a scene
+// release for the scene preset, do not match as their tags differ from those
+// of the file.
+func (m *Matcher) Match(path string, info *media.VideoInfo) (Values, bool) {
+	separator := m.opts.separator()
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	values := Values{}
+	if !m.file.match(strings.TrimSuffix(base, ext), separator, values) {
+		return nil, false
+	}
+
+	var components []string
+	if len(m.dirs) > 0 {
+		components = strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+		if len(components) < len(m.dirs) {
+			return nil, false
+		}
+		components = components[len(components)-len(m.dirs):]
+		for i, dir := range m.dirs {
+			if !dir.match(components[i], separator, values) {
+				return nil, false
+			}
+		}
+	}
+
+	rendered := make(Values, len(values))
+	for field, value := range values {
+		if info == nil || !technicalFields[field] {
+			rendered[field] = value
+		}
+	}
+	if info != nil {
+		for field, value := range technicalValues(info) {
+			rendered[field] = value
+		}
+	}
+	dir, filename, err := Render(m.filenameTemplate, m.directoryTemplate, ext, rendered, m.opts)
+	if err != nil || filename != base {
+		return nil, false
+	}
+	if len(m.dirs) > 0 && filepath.ToSlash(dir) != strings.Join(components, "/") && !strings.HasSuffix(filepath.ToSlash(dir), "/"+strings.Join(components, "/")) {
+		return nil, false
+	}
+	return values, true
+}
+
+// episodeRangePattern parses the episode range of a matched name: S01E01-E03, S01E01E02
+var episodeRangePattern = regexp.MustCompile(`(?i)^S(\d+)E(\d+)((?:-?E\d+)*)$`)
+
+// MovieSearch returns the movie described by values recovered from a
+// name, the inverse of MovieValues. The search is empty unless the values
+// name both the title and the year.
+func MovieSearch(values Values) metadata.MovieSearch {
+	title, _ := values["title"].(string)
+	year, _ := values["year"].(int)
+	if title == "" || year == 0 {
+		return metadata.MovieSearch{}
+	}
+	search := metadata.MovieSearch{Title: title, Year: year, Confidence: 1}
+	search.Edition, _ = values["edition"].(string)
+	if part, ok := values["part"].(string); ok {
+		search.Part, _ = strconv.Atoi(part[len("part"):])
+	}
+	return search
+}
+
+// TVShowSearch returns the episode described by values recovered from a
+// name, the inverse of TVValues. The search is empty unless the values name
+// the show and identify an episode.
+func TVShowSearch(values Values) metadata.TVShowSearch {
+	search := metadata.TVShowSearch{Confidence: 1}
+	search.Title, _ = values["title"].(string)
+	search.Year, _ = values["year"].(int)
+	search.Season, _ = values["season"].(int)
+	search.Episode, _ = values["episode"].(int)
+	search.AbsoluteEpisode, _ = values["absolute_episode"].(int)
+	search.AirDate, _ = values["air_date"].(string)
+	search.EpisodeTitle, _ = values["episode_title"].(string)
+
+	season, hasSeason := values["season"]
+	if r, ok := values["episode_range"].(string); ok {
+		if m := episodeRangePattern.FindStringSubmatch(r); m != nil {
+			search.Season, _ = strconv.Atoi(m[1])
+			if hasSeason && season != search.Season {
+				// A season directory that does not hold the episode
+				return metadata.TVShowSearch{}
+			}
+			search.Episode, _ = strconv.Atoi(m[2])
+			search.Episodes = episodeRangeNumbers(search.Episode, m[3])
+			hasSeason = true
+		}
+	}
+	search.Special = hasSeason && search.Season == 0
+
+	if search.Title == "" || !search.IsEpisode() {
+		return metadata.TVShowSearch{}
+	}
+	return search
+}
+
+// episodeRangeNumbers returns the episodes of a multi-episode range following
+// the first episode: "-E03" is a range, "E02E04" a list. It returns nil for
+// single episodes.
+func episodeRangeNumbers(first int, rest string) []int {
+	if rest == "" {
+		return nil
+	}
+	episodes := []int{first}
+	if strings.HasPrefix(rest, "-") {
+		last, _ := strconv.Atoi(strings.TrimLeft(rest, "-Ee"))
+		for e := first + 1; e <= last; e++ {
+			episodes = append(episodes, e)
+		}
+		return episodes
+	}
+	for _, e := range strings.FieldsFunc(strings.ToUpper(rest), func(r rune) bool { return r == 'E' }) {
+		n, _ := strconv.Atoi(e)
+		episodes = append(episodes, n)
+	}
+	return episodes
+}
@@ -0,0 +1,171 @@
+package naming
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tekenstam/vidkit/internal/pkg/media"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+func TestMatcherMatch(t *testing.T) {
+	info := &media.VideoInfo{}
+	info.Streams = []media.Stream{{CodecType: "video", CodecName: "h264", Width: 1920, Height: 1080}}
+
+	tests := []struct {
+		name              string
+		filenameTemplate  string
+		directoryTemplate string
+		opts              Options
+		path              string
+		want              Values // nil when the path should not match
+	}{
+		{
+			name:             "Custom TV template",
+			filenameTemplate: "{title} - {season}x{episode:02d} - {episode_title}",
+			path:             "/tv/Breaking Bad - 1x02 - Cat's in the Bag.mkv",
+			want:             Values{"title": "Breaking Bad", "season": 1, "episode": 2, "episode_title": "Cat's in the Bag"},
+		},
+		{
+			name:             "Missing episode title",
+			filenameTemplate: "{title} - {season}x{episode:02d} - {episode_title}",
+			path:             "/tv/Breaking Bad - 1x02.mkv",
+			want:             Values{"title": "Breaking Bad", "season": 1, "episode": 2},
+		},
+		{
+			name:             "Number in the title",
+			filenameTemplate: "{title} ({year})< - {part}>",
+			path:             "Blade Runner 2049 (2017) - part2.mkv",
+			want:             Values{"title": "Blade Runner 2049", "year": 2017, "part": "part2"},
+		},
+		{
+			name:             "Technical values from the file",
+			filenameTemplate: "{title} ({year}) [{resolution} {codec}]",
+			path:             "The Matrix (1999) [1080p h264].mkv",
+			want:             Values{"title": "The Matrix", "year": 1999, "resolution": "1080p", "codec": "h264"},
+		},
+		{
+			name:             "Technical values differ from the file",
+			filenameTemplate: "{title} ({year}) [{resolution} {codec}]",
+			path:             "The Matrix (1999) [720p H264].mkv",
+		},
+		{
+			name:              "Directory template",
+			filenameTemplate:  "{title} {episode_range}< - {episode_title}>",
+			directoryTemplate: "TV Shows/{title}< ({year})>/Season {season:02d}",
+			path:              "/media/TV Shows/Lost (2004)/Season 01/Lost S01E01-E02 - Pilot.mkv",
+			want:              Values{"title": "Lost", "year": 2004, "season": 1, "episode_range": "S01E01-E02", "episode_title": "Pilot"},
+		},
+		{
+			name:              "Directory disagrees with the filename",
+			filenameTemplate:  "{title} {episode_range}< - {episode_title}>",
+			directoryTemplate: "TV Shows/{title}< ({year})>/Season {season:02d}",
+			path:              "/media/TV Shows/Lost (2004)/Season 01/Lost Girl S01E01 - Pilot.mkv",
+		},
+		{
+			name:              "Not in the template directory",
+			filenameTemplate:  "{title} ({year})",
+			directoryTemplate: "Movies/{title} ({year})",
+			path:              "/downloads/The Matrix (1999).mkv",
+		},
+		{
+			name:             "Scene style",
+			filenameTemplate: "{title} {year}< {edition}>< {resolution}>< {video_codec}>",
+			opts:             Options{SceneStyle: true},
+			path:             "Blade.Runner.1982.Final.Cut.1080p.AVC.mkv",
+			want:             Values{"title": "Blade Runner", "year": 1982, "edition": "Final Cut", "resolution": "1080p", "video_codec": "AVC"},
+		},
+		{
+			name:             "Scene release resembling the template",
+			filenameTemplate: "{title} {year}< {edition}>< {resolution}>< {video_codec}>",
+			opts:             Options{SceneStyle: true},
+			path:             "The.Matrix.1999.1080p.BluRay.x264-GRP.mkv",
+		},
+		{
+			name:             "Lowercase",
+			filenameTemplate: "{title} ({year})",
+			opts:             Options{Lowercase: true},
+			path:             "the matrix (1999).mkv",
+			want:             Values{"title": "the matrix", "year": 1999},
+		},
+		{
+			name:             "Unrelated name",
+			filenameTemplate: "{title} - {season}x{episode:02d} - {episode_title}",
+			path:             "The.Matrix.1999.1080p.mkv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher(tt.filenameTemplate, tt.directoryTemplate, tt.opts)
+			if err != nil {
+				t.Fatalf("NewMatcher() error = %v", err)
+			}
+			got, ok := m.Match(tt.path, info)
+			if ok != (tt.want != nil) {
+				t.Fatalf("Match(%q) = %v, %v, want match %v", tt.path, got, ok, tt.want != nil)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchedSearches(t *testing.T) {
+	movie := MovieSearch(Values{"title": "Old Movie", "year": 2004, "edition": "Extended", "part": "part2"})
+	if want := (metadata.MovieSearch{Title: "Old Movie", Year: 2004, Edition: "Extended", Part: 2, Confidence: 1}); movie != want {
+		t.Errorf("MovieSearch() = %+v, want %+v", movie, want)
+	}
+	if movie := MovieSearch(Values{"title": "Old Movie"}); movie.Title != "" {
+		t.Errorf("MovieSearch() without year = %+v, want an empty search", movie)
+	}
+
+	tests := []struct {
+		name   string
+		values Values
+		want   metadata.TVShowSearch
+	}{
+		{
+			name:   "Season and episode",
+			values: Values{"title": "Lost", "season": 1, "episode": 2, "episode_title": "Pilot (2)"},
+			want:   metadata.TVShowSearch{Title: "Lost", Season: 1, Episode: 2, EpisodeTitle: "Pilot (2)", Confidence: 1},
+		},
+		{
+			name:   "Episode range",
+			values: Values{"title": "Lost", "episode_range": "S01E01-E03"},
+			want:   metadata.TVShowSearch{Title: "Lost", Season: 1, Episode: 1, Episodes: []int{1, 2, 3}, Confidence: 1},
+		},
+		{
+			name:   "Episode list",
+			values: Values{"title": "Lost", "episode_range": "S01E01E04"},
+			want:   metadata.TVShowSearch{Title: "Lost", Season: 1, Episode: 1, Episodes: []int{1, 4}, Confidence: 1},
+		},
+		{
+			name:   "Special",
+			values: Values{"title": "Doctor Who", "episode_range": "S00E05"},
+			want:   metadata.TVShowSearch{Title: "Doctor Who", Special: true, Episode: 5, Confidence: 1},
+		},
+		{
+			name:   "Air date",
+			values: Values{"title": "The Daily Show", "air_date": "2024-03-14"},
+			want:   metadata.TVShowSearch{Title: "The Daily Show", AirDate: "2024-03-14", Confidence: 1},
+		},
+		{
+			name:   "Season directory disagrees with the episode range",
+			values: Values{"title": "Lost", "season": 2, "episode_range": "S01E01"},
+		},
+		{
+			name:   "No episode",
+			values: Values{"title": "Lost", "year": 2004},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TVShowSearch(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TVShowSearch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package naming
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tekenstam/vidkit/internal/pkg/media"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+// Options controls the post-processing applied to rendered names.
+type Options struct {
+	Separator  string     // Word separator; a space keeps names unchanged
+	SceneStyle bool       // Apply scene naming rules (dots by default)
+	Lowercase  bool       // Convert the result to lowercase
+	Sanitizer  *Sanitizer // Target filesystem rules (posix when nil)
+	Articles   []string   // Leading articles ignored by {title_sort} and {first_letter} (English when nil)
+}
+
+// MovieValues builds the template values for a movie from its metadata and
+// the technical information of the video file. Unknown values are left out so
+// that optional template groups referencing them collapse.
+func MovieValues(movie *metadata.MovieMetadata, info *media.VideoInfo) Values {
+	values := technicalValues(info)
+	values["title"] = movie.Title
+	setInt(values, "year", movie.Year)
+	values["edition"] = movie.Edition
+	if movie.Part > 0 {
+		values["part"] = fmt.Sprintf("part%d", movie.Part)
+	}
+	values["genres"] = movie.Genres
+	values["genre"] = primaryGenre(movie.Genres)
+	return values
+}
+
+// TVValues builds the template values for a TV episode from its metadata and
+// the technical information of the video file. Season and episode are always
+// set, other unknown values are left out.
+func TVValues(show *metadata.TVShowMetadata, info *media.VideoInfo) Values {
+	values := technicalValues(info)
+	values["title"] = show.Title
+	setInt(values, "year", show.Year)
+	values["season"] = show.Season
+	values["episode"] = show.Episode
+	setString(values, "episode_range", episodeRange(show))
+	setInt(values, "absolute_episode", show.AbsoluteEpisode)
+	values["air_date"] = show.AirDate
+	values["episode_title"] = show.EpisodeTitle
+	values["network"] = show.Network
+	values["genres"] = show.Genres
+	values["genre"] = primaryGenre(show.Genres)
+	return values
+}
+
+// episodeRange formats the SxxEyy marker of all episodes in the file. It is
+// empty when the episode is unknown, such as for a daily show whose air date
+// could not be matched to an episode.
+func episodeRange(show *metadata.TVShowMetadata) string {
+	episodes := show.EpisodeNumbers()
+	if len(episodes) == 0 {
+		return ""
+	}
+	return metadata.FormatEpisodeRange(show.Season, episodes)
+}
+
+// setInt stores n under key unless it is zero.
+func setInt(values Values, key string, n int) {
+	if n != 0 {
+		values[key] = n
+	}
+}
+
+func primaryGenre(genres []string) string {
+	if len(genres) > 0 {
+		return genres[0]
+	}
+	return "Unknown"
+}
+
+// Render expands a filename template and an optional directory template.
+// It returns the resulting relative directory (empty when no directory template
+// is given) and the filename with ext appended. Values are sanitized for the
+// target filesystem before they are substituted, so a "/" in a title can never
+// introduce an extra directory level. The sort title and first letter are
+// derived from the title using the articles in opts.
+func Render(filenameTemplate, directoryTemplate, ext string, values Values, opts Options) (dir, filename string, err error) {
+	sanitizer := opts.Sanitizer
+	if sanitizer == nil {
+		sanitizer, _ = NewSanitizer("posix", "")
+	}
+	values = sanitizeValues(addSortValues(values, opts.Articles), sanitizer)
+
+	tmpl, err := Parse(filenameTemplate)
+	if err != nil {
+		return "", "", err
+	}
+	name, err := tmpl.Execute(values)
+	if err != nil {
+		return "", "", err
+	}
+	filename = sanitizer.Filename(opts.apply(Tidy(name)), ext)
+
+	if directoryTemplate != "" {
+		dirTmpl, err := Parse(directoryTemplate)
+		if err != nil {
+			return "", "", err
+		}
+		dir, err = dirTmpl.Execute(values)
+		if err != nil {
+			return "", "", err
+		}
+		components := strings.Split(dir, "/")
+		for i, component := range components {
+			components[i] = sanitizer.Component(opts.apply(Tidy(component)))
+		}
+		dir = filepath.Join(components...)
+	}
+	return dir, filename, nil
+}
+
+// sanitizeValues returns a copy of values with every string made safe for the
+// target filesystem.
+func sanitizeValues(values Values, sanitizer *Sanitizer) Values {
+	sanitized := make(Values, len(values))
+	for key, value := range values {
+		switch v := value.(type) {
+		case string:
+			sanitized[key] = sanitizer.Value(v)
+		case []string:
+			list := make([]string, len(v))
+			for i, item := range v {
+				list[i] = sanitizer.Value(item)
+			}
+			sanitized[key] = list
+		default:
+			sanitized[key] = value
+		}
+	}
+	return sanitized
+}
+
+var (
+	emptyBrackets     = regexp.MustCompile(`\(\s*\)|\[\s*\]|\{\s*\}`)
+	spaceAfterOpen    = regexp.MustCompile(`([(\[{])\s+`)
+	spaceBeforeClose  = regexp.MustCompile(`\s+([)\]}])`)
+	repeatedDashes    = regexp.MustCompile(`(\s+-)+\s+`)
+	whitespaceRun     = regexp.MustCompile(`\s+`)
+	danglingSeparator = " -_,"
+)
+
+// Tidy cleans up a rendered name component: it removes brackets left empty by
+// missing values, collapses repeated " - " separators and whitespace, and
+// trims separators dangling at either end.
+//
+// Example:
+//
+//	naming.Tidy("Title () [1080p ] -  - ") // "Title [1080p]"
+func Tidy(s string) string {
+	for {
+		cleaned := emptyBrackets.ReplaceAllString(s, "")
+		if cleaned == s {
+			break
+		}
+		s = cleaned
+	}
+	s = spaceAfterOpen.ReplaceAllString(s, "$1")
+	s = spaceBeforeClose.ReplaceAllString(s, "$1")
+	s = repeatedDashes.ReplaceAllString(s, " - ")
+	s = whitespaceRun.ReplaceAllString(s, " ")
+	return strings.Trim(s, danglingSeparator)
+}
+
+// apply performs the separator and casing adjustments selected in opts.
+func (o Options) apply(s string) string {
+	s = ApplySeparator(s, o.separator(), o.SceneStyle)
+	if o.Lowercase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// separator returns the word separator of rendered names: dots for scene
+// style unless another separator is chosen.
+func (o Options) separator() string {
+	if o.SceneStyle && (o.Separator == "" || o.Separator == " ") {
+		return "."
+	}
+	return o.Separator
+}
@@ -0,0 +1,225 @@
+package naming
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tekenstam/vidkit/internal/pkg/media"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+func testVideoInfo() *media.VideoInfo {
+	info := &media.VideoInfo{}
+	info.Streams = append(info.Streams, media.Stream{
+		CodecType: "video",
+		CodecName: "h264",
+		Width:     1920,
+		Height:    1080,
+	})
+	return info
+}
+
+func TestRender(t *testing.T) {
+	movie := &metadata.MovieMetadata{
+		Title:  "The Matrix",
+		Year:   1999,
+		Genres: []string{"Action", "Science Fiction"},
+	}
+	show := &metadata.TVShowMetadata{
+		Title:        "Breaking Bad",
+		Year:         2008,
+		Season:       1,
+		Episode:      5,
+		EpisodeTitle: "Gray Matter",
+		Network:      "AMC",
+	}
+
+	tests := []struct {
+		name      string
+		filename  string
+		directory string
+		values    Values
+		opts      Options
+		wantDir   string
+		wantName  string
+	}{
+		{
+			name:     "Default movie template",
+			filename: "{title} ({year}) [{resolution} {codec}]",
+			values:   MovieValues(movie, testVideoInfo()),
+			wantName: "The Matrix (1999) [1080p h264].mkv",
+		},
+		{
+			name:      "Movie with directory",
+			filename:  "{title} ({year})",
+			directory: "Movies/{genre}/{title[0]}",
+			values:    MovieValues(movie, testVideoInfo()),
+			wantDir:   filepath.Join("Movies", "Action", "T"),
+			wantName:  "The Matrix (1999).mkv",
+		},
+		{
+			name:     "Default TV template",
+			filename: "{title} - S{season:02d}E{episode:02d} - {episode_title}",
+			values:   TVValues(show, testVideoInfo()),
+			wantName: "Breaking Bad - S01E05 - Gray Matter.mkv",
+		},
+		{
+			name:      "TV with genre fallback and scene style",
+			filename:  "{title} S{season:02d}E{episode:02d}",
+			directory: "TV/{genre}/{network}",
+			values:    TVValues(show, testVideoInfo()),
+			opts:      Options{SceneStyle: true, Lowercase: true},
+			wantDir:   filepath.Join("tv", "unknown", "amc"),
+			wantName:  "breaking.bad.s01e05.mkv",
+		},
+		{
+			name:      "Custom separator in filename and directory",
+			filename:  "{title} ({year})",
+			directory: "Movies/{title} ({year})",
+			values:    MovieValues(movie, nil),
+			opts:      Options{Separator: "_"},
+			wantDir:   filepath.Join("Movies", "The_Matrix_(1999)"),
+			wantName:  "The_Matrix_(1999).mkv",
+		},
+		{
+			name:     "No video stream",
+			filename: "{title} [{resolution} {codec}]",
+			values:   MovieValues(movie, &media.VideoInfo{}),
+			wantName: "The Matrix.mkv",
+		},
+		{
+			name:     "Missing year and episode title",
+			filename: "{title} ({year}) - S{season:02d}E{episode:02d} - {episode_title}",
+			values:   TVValues(&metadata.TVShowMetadata{Title: "Breaking Bad", Season: 1, Episode: 5}, nil),
+			wantName: "Breaking Bad - S01E05.mkv",
+		},
+		{
+			name:     "Single episode range",
+			filename: "{title} - {episode_range} - {episode_title}",
+			values:   TVValues(show, nil),
+			wantName: "Breaking Bad - S01E05 - Gray Matter.mkv",
+		},
+		{
+			name:     "Multi-episode range",
+			filename: "{title} - {episode_range} - {episode_title}",
+			values: TVValues(&metadata.TVShowMetadata{
+				Title:         "Lost",
+				Season:        1,
+				Episode:       1,
+				Episodes:      []int{1, 2},
+				EpisodeTitle:  "Pilot",
+				EpisodeTitles: []string{"Pilot (1)", "Pilot (2)"},
+			}, nil),
+			wantName: "Lost - S01E01-E02 - Pilot.mkv",
+		},
+		{
+			name:     "Plex edition tag",
+			filename: "{title} ({year})< {{edition-{edition}}}>",
+			values:   MovieValues(&metadata.MovieMetadata{Title: "Blade Runner", Year: 1982, Edition: "Director's Cut"}, nil),
+			wantName: "Blade Runner (1982) {edition-Director's Cut}.mkv",
+		},
+		{
+			name:     "Jellyfin edition suffix",
+			filename: "{title} ({year})< - {edition}>",
+			values:   MovieValues(&metadata.MovieMetadata{Title: "Blade Runner", Year: 1982, Edition: "Final Cut"}, nil),
+			wantName: "Blade Runner (1982) - Final Cut.mkv",
+		},
+		{
+			name:     "Missing edition",
+			filename: "{title} ({year})< {{edition-{edition}}}>",
+			values:   MovieValues(movie, nil),
+			wantName: "The Matrix (1999).mkv",
+		},
+		{
+			name:     "Movie part",
+			filename: "{title} ({year})< - {part}>",
+			values:   MovieValues(&metadata.MovieMetadata{Title: "Old Movie", Year: 2004, Part: 2}, nil),
+			wantName: "Old Movie (2004) - part2.mkv",
+		},
+		{
+			name:     "Complete movie without part",
+			filename: "{title} ({year})< - {part}>",
+			values:   MovieValues(movie, nil),
+			wantName: "The Matrix (1999).mkv",
+		},
+		{
+			name:     "Daily show by air date",
+			filename: "{title} - {air_date}< - {episode_title}>",
+			values:   TVValues(&metadata.TVShowMetadata{Title: "The Daily Show", AirDate: "2024-03-14"}, nil),
+			wantName: "The Daily Show - 2024-03-14.mkv",
+		},
+		{
+			name:     "Absolute episode number",
+			filename: "{title} - {absolute_episode:03d} - {episode_range}",
+			values:   TVValues(&metadata.TVShowMetadata{Title: "One Piece", Season: 5, Episode: 13, AbsoluteEpisode: 143}, nil),
+			wantName: "One Piece - 143 - S05E13.mkv",
+		},
+		{
+			name:     "Unknown episode has no range",
+			filename: "{title} - {episode_range} - {air_date}",
+			values:   TVValues(&metadata.TVShowMetadata{Title: "The Daily Show", AirDate: "2024-03-14"}, nil),
+			wantName: "The Daily Show - 2024-03-14.mkv",
+		},
+		{
+			name:     "Optional groups",
+			filename: "{title}< ({year})>< - {episode_title}>",
+			values:   TVValues(&metadata.TVShowMetadata{Title: "Breaking Bad", Season: 1, Episode: 5}, nil),
+			wantName: "Breaking Bad.mkv",
+		},
+		{
+			name:      "Empty directory component groups",
+			filename:  "{title}",
+			directory: "Movies/{title}< ({year})>",
+			values:    MovieValues(&metadata.MovieMetadata{Title: "Heat"}, nil),
+			wantDir:   filepath.Join("Movies", "Heat"),
+			wantName:  "Heat.mkv",
+		},
+		{
+			name:      "Leading slash stays relative",
+			filename:  "{title}",
+			directory: "/Movies/{title}",
+			values:    MovieValues(&metadata.MovieMetadata{Title: "Heat"}, nil),
+			wantDir:   filepath.Join("Movies", "Heat"),
+			wantName:  "Heat.mkv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, name, err := Render(tt.filename, tt.directory, ".mkv", tt.values, tt.opts)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if dir != tt.wantDir {
+				t.Errorf("Render() dir = %q, want %q", dir, tt.wantDir)
+			}
+			if name != tt.wantName {
+				t.Errorf("Render() name = %q, want %q", name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestTidy(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Clean name unchanged", input: "The Matrix (1999) [1080p h264]", want: "The Matrix (1999) [1080p h264]"},
+		{name: "Empty parentheses", input: "The Matrix () [1080p h264]", want: "The Matrix [1080p h264]"},
+		{name: "Partially empty brackets", input: "The Matrix [1080p ]", want: "The Matrix [1080p]"},
+		{name: "Nested empty brackets", input: "Title [( )]", want: "Title"},
+		{name: "Dangling separator", input: "Breaking Bad - S01E05 - ", want: "Breaking Bad - S01E05"},
+		{name: "Repeated separators", input: "Show -  - Pilot", want: "Show - Pilot"},
+		{name: "Hyphenated words kept", input: "Spider-Man - Homecoming", want: "Spider-Man - Homecoming"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tidy(tt.input); got != tt.want {
+				t.Errorf("Tidy(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package naming
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tekenstam/vidkit/internal/pkg/config"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+func TestPresets(t *testing.T) {
+	movie := &metadata.MovieMetadata{Title: "Blade Runner", Year: 1982, Edition: "Final Cut"}
+	show := &metadata.TVShowMetadata{Title: "Lost", Year: 2004, Season: 1, Episode: 1, Episodes: []int{1, 2}, EpisodeTitle: "Pilot"}
+
+	tests := []struct {
+		preset       string
+		wantMovieDir string
+		wantMovie    string
+		wantTVDir    string
+		wantTV       string
+	}{
+		{
+			preset:       "plex",
+			wantMovieDir: filepath.Join("Movies", "Blade Runner (1982) {edition-Final Cut}"),
+			wantMovie:    "Blade Runner (1982) {edition-Final Cut}.mkv",
+			wantTVDir:    filepath.Join("TV Shows", "Lost (2004)", "Season 01"),
+			wantTV:       "Lost (2004) - S01E01-E02 - Pilot.mkv",
+		},
+		{
+			preset:       "jellyfin",
+			wantMovieDir: filepath.Join("Movies", "Blade Runner (1982)"),
+			wantMovie:    "Blade Runner (1982) - Final Cut.mkv",
+			wantTVDir:    filepath.Join("Shows", "Lost (2004)", "Season 01"),
+			wantTV:       "Lost S01E01-E02 - Pilot.mkv",
+		},
+		{
+			preset:       "emby",
+			wantMovieDir: filepath.Join("Movies", "Blade Runner (1982)"),
+			wantMovie:    "Blade Runner (1982) - Final Cut.mkv",
+			wantTVDir:    filepath.Join("TV Shows", "Lost (2004)", "Season 1"),
+			wantTV:       "Lost - S01E01-E02 - Pilot.mkv",
+		},
+		{
+			preset:       "kodi",
+			wantMovieDir: filepath.Join("Movies", "Blade Runner (1982)"),
+			wantMovie:    "Blade Runner (1982).mkv",
+			wantTVDir:    filepath.Join("TV Shows", "Lost (2004)", "Season 01"),
+			wantTV:       "Lost S01E01-E02 - Pilot.mkv",
+		},
+		{
+			preset:       "scene",
+			wantMovieDir: "Blade.Runner.1982.Final.Cut",
+			wantMovie:    "Blade.Runner.1982.Final.Cut.1080p.AVC.mkv",
+			wantTVDir:    filepath.Join("Lost", "Season.01"),
+			wantTV:       "Lost.S01E01-E02.Pilot.1080p.AVC.mkv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			if err := cfg.ApplyPreset(tt.preset); err != nil {
+				t.Fatalf("ApplyPreset() error = %v", err)
+			}
+			opts := Options{Separator: cfg.Separator, SceneStyle: cfg.SceneStyle, Lowercase: cfg.Lowercase}
+
+			dir, name, err := Render(cfg.MovieFilenameTemplate, cfg.MovieDirectoryTemplate, ".mkv", MovieValues(movie, testVideoInfo()), opts)
+			if err != nil {
+				t.Fatalf("Render(movie) error = %v", err)
+			}
+			if dir != tt.wantMovieDir || name != tt.wantMovie {
+				t.Errorf("Render(movie) = %q, %q, want %q, %q", dir, name, tt.wantMovieDir, tt.wantMovie)
+			}
+
+			dir, name, err = Render(cfg.TVFilenameTemplate, cfg.TVDirectoryTemplate, ".mkv", TVValues(show, testVideoInfo()), opts)
+			if err != nil {
+				t.Fatalf("Render(tv) error = %v", err)
+			}
+			if dir != tt.wantTVDir || name != tt.wantTV {
+				t.Errorf("Render(tv) = %q, %q, want %q, %q", dir, name, tt.wantTVDir, tt.wantTV)
+			}
+		})
+	}
+}
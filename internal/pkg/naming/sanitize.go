@@ -0,0 +1,293 @@
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Profile describes the naming restrictions of a target filesystem.
+type Profile struct {
+	Name          string    // Profile name as used in the configuration
+	Illegal       string    // Characters that may not appear in a name
+	Reserved      bool      // Whether Windows device names (CON, NUL, COM1, ...) are rejected
+	TrimTrailing  string    // Characters a name may not end with
+	MaxBytes      int       // Maximum length of a single path component in UTF-8 bytes
+	MaxUTF16      int       // Maximum length in UTF-16 code units instead, for filesystems that store names as UTF-16
+	Normalization norm.Form // Unicode normalization form applied to every name
+}
+
+// windowsIllegal holds the characters rejected by the Win32 API. They apply to
+// every filesystem that is written to from Windows or through SMB.
+const windowsIllegal = `<>:"/\|?*`
+
+// Profiles lists the supported target filesystems by name.
+var Profiles = map[string]Profile{
+	"posix": {
+		Name:          "posix",
+		Illegal:       "/",
+		MaxBytes:      255,
+		Normalization: norm.NFC,
+	},
+	"windows": {
+		Name:          "windows",
+		Illegal:       windowsIllegal,
+		Reserved:      true,
+		TrimTrailing:  ". ",
+		MaxBytes:      255,
+		Normalization: norm.NFC,
+	},
+	"smb": {
+		Name:          "smb",
+		Illegal:       windowsIllegal,
+		Reserved:      true,
+		TrimTrailing:  ". ",
+		MaxBytes:      255,
+		Normalization: norm.NFC,
+	},
+	// exFAT rejects the Win32 characters but has no device names, and it
+	// limits names to 255 UTF-16 characters rather than bytes
+	"exfat": {
+		Name:          "exfat",
+		Illegal:       windowsIllegal,
+		TrimTrailing:  ". ",
+		MaxUTF16:      255,
+		Normalization: norm.NFC,
+	},
+}
+
+// ProfileNames returns the sorted names of all supported target filesystems.
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// substitutions maps illegal characters to readable replacements. Characters
+// not listed here are dropped.
+var substitutions = map[rune]string{
+	':':  "-",
+	'/':  "-",
+	'\\': "-",
+	'|':  "-",
+	'*':  "-",
+	'"':  "'",
+}
+
+var (
+	// colonSeparator matches a colon used as a title separator ("Mission: Impossible").
+	colonSeparator = regexp.MustCompile(`\s*:\s+`)
+
+	// episodeMarker matches SxxEyy style markers that must survive truncation.
+	episodeMarker = regexp.MustCompile(`(?i)S\d{1,4}E\d{1,4}(?:-?E\d{1,4})*`)
+
+	// reservedNames holds the Windows device names, compared case-insensitively.
+	reservedNames = map[string]bool{
+		"CON": true, "PRN": true, "AUX": true, "NUL": true,
+		"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+		"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+		"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+		"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	}
+)
+
+// Sanitizer makes names safe for a target filesystem profile.
+type Sanitizer struct {
+	profile Profile
+}
+
+// NewSanitizer creates a sanitizer for the named profile. An empty name selects
+// the posix profile. The normalization argument overrides the profile's Unicode
+// normalization form ("nfc" or "nfd"); leave it empty to keep the default.
+func NewSanitizer(profileName, normalization string) (*Sanitizer, error) {
+	if profileName == "" {
+		profileName = "posix"
+	}
+	profile, ok := Profiles[strings.ToLower(profileName)]
+	if !ok {
+		return nil, fmt.Errorf("unknown target filesystem %q (supported: %s)", profileName, strings.Join(ProfileNames(), ", "))
+	}
+	switch strings.ToLower(normalization) {
+	case "":
+	case "nfc":
+		profile.Normalization = norm.NFC
+	case "nfd":
+		profile.Normalization = norm.NFD
+	default:
+		return nil, fmt.Errorf("unknown unicode normalization %q (supported: nfc, nfd)", normalization)
+	}
+	return &Sanitizer{profile: profile}, nil
+}
+
+// Profile returns the profile the sanitizer enforces.
+func (s *Sanitizer) Profile() Profile {
+	return s.profile
+}
+
+// Value replaces characters that are illegal on the target filesystem inside a
+// single template value. It also replaces path separators so that a title such
+// as "AC/DC" can never create an extra directory.
+//
+// Example (windows profile):
+//
+//	s.Value("Mission: Impossible") // "Mission - Impossible"
+//	s.Value("What If...?")         // "What If..."
+func (s *Sanitizer) Value(v string) string {
+	if strings.ContainsRune(s.profile.Illegal, ':') {
+		v = colonSeparator.ReplaceAllString(v, " - ")
+	}
+	var sb strings.Builder
+	for _, r := range v {
+		switch {
+		case r == '/' || strings.ContainsRune(s.profile.Illegal, r):
+			sb.WriteString(substitutions[r])
+		case unicode.IsControl(r):
+			// Control characters are dropped
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// Component sanitizes a single directory name: it normalizes Unicode, replaces
+// illegal characters, avoids reserved device names, strips trailing characters
+// the filesystem rejects and enforces the per-component length limit.
+func (s *Sanitizer) Component(name string) string {
+	name = s.clean(name)
+	name = s.truncate(name, s.maxLength())
+	return s.trimTrailing(name)
+}
+
+// Filename sanitizes a filename without extension and returns it joined with
+// ext. When the result exceeds the length limit, the name is shortened while
+// the extension and any SxxEyy episode marker are kept intact.
+func (s *Sanitizer) Filename(name, ext string) string {
+	name = s.trimTrailing(s.clean(name))
+	ext = s.profile.Normalization.String(ext)
+
+	limit := s.maxLength() - s.length(ext)
+	if limit > 0 && s.length(name) > limit {
+		name = s.trimTrailing(s.truncateKeepingMarker(name, limit))
+	}
+	return name + ext
+}
+
+// clean applies normalization, character substitution and reserved-name handling.
+func (s *Sanitizer) clean(name string) string {
+	name = s.profile.Normalization.String(name)
+	name = s.Value(name)
+	name = strings.TrimLeft(name, " ")
+	if s.profile.Reserved {
+		stem := name
+		if i := strings.IndexByte(stem, '.'); i >= 0 {
+			stem = stem[:i]
+		}
+		if reservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+			name = stem + "_" + name[len(stem):]
+		}
+	}
+	return s.trimTrailing(name)
+}
+
+func (s *Sanitizer) trimTrailing(name string) string {
+	if s.profile.TrimTrailing == "" {
+		return name
+	}
+	return strings.TrimRight(name, s.profile.TrimTrailing)
+}
+
+// truncateKeepingMarker shortens name to at most limit. If the name
+// contains an episode marker that fits, text after the marker is removed
+// first and text before it second.
+func (s *Sanitizer) truncateKeepingMarker(name string, limit int) string {
+	loc := episodeMarker.FindStringIndex(name)
+	if loc == nil || loc[1]-loc[0] > limit {
+		return strings.TrimRight(s.truncate(name, limit), " -._")
+	}
+	head, marker, tail := name[:loc[0]], name[loc[0]:loc[1]], name[loc[1]:]
+
+	tail = strings.TrimRight(s.truncate(tail, limit-s.length(head)-len(marker)), " -._")
+	if s.length(head)+len(marker)+s.length(tail) <= limit {
+		return head + marker + tail
+	}
+	// Keep the separator in front of the marker so it stays readable
+	sep := ""
+	if trimmed := strings.TrimRight(head, " -._"); len(trimmed) < len(head) {
+		sep = head[len(trimmed):]
+		head = trimmed
+	}
+	head = strings.TrimRight(s.truncate(head, limit-len(marker)-len(sep)), " -._")
+	if head == "" {
+		sep = ""
+	}
+	return head + sep + marker
+}
+
+// maxLength returns the length limit of a path component, in the unit the
+// profile counts in.
+func (s *Sanitizer) maxLength() int {
+	if s.profile.MaxUTF16 > 0 {
+		return s.profile.MaxUTF16
+	}
+	return s.profile.MaxBytes
+}
+
+// length returns the length of name in the unit the profile counts in:
+// UTF-16 code units or UTF-8 bytes.
+func (s *Sanitizer) length(name string) int {
+	if s.profile.MaxUTF16 == 0 {
+		return len(name)
+	}
+	n := 0
+	for _, r := range name {
+		n += utf16Length(r)
+	}
+	return n
+}
+
+// truncate shortens name to at most n units of the profile without splitting
+// a character.
+func (s *Sanitizer) truncate(name string, n int) string {
+	if s.profile.MaxUTF16 == 0 {
+		return truncateBytes(name, n)
+	}
+	size := 0
+	for i, r := range name {
+		size += utf16Length(r)
+		if size > n {
+			return name[:i]
+		}
+	}
+	return name
+}
+
+// utf16Length returns the number of UTF-16 code units that encode r.
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2 // Surrogate pair
+	}
+	return 1
+}
+
+// truncateBytes shortens s to at most n bytes without splitting a UTF-8 sequence.
+func truncateBytes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,154 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizerFilename(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		input   string
+		ext     string
+		want    string
+	}{
+		{name: "Posix keeps colon", profile: "posix", input: "Mission: Impossible", ext: ".mkv", want: "Mission: Impossible.mkv"},
+		{name: "Posix replaces slash", profile: "posix", input: "AC/DC: Live", ext: ".mkv", want: "AC-DC: Live.mkv"},
+		{name: "Windows colon separator", profile: "windows", input: "Mission: Impossible", ext: ".mkv", want: "Mission - Impossible.mkv"},
+		{name: "Windows question mark and trailing dots", profile: "windows", input: "What If...?", ext: ".mkv", want: "What If.mkv"},
+		{name: "SMB slash and colon", profile: "smb", input: "AC/DC: Live", ext: ".mp4", want: "AC-DC - Live.mp4"},
+		{name: "Exfat quotes and pipes", profile: "exfat", input: `The "Best" | Worst <Cut>`, ext: ".mkv", want: "The 'Best' - Worst Cut.mkv"},
+		{name: "Windows reserved name", profile: "windows", input: "CON", ext: ".mkv", want: "CON_.mkv"},
+		{name: "Windows reserved name case insensitive", profile: "smb", input: "nul", ext: ".avi", want: "nul_.avi"},
+		{name: "Reserved name only as whole stem", profile: "windows", input: "Con Air (1997)", ext: ".mkv", want: "Con Air (1997).mkv"},
+		{name: "Posix allows reserved names", profile: "posix", input: "CON", ext: ".mkv", want: "CON.mkv"},
+		{name: "Exfat allows reserved names", profile: "exfat", input: "CON", ext: ".mkv", want: "CON.mkv"},
+		{name: "Control characters dropped", profile: "posix", input: "Bad\tName\x00", ext: ".mkv", want: "BadName.mkv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSanitizer(tt.profile, "")
+			if err != nil {
+				t.Fatalf("NewSanitizer() error = %v", err)
+			}
+			if got := s.Filename(tt.input, tt.ext); got != tt.want {
+				t.Errorf("Filename(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizerComponent(t *testing.T) {
+	s, _ := NewSanitizer("windows", "")
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "What If...?", want: "What If"},
+		{input: "Star Wars: Episode IV ", want: "Star Wars - Episode IV"},
+		{input: "AUX", want: "AUX_"},
+		{input: "Season 01", want: "Season 01"},
+	}
+
+	for _, tt := range tests {
+		if got := s.Component(tt.input); got != tt.want {
+			t.Errorf("Component(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizerNormalization(t *testing.T) {
+	composed := "Am\u00e9lie"    // é as a single code point
+	decomposed := "Ame\u0301lie" // e followed by a combining acute accent
+
+	nfc, _ := NewSanitizer("posix", "")
+	if got := nfc.Filename(decomposed, ".mkv"); got != composed+".mkv" {
+		t.Errorf("NFC Filename() = %q, want %q", got, composed+".mkv")
+	}
+
+	nfd, err := NewSanitizer("posix", "nfd")
+	if err != nil {
+		t.Fatalf("NewSanitizer() error = %v", err)
+	}
+	if got := nfd.Filename(composed, ".mkv"); got != decomposed+".mkv" {
+		t.Errorf("NFD Filename() = %q, want %q", got, decomposed+".mkv")
+	}
+}
+
+func TestSanitizerTruncation(t *testing.T) {
+	s, _ := NewSanitizer("posix", "")
+	limit := s.Profile().MaxBytes
+
+	t.Run("Keeps extension", func(t *testing.T) {
+		got := s.Filename(strings.Repeat("a", 300), ".mkv")
+		if len(got) > limit || !strings.HasSuffix(got, ".mkv") {
+			t.Errorf("Filename() = %q (%d bytes), want at most %d bytes ending in .mkv", got, len(got), limit)
+		}
+	})
+
+	t.Run("Keeps episode marker", func(t *testing.T) {
+		input := "Show - S01E02-E03 - " + strings.Repeat("Long Title ", 30)
+		got := s.Filename(input, ".mkv")
+		if len(got) > limit || !strings.Contains(got, "S01E02-E03") {
+			t.Errorf("Filename() = %q, want marker kept within %d bytes", got, limit)
+		}
+		if strings.HasSuffix(strings.TrimSuffix(got, ".mkv"), " ") {
+			t.Errorf("Filename() = %q, has trailing space", got)
+		}
+	})
+
+	t.Run("Shortens title before marker", func(t *testing.T) {
+		input := strings.Repeat("Very Long Show ", 30) + "S01E02"
+		got := s.Filename(input, ".mkv")
+		if len(got) > limit || !strings.HasSuffix(got, " S01E02.mkv") {
+			t.Errorf("Filename() = %q, want marker kept at the end within %d bytes", got, limit)
+		}
+	})
+
+	t.Run("Does not split multi-byte characters", func(t *testing.T) {
+		got := s.Component(strings.Repeat("é", 200))
+		if len(got) > limit || !utf8.ValidString(got) {
+			t.Errorf("Component() = %q (%d bytes), want valid UTF-8 within %d bytes", got, len(got), limit)
+		}
+	})
+}
+
+func TestSanitizerTruncationUTF16(t *testing.T) {
+	s, _ := NewSanitizer("exfat", "")
+
+	t.Run("Counts characters rather than bytes", func(t *testing.T) {
+		input := strings.Repeat("é", 200)
+		if got := s.Component(input); got != input {
+			t.Errorf("Component() = %q (%d characters), want all 200 characters kept", got, utf8.RuneCountInString(got))
+		}
+	})
+
+	t.Run("Limits characters", func(t *testing.T) {
+		got := s.Filename(strings.Repeat("é", 300), ".mkv")
+		if n := utf8.RuneCountInString(got); n != 255 || !strings.HasSuffix(got, ".mkv") {
+			t.Errorf("Filename() = %q (%d characters), want 255 characters ending in .mkv", got, n)
+		}
+	})
+
+	t.Run("Counts surrogate pairs twice", func(t *testing.T) {
+		got := s.Component(strings.Repeat("🎬", 200))
+		if n := utf8.RuneCountInString(got); n != 127 {
+			t.Errorf("Component() has %d characters, want 127 surrogate pairs", n)
+		}
+	})
+}
+
+func TestNewSanitizerErrors(t *testing.T) {
+	if _, err := NewSanitizer("fat12", ""); err == nil {
+		t.Errorf("NewSanitizer(fat12) error = nil, want error")
+	}
+	if _, err := NewSanitizer("posix", "nfkc"); err == nil {
+		t.Errorf("NewSanitizer(posix, nfkc) error = nil, want error")
+	}
+	if _, err := NewSanitizer("", ""); err != nil {
+		t.Errorf("NewSanitizer(\"\") error = %v, want default profile", err)
+	}
+}
@@ -0,0 +1,69 @@
+package naming
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// ampersand matches "&" together with the spaces around it.
+	ampersand = regexp.MustCompile(`\s*&\s*`)
+
+	// sceneStripped lists characters scene-style names leave out entirely.
+	sceneStripped = strings.NewReplacer("'", "", "’", "", "‘", "", "`", "", ",", "")
+
+	// wordBreak matches a run of spaces together with the punctuation around
+	// them, such as " - " or ". ", which turns into a single separator. The
+	// dot ending an abbreviation ("Mr. Robot") is captured with the letter
+	// before it, so it is kept.
+	wordBreak = regexp.MustCompile(`(\pL\.)\s[\s._-]*|[\s._-]*\s[\s._-]*`)
+
+	// brackets are the characters scene-style names place no separator next to.
+	brackets = []string{"(", ")", "[", "]", "{", "}"}
+)
+
+// ApplySeparator replaces the word breaks of a single name component with sep.
+// A word break is a run of whitespace including any dots, dashes or underscores
+// around it, so "Show - Pilot" becomes "Show.Pilot" rather than "Show.-.Pilot".
+// Hyphenated words such as "Spider-Man" and the dots of abbreviations such as
+// "Mr. Robot" are left alone.
+//
+// With scene set, scene naming rules apply as well: "&" becomes "and",
+// apostrophes and commas are removed and no separator is placed next to a bracket.
+//
+// Example:
+//
+//	naming.ApplySeparator("Law & Order - Pilot", ".", true) // "Law.and.Order.Pilot"
+//	naming.ApplySeparator("The Matrix (1999)", "_", false)  // "The_Matrix_(1999)"
+func ApplySeparator(s, sep string, scene bool) string {
+	if scene {
+		s = ampersand.ReplaceAllString(s, " and ")
+		s = sceneStripped.Replace(s)
+	}
+	if sep == "" || sep == " " {
+		if scene {
+			s = whitespaceRun.ReplaceAllString(strings.TrimSpace(s), " ")
+		}
+		return s
+	}
+
+	s = wordBreak.ReplaceAllString(strings.TrimSpace(s), "${1}"+strings.ReplaceAll(sep, "$", "$$"))
+
+	for strings.Contains(s, sep+sep) {
+		s = strings.ReplaceAll(s, sep+sep, sep)
+	}
+	if scene {
+		for _, bracket := range brackets {
+			for strings.Contains(s, sep+bracket) || strings.Contains(s, bracket+sep) {
+				s = strings.ReplaceAll(strings.ReplaceAll(s, sep+bracket, bracket), bracket+sep, bracket)
+			}
+		}
+	}
+	for strings.HasPrefix(s, sep) {
+		s = s[len(sep):]
+	}
+	for strings.HasSuffix(s, sep) {
+		s = s[:len(s)-len(sep)]
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package naming
+
+import "testing"
+
+func TestApplySeparator(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		sep   string
+		scene bool
+		want  string
+	}{
+		{name: "Space separator unchanged", input: "Law & Order, Part 1", sep: " ", want: "Law & Order, Part 1"},
+		{name: "Empty separator unchanged", input: "The Matrix (1999)", sep: "", want: "The Matrix (1999)"},
+		{name: "Underscore", input: "The Matrix (1999) [1080p h264]", sep: "_", want: "The_Matrix_(1999)_[1080p_h264]"},
+		{name: "Dash", input: "Breaking Bad - S01E05 - Gray Matter", sep: "-", want: "Breaking-Bad-S01E05-Gray-Matter"},
+		{name: "Dot collapses dashed breaks", input: "Breaking Bad - S01E05 - Gray Matter", sep: ".", want: "Breaking.Bad.S01E05.Gray.Matter"},
+		{name: "Hyphenated words kept", input: "Spider-Man Homecoming", sep: ".", want: "Spider-Man.Homecoming"},
+		{name: "Abbreviation dot merged", input: "Mr. Robot", sep: ".", want: "Mr.Robot"},
+		{name: "Abbreviation dot kept", input: "Mr. Robot", sep: "_", want: "Mr._Robot"},
+		{name: "Abbreviation dot kept before a dash", input: "Dr. Who - Rose", sep: "_", want: "Dr._Who_Rose"},
+		{name: "Ellipsis merged", input: "What If... Again", sep: "_", want: "What_If_Again"},
+		{name: "Dollar separator", input: "Mr. Robot - Pilot", sep: "$", want: "Mr.$Robot$Pilot"},
+		{name: "Runs of separators collapse", input: "What If... Again", sep: ".", want: "What.If.Again"},
+		{name: "Scene ampersand", input: "Law & Order", sep: ".", scene: true, want: "Law.and.Order"},
+		{name: "Scene apostrophes and commas", input: "Schindler's List, Part 1", sep: ".", scene: true, want: "Schindlers.List.Part.1"},
+		{name: "Scene brackets", input: "The Matrix (1999) [1080p h264]", sep: ".", scene: true, want: "The.Matrix(1999)[1080p.h264]"},
+		{name: "Scene with underscore", input: "Bob's Burgers & Fries", sep: "_", scene: true, want: "Bobs_Burgers_and_Fries"},
+		{name: "Scene rules with space separator", input: "Bob's Burgers & Fries", sep: " ", scene: true, want: "Bobs Burgers and Fries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplySeparator(tt.input, tt.sep, tt.scene); got != tt.want {
+				t.Errorf("ApplySeparator(%q, %q, %v) = %q, want %q", tt.input, tt.sep, tt.scene, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package naming
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultArticles lists the leading articles ignored when sorting titles,
+// keyed by ISO 639-1 language code. Articles ending in an apostrophe attach
+// directly to the following word ("L'Avventura").
+var DefaultArticles = map[string][]string{
+	"en": {"The", "A", "An"},
+	"de": {"Der", "Die", "Das", "Ein", "Eine"},
+	"fr": {"Le", "La", "Les", "L'", "Un", "Une"},
+	"es": {"El", "La", "Los", "Las", "Un", "Una"},
+	"it": {"Il", "Lo", "La", "I", "Gli", "Le", "L'", "Un", "Una"},
+	"nl": {"De", "Het", "Een"},
+	"pt": {"O", "A", "Os", "As", "Um", "Uma"},
+	"sv": {"En", "Ett"},
+}
+
+// ArticlesFor returns the leading articles for a language. Entries in
+// overrides replace the built-in list of a language; languages without a
+// list fall back to English.
+func ArticlesFor(language string, overrides map[string][]string) []string {
+	language = strings.ToLower(language)
+	if articles, ok := overrides[language]; ok {
+		return articles
+	}
+	if articles, ok := DefaultArticles[language]; ok {
+		return articles
+	}
+	return DefaultArticles["en"]
+}
+
+// splitArticle separates a leading article from the rest of the title. It
+// returns an empty article when the title does not start with one, or when
+// the article is all there is.
+func splitArticle(title string, articles []string) (article, rest string) {
+	for _, candidate := range articles {
+		if len(title) <= len(candidate) || !strings.EqualFold(title[:len(candidate)], candidate) {
+			continue
+		}
+		rest := title[len(candidate):]
+		if !strings.HasSuffix(candidate, "'") {
+			if rest[0] != ' ' {
+				continue
+			}
+			rest = strings.TrimLeft(rest, " ")
+		}
+		if rest != "" {
+			return title[:len(candidate)], rest
+		}
+	}
+	return "", title
+}
+
+// SortTitle moves a leading article to the end of the title so that titles
+// sort by their first significant word.
+//
+// Example:
+//
+//	naming.SortTitle("The Matrix", naming.DefaultArticles["en"]) // "Matrix, The"
+//	naming.SortTitle("Heat", naming.DefaultArticles["en"])       // "Heat"
+func SortTitle(title string, articles []string) string {
+	article, rest := splitArticle(strings.TrimSpace(title), articles)
+	if article == "" {
+		return rest
+	}
+	return rest + ", " + article
+}
+
+// FirstLetter returns the letter a title is filed under in alphabetical
+// directory trees. Leading articles and punctuation are ignored, diacritics are
+// folded ("Élite" is filed under "E"), titles starting with a digit are filed
+// under "0-9" and titles without any letter or digit under "#".
+//
+// Example:
+//
+//	naming.FirstLetter("The Matrix", naming.DefaultArticles["en"])          // "M"
+//	naming.FirstLetter("(500) Days of Summer", naming.DefaultArticles["en"]) // "0-9"
+func FirstLetter(title string, articles []string) string {
+	_, rest := splitArticle(strings.TrimSpace(title), articles)
+	for _, r := range foldDiacritics(rest) {
+		switch {
+		case unicode.IsDigit(r):
+			return "0-9"
+		case unicode.IsLetter(r):
+			return string(unicode.ToUpper(r))
+		}
+	}
+	return "#"
+}
+
+// foldDiacritics removes combining marks, turning "Amélie" into "Amelie".
+func foldDiacritics(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil || !utf8.ValidString(folded) {
+		return s
+	}
+	return folded
+}
+
+// addSortValues derives the sort title and first letter from the title value.
+func addSortValues(values Values, articles []string) Values {
+	title, ok := values["title"].(string)
+	if !ok || title == "" {
+		return values
+	}
+	if articles == nil {
+		articles = DefaultArticles["en"]
+	}
+	extended := make(Values, len(values)+2)
+	for key, value := range values {
+		extended[key] = value
+	}
+	extended["title_sort"] = SortTitle(title, articles)
+	extended["first_letter"] = FirstLetter(title, articles)
+	return extended
+}
@@ -0,0 +1,87 @@
+package naming
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+func TestSortTitle(t *testing.T) {
+	english := DefaultArticles["en"]
+	tests := []struct {
+		title    string
+		articles []string
+		want     string
+	}{
+		{title: "The Matrix", articles: english, want: "Matrix, The"},
+		{title: "A Beautiful Mind", articles: english, want: "Beautiful Mind, A"},
+		{title: "An American Werewolf in London", articles: english, want: "American Werewolf in London, An"},
+		{title: "the thing", articles: english, want: "thing, the"},
+		{title: "Heat", articles: english, want: "Heat"},
+		{title: "Theodore Rex", articles: english, want: "Theodore Rex"},
+		{title: "A-Team", articles: english, want: "A-Team"},
+		{title: "The", articles: english, want: "The"},
+		{title: "Das Boot", articles: DefaultArticles["de"], want: "Boot, Das"},
+		{title: "L'Avventura", articles: DefaultArticles["it"], want: "Avventura, L'"},
+		{title: "Das Boot", articles: english, want: "Das Boot"},
+	}
+
+	for _, tt := range tests {
+		if got := SortTitle(tt.title, tt.articles); got != tt.want {
+			t.Errorf("SortTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestFirstLetter(t *testing.T) {
+	english := DefaultArticles["en"]
+	tests := []struct {
+		title    string
+		articles []string
+		want     string
+	}{
+		{title: "The Matrix", articles: english, want: "M"},
+		{title: "heat", articles: english, want: "H"},
+		{title: "12 Angry Men", articles: english, want: "0-9"},
+		{title: "(500) Days of Summer", articles: english, want: "0-9"},
+		{title: "'Salem's Lot", articles: english, want: "S"},
+		{title: "Élite", articles: english, want: "E"},
+		{title: "à bout de souffle", articles: english, want: "A"},
+		{title: "Le Samouraï", articles: DefaultArticles["fr"], want: "S"},
+		{title: "!!!", articles: english, want: "#"},
+	}
+
+	for _, tt := range tests {
+		if got := FirstLetter(tt.title, tt.articles); got != tt.want {
+			t.Errorf("FirstLetter(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestArticlesFor(t *testing.T) {
+	overrides := map[string][]string{"en": {"The"}}
+	if got := ArticlesFor("en", overrides); len(got) != 1 || got[0] != "The" {
+		t.Errorf("ArticlesFor(en) = %v, want override", got)
+	}
+	if got := ArticlesFor("DE", nil); got[0] != "Der" {
+		t.Errorf("ArticlesFor(DE) = %v, want German articles", got)
+	}
+	if got := ArticlesFor("ja", nil); got[0] != "The" {
+		t.Errorf("ArticlesFor(ja) = %v, want English fallback", got)
+	}
+}
+
+func TestRenderSortPlaceholders(t *testing.T) {
+	movie := &metadata.MovieMetadata{Title: "The Matrix", Year: 1999}
+	dir, name, err := Render("{title} ({year})", "Movies/{first_letter}/{title_sort} ({year})", ".mkv", MovieValues(movie, nil), Options{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := filepath.Join("Movies", "M", "Matrix, The (1999)"); dir != want {
+		t.Errorf("Render() dir = %q, want %q", dir, want)
+	}
+	if name != "The Matrix (1999).mkv" {
+		t.Errorf("Render() name = %q, want %q", name, "The Matrix (1999).mkv")
+	}
+}
@@ -0,0 +1,150 @@
+package naming
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tekenstam/vidkit/internal/pkg/media"
+	"github.com/tekenstam/vidkit/pkg/resolution"
+)
+
+// videoCodecNames maps ffprobe video codec names to the names used in releases.
+var videoCodecNames = map[string]string{
+	"hevc":       "HEVC",
+	"h265":       "HEVC",
+	"h264":       "AVC",
+	"av1":        "AV1",
+	"vp9":        "VP9",
+	"vp8":        "VP8",
+	"mpeg4":      "XviD",
+	"mpeg2video": "MPEG2",
+	"mpeg1video": "MPEG1",
+	"vc1":        "VC-1",
+	"wmv3":       "WMV",
+}
+
+// audioCodecNames maps ffprobe audio codec names to the names used in releases.
+var audioCodecNames = map[string]string{
+	"eac3":   "DDP",
+	"ac3":    "DD",
+	"aac":    "AAC",
+	"truehd": "TrueHD",
+	"dts":    "DTS",
+	"flac":   "FLAC",
+	"opus":   "Opus",
+	"vorbis": "Vorbis",
+	"mp3":    "MP3",
+	"mp2":    "MP2",
+}
+
+// VideoCodecName returns the release-style name of an ffprobe video codec,
+// such as "HEVC" for hevc or "AVC" for h264. Unknown codecs are returned in
+// upper case.
+func VideoCodecName(codec string) string {
+	if name, ok := videoCodecNames[strings.ToLower(codec)]; ok {
+		return name
+	}
+	return strings.ToUpper(codec)
+}
+
+// AudioCodecName returns the release-style name of an ffprobe audio codec,
+// such as "DDP" for eac3 or "DD" for ac3. The profile distinguishes the DTS
+// variants and every PCM format is reported as "LPCM". Unknown codecs are
+// returned in upper case.
+//
+// Example:
+//
+//	naming.AudioCodecName("dts", "DTS-HD MA") // "DTS-HD MA"
+//	naming.AudioCodecName("pcm_s24le", "")    // "LPCM"
+func AudioCodecName(codec, profile string) string {
+	codec = strings.ToLower(codec)
+	switch {
+	case strings.HasPrefix(codec, "pcm_"):
+		return "LPCM"
+	case codec == "dts" && strings.HasPrefix(profile, "DTS-HD"):
+		return profile
+	case codec == "dts" && profile == "DTS:X":
+		return profile
+	case codec == "eac3" && strings.Contains(profile, "Atmos"):
+		return "DDP Atmos"
+	case codec == "truehd" && strings.Contains(profile, "Atmos"):
+		return "TrueHD Atmos"
+	}
+	if name, ok := audioCodecNames[codec]; ok {
+		return name
+	}
+	return strings.ToUpper(codec)
+}
+
+// ContainerName returns the short container name of a file from the ffprobe
+// format name. ffprobe reports a list of formats for some containers
+// ("mov,mp4,m4a,3gp,3g2,mj2"), in which case the file extension decides.
+func ContainerName(formatName, path string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	switch {
+	case formatName == "":
+		return ext
+	case strings.HasPrefix(formatName, "matroska"):
+		if ext == "webm" {
+			return ext
+		}
+		return "mkv"
+	case strings.HasPrefix(formatName, "mov"):
+		if ext == "mov" || ext == "m4v" {
+			return ext
+		}
+		return "mp4"
+	case formatName == "mpegts":
+		return "ts"
+	}
+	if i := strings.IndexByte(formatName, ','); i >= 0 {
+		return formatName[:i]
+	}
+	return formatName
+}
+
+// technicalValues extracts the values derived from the ffprobe information of
+// a file: container and size from the format, video properties from the first
+// video stream and audio properties from the first audio stream.
+func technicalValues(info *media.VideoInfo) Values {
+	values := Values{}
+	if info == nil {
+		return values
+	}
+
+	setString(values, "container", ContainerName(info.Format.FormatName, info.Format.Filename))
+	setString(values, "duration", media.FormatDuration(info.Format.Duration))
+	if info.Format.Size != "" {
+		values["filesize"] = media.FormatFileSize(info.Format.Size)
+	}
+
+	if video := info.VideoStream(); video != nil {
+		if video.Height > 0 {
+			values["resolution"] = resolution.GetStandardResolution(video.Width, video.Height)
+		}
+		values["codec"] = video.CodecName
+		setString(values, "video_codec", VideoCodecName(video.CodecName))
+		setInt(values, "bit_depth", video.BitDepth())
+		setString(values, "hdr", video.HDRFormat())
+		if fps := media.FrameRateValue(video.FrameRate); fps > 0 {
+			values["framerate"] = fps
+		}
+		if kbps, err := strconv.Atoi(video.BitRate); err == nil {
+			setInt(values, "video_bitrate", kbps/1000)
+		}
+	}
+
+	if audio := info.AudioStream(); audio != nil {
+		setString(values, "audio_codec", AudioCodecName(audio.CodecName, audio.Profile))
+		setString(values, "audio_channels", media.FormatChannels(audio.Channels, audio.ChannelLayout))
+	}
+	return values
+}
+
+// setString stores s under key unless it is empty.
+func setString(values Values, key, s string) {
+	if s != "" {
+		values[key] = s
+	}
+}
@@ -0,0 +1,132 @@
+package naming
+
+import (
+	"testing"
+
+	"github.com/tekenstam/vidkit/internal/pkg/media"
+	"github.com/tekenstam/vidkit/internal/pkg/metadata"
+)
+
+func TestCodecNames(t *testing.T) {
+	videoTests := map[string]string{
+		"hevc":   "HEVC",
+		"h264":   "AVC",
+		"av1":    "AV1",
+		"mpeg4":  "XviD",
+		"theora": "THEORA",
+	}
+	for codec, want := range videoTests {
+		if got := VideoCodecName(codec); got != want {
+			t.Errorf("VideoCodecName(%q) = %q, want %q", codec, got, want)
+		}
+	}
+
+	audioTests := []struct {
+		codec   string
+		profile string
+		want    string
+	}{
+		{codec: "eac3", want: "DDP"},
+		{codec: "eac3", profile: "Dolby Digital Plus + Dolby Atmos", want: "DDP Atmos"},
+		{codec: "ac3", want: "DD"},
+		{codec: "aac", profile: "LC", want: "AAC"},
+		{codec: "truehd", want: "TrueHD"},
+		{codec: "dts", profile: "DTS", want: "DTS"},
+		{codec: "dts", profile: "DTS-HD MA", want: "DTS-HD MA"},
+		{codec: "pcm_s24le", want: "LPCM"},
+		{codec: "alac", want: "ALAC"},
+	}
+	for _, tt := range audioTests {
+		if got := AudioCodecName(tt.codec, tt.profile); got != tt.want {
+			t.Errorf("AudioCodecName(%q, %q) = %q, want %q", tt.codec, tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestContainerName(t *testing.T) {
+	tests := []struct {
+		format string
+		path   string
+		want   string
+	}{
+		{format: "matroska,webm", path: "movie.mkv", want: "mkv"},
+		{format: "matroska,webm", path: "clip.webm", want: "webm"},
+		{format: "mov,mp4,m4a,3gp,3g2,mj2", path: "movie.mp4", want: "mp4"},
+		{format: "mov,mp4,m4a,3gp,3g2,mj2", path: "movie.m4v", want: "m4v"},
+		{format: "mpegts", path: "recording.m2ts", want: "ts"},
+		{format: "avi", path: "old.avi", want: "avi"},
+		{format: "", path: "unknown.mkv", want: "mkv"},
+	}
+
+	for _, tt := range tests {
+		if got := ContainerName(tt.format, tt.path); got != tt.want {
+			t.Errorf("ContainerName(%q, %q) = %q, want %q", tt.format, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTechnicalPlaceholders(t *testing.T) {
+	info := &media.VideoInfo{}
+	info.Format.Filename = "/videos/movie.mkv"
+	info.Format.FormatName = "matroska,webm"
+	info.Format.Duration = "8160.5"
+	info.Format.Size = "4831838208"
+	info.Streams = []media.Stream{
+		{
+			CodecType:     "video",
+			CodecName:     "hevc",
+			Width:         3840,
+			Height:        2160,
+			BitRate:       "15000000",
+			FrameRate:     "24000/1001",
+			PixFmt:        "yuv420p10le",
+			ColorTransfer: "smpte2084",
+		},
+		{CodecType: "audio", CodecName: "eac3", Channels: 6, ChannelLayout: "5.1(side)"},
+	}
+	movie := &metadata.MovieMetadata{Title: "Dune", Year: 2021}
+
+	tests := []struct {
+		name     string
+		template string
+		info     *media.VideoInfo
+		want     string
+	}{
+		{
+			name:     "Plex quality suffix",
+			template: "{title} ({year}) [{resolution} {hdr} {video_codec} {bit_depth}bit {audio_codec} {audio_channels}]",
+			info:     info,
+			want:     "Dune (2021) [4K HDR10 HEVC 10bit DDP 5.1].mkv",
+		},
+		{
+			name:     "Container duration and size",
+			template: "{title} {container} {duration} {filesize}",
+			info:     info,
+			want:     "Dune mkv 2h16m 4.50 GB.mkv",
+		},
+		{
+			name:     "Bitrate and frame rate",
+			template: "{title} {video_bitrate}kbps {framerate}fps",
+			info:     info,
+			want:     "Dune 15000kbps 23.976fps.mkv",
+		},
+		{
+			name:     "Missing values collapse optional groups",
+			template: "{title}< {hdr}>< {audio_codec}>",
+			info:     testVideoInfo(),
+			want:     "Dune.mkv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := Render(tt.template, "", ".mkv", MovieValues(movie, tt.info), Options{})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,497 @@
+// Package naming renders the filename and directory templates VidKit uses when
+// renaming and organizing video files.
+//
+// A template is plain text containing placeholders in braces. Each placeholder
+// names a value and may carry an index, a printf-style format spec and a chain
+// of filters:
+//
+//	{title}                 plain substitution
+//	{season:02d}            printf-style formatting (%02d)
+//	{title[0]}              first character of a string (or element of a list)
+//	{title|upper}           filters are applied left to right
+//	{genres|join:", "}      filters may take a (quoted) argument
+//
+// Text in angle brackets is an optional group that is dropped entirely when any
+// placeholder inside it is missing, empty or zero:
+//
+//	{title}< ({year})>< - {episode_title}>
+//
+// Literal braces are written as "{{" and "}}", literal angle brackets as "<<"
+// and ">>". Templates are parsed once and can be executed any number of times
+// against different values.
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Values holds the data a template is executed against, keyed by placeholder name.
+// Supported value types are string, []string, int and float64.
+type Values map[string]interface{}
+
+// knownFields lists every placeholder name a template may reference.
+// Parsing fails for any other name so typos are caught before files are renamed.
+var knownFields = map[string]bool{
+	"title":            true,
+	"title_sort":       true,
+	"first_letter":     true,
+	"year":             true,
+	"edition":          true,
+	"part":             true,
+	"resolution":       true,
+	"codec":            true,
+	"genre":            true,
+	"genres":           true,
+	"season":           true,
+	"episode":          true,
+	"episode_range":    true,
+	"air_date":         true,
+	"absolute_episode": true,
+	"episode_title":    true,
+	"network":          true,
+
+	// Technical values from ffprobe
+	"video_codec":    true,
+	"audio_codec":    true,
+	"audio_channels": true,
+	"video_bitrate":  true,
+	"bit_depth":      true,
+	"hdr":            true,
+	"container":      true,
+	"duration":       true,
+	"filesize":       true,
+	"framerate":      true,
+}
+
+// Fields returns the sorted list of placeholder names templates may use.
+func Fields() []string {
+	names := make([]string, 0, len(knownFields))
+	for name := range knownFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterFunc transforms a placeholder value. The argument is empty when the
+// filter was used without one.
+type filterFunc func(value interface{}, arg string) interface{}
+
+// filters maps filter names to their implementation and whether they accept an argument.
+var filters = map[string]struct {
+	fn     filterFunc
+	hasArg bool
+}{
+	"upper": {fn: func(v interface{}, _ string) interface{} { return strings.ToUpper(formatValue(v)) }},
+	"lower": {fn: func(v interface{}, _ string) interface{} { return strings.ToLower(formatValue(v)) }},
+	"join": {fn: func(v interface{}, sep string) interface{} {
+		if list, ok := v.([]string); ok {
+			return strings.Join(list, sep)
+		}
+		return formatValue(v)
+	}, hasArg: true},
+}
+
+// specPattern validates printf-style format specs such as "02d", "-10s" or ".1f".
+var specPattern = regexp.MustCompile(`^[-+# 0]*[0-9]*(\.[0-9]+)?[dsvfxX]$`)
+
+// ParseError describes a syntax problem in a template.
+type ParseError struct {
+	Template string // The template text being parsed
+	Pos      int    // Byte offset of the problem within Template
+	Msg      string // Description of the problem
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("template %q: position %d: %s", e.Template, e.Pos, e.Msg)
+}
+
+// Template is a parsed filename or directory template.
+type Template struct {
+	text  string
+	nodes []node
+}
+
+// node is a run of literal text, a placeholder or an optional group.
+type node struct {
+	literal     string
+	placeholder *placeholder
+	group       []node
+}
+
+// placeholder is a parsed {name[index]:spec|filter:arg} expression.
+type placeholder struct {
+	name    string
+	index   int // -1 when no index was given
+	spec    string
+	filters []appliedFilter
+}
+
+type appliedFilter struct {
+	name string
+	arg  string
+}
+
+// Parse parses template text. It returns a *ParseError for malformed
+// placeholders, unknown placeholder names, unknown filters and invalid format specs.
+func Parse(text string) (*Template, error) {
+	p := &parser{text: text}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return &Template{text: text, nodes: p.nodes}, nil
+}
+
+// MustParse is like Parse but panics on error. It is intended for templates
+// that are compiled into the program.
+func MustParse(text string) *Template {
+	t, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the original template text.
+func (t *Template) String() string {
+	return t.text
+}
+
+// Uses reports whether the template references the named value, including
+// inside optional groups.
+func (t *Template) Uses(name string) bool {
+	return usesField(t.nodes, name)
+}
+
+func usesField(nodes []node, name string) bool {
+	for _, n := range nodes {
+		if (n.placeholder != nil && n.placeholder.name == name) || usesField(n.group, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute renders the template with the given values. Placeholders without a
+// value render as an empty string and optional groups containing them are omitted.
+func (t *Template) Execute(values Values) (string, error) {
+	s, _, err := renderNodes(t.nodes, values)
+	return s, err
+}
+
+// renderNodes renders a node list and reports whether any placeholder in it
+// (outside nested groups) was missing, empty or zero.
+func renderNodes(nodes []node, values Values) (string, bool, error) {
+	var sb strings.Builder
+	hasEmpty := false
+	for _, n := range nodes {
+		switch {
+		case n.placeholder != nil:
+			s, err := n.placeholder.render(values)
+			if err != nil {
+				return "", false, err
+			}
+			if s == "" || isZero(values[n.placeholder.name]) {
+				hasEmpty = true
+			}
+			sb.WriteString(s)
+		case n.group != nil:
+			s, empty, err := renderNodes(n.group, values)
+			if err != nil {
+				return "", false, err
+			}
+			if !empty {
+				sb.WriteString(s)
+			}
+		default:
+			sb.WriteString(n.literal)
+		}
+	}
+	return sb.String(), hasEmpty, nil
+}
+
+// isZero reports whether a value counts as absent for optional groups.
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	}
+	return false
+}
+
+// render resolves a single placeholder against values.
+func (ph *placeholder) render(values Values) (string, error) {
+	value := values[ph.name]
+	if ph.index >= 0 {
+		value = indexValue(value, ph.index)
+	}
+	for _, f := range ph.filters {
+		value = filters[f.name].fn(value, f.arg)
+	}
+	if ph.spec == "" || value == nil {
+		return formatValue(value), nil
+	}
+
+	verb := ph.spec[len(ph.spec)-1]
+	switch v := value.(type) {
+	case int:
+		if verb == 'f' {
+			return fmt.Sprintf("%"+ph.spec, float64(v)), nil
+		}
+	case float64:
+		if verb == 'd' || verb == 'x' || verb == 'X' {
+			return fmt.Sprintf("%"+ph.spec, int(v)), nil
+		}
+	case string:
+		if verb != 's' && verb != 'v' {
+			return "", fmt.Errorf("placeholder {%s}: format %%%s needs a number, got %q", ph.name, ph.spec, v)
+		}
+	default:
+		value = formatValue(v)
+		if verb != 's' && verb != 'v' {
+			return "", fmt.Errorf("placeholder {%s}: format %%%s needs a number", ph.name, ph.spec)
+		}
+	}
+	return fmt.Sprintf("%"+ph.spec, value), nil
+}
+
+// indexValue returns the i-th character of a string or the i-th element of a list.
+func indexValue(value interface{}, i int) interface{} {
+	switch v := value.(type) {
+	case string:
+		runes := []rune(v)
+		if i < len(runes) {
+			return string(runes[i])
+		}
+		return ""
+	case []string:
+		if i < len(v) {
+			return v[i]
+		}
+		return ""
+	}
+	return value
+}
+
+// formatValue converts a value to its default string form.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ", ")
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parser holds the state used while parsing a template.
+type parser struct {
+	text       string
+	pos        int
+	nodes      []node
+	lit        strings.Builder
+	groupStart int // index into nodes where the open group begins, -1 outside groups
+	groupPos   int // byte offset of the open group's '<'
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &ParseError{Template: p.text, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) flushLiteral() {
+	if p.lit.Len() > 0 {
+		p.nodes = append(p.nodes, node{literal: p.lit.String()})
+		p.lit.Reset()
+	}
+}
+
+func (p *parser) parse() error {
+	p.groupStart = -1
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case c == '<' && strings.HasPrefix(p.text[p.pos:], "<<"):
+			p.lit.WriteByte('<')
+			p.pos += 2
+		case c == '>' && strings.HasPrefix(p.text[p.pos:], ">>") && p.groupStart < 0:
+			p.lit.WriteByte('>')
+			p.pos += 2
+		case c == '<':
+			if p.groupStart >= 0 {
+				return p.errorf(p.pos, "optional groups cannot be nested")
+			}
+			p.flushLiteral()
+			p.groupStart = len(p.nodes)
+			p.groupPos = p.pos
+			p.pos++
+		case c == '>':
+			if p.groupStart < 0 {
+				return p.errorf(p.pos, "unexpected '>' (use '>>' for a literal angle bracket)")
+			}
+			p.flushLiteral()
+			group := append([]node(nil), p.nodes[p.groupStart:]...)
+			p.nodes = append(p.nodes[:p.groupStart], node{group: group})
+			p.groupStart = -1
+			p.pos++
+		case c == '{' && strings.HasPrefix(p.text[p.pos:], "{{"):
+			p.lit.WriteByte('{')
+			p.pos += 2
+		case c == '}' && strings.HasPrefix(p.text[p.pos:], "}}"):
+			p.lit.WriteByte('}')
+			p.pos += 2
+		case c == '{':
+			p.flushLiteral()
+			ph, err := p.parsePlaceholder()
+			if err != nil {
+				return err
+			}
+			p.nodes = append(p.nodes, node{placeholder: ph})
+		case c == '}':
+			return p.errorf(p.pos, "unexpected '}' (use '}}' for a literal brace)")
+		default:
+			_, size := utf8.DecodeRuneInString(p.text[p.pos:])
+			p.lit.WriteString(p.text[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+	if p.groupStart >= 0 {
+		return p.errorf(p.groupPos, "unterminated optional group")
+	}
+	p.flushLiteral()
+	return nil
+}
+
+// parsePlaceholder parses a placeholder starting at the opening brace.
+func (p *parser) parsePlaceholder() (*placeholder, error) {
+	start := p.pos
+	p.pos++ // skip '{'
+
+	name := p.scanIdent()
+	if name == "" {
+		return nil, p.errorf(start, "empty placeholder name")
+	}
+	if !knownFields[name] {
+		return nil, p.errorf(start, "unknown placeholder {%s}", name)
+	}
+	ph := &placeholder{name: name, index: -1}
+
+	if p.peek() == '[' {
+		p.pos++
+		digits := p.scanWhile(func(c byte) bool { return c >= '0' && c <= '9' })
+		if digits == "" || p.peek() != ']' {
+			return nil, p.errorf(p.pos, "invalid index in placeholder {%s}", name)
+		}
+		p.pos++
+		ph.index, _ = strconv.Atoi(digits)
+	}
+
+	if p.peek() == ':' {
+		p.pos++
+		specPos := p.pos
+		ph.spec = p.scanWhile(func(c byte) bool { return c != '|' && c != '}' })
+		if !specPattern.MatchString(ph.spec) {
+			return nil, p.errorf(specPos, "invalid format spec %q in placeholder {%s}", ph.spec, name)
+		}
+	}
+
+	for p.peek() == '|' {
+		p.pos++
+		filterPos := p.pos
+		filterName := p.scanIdent()
+		def, ok := filters[filterName]
+		if !ok {
+			return nil, p.errorf(filterPos, "unknown filter %q in placeholder {%s}", filterName, name)
+		}
+		f := appliedFilter{name: filterName}
+		if p.peek() == ':' {
+			if !def.hasArg {
+				return nil, p.errorf(p.pos, "filter %q does not take an argument", filterName)
+			}
+			p.pos++
+			arg, err := p.scanArg()
+			if err != nil {
+				return nil, err
+			}
+			f.arg = arg
+		} else if filterName == "join" {
+			f.arg = ", "
+		}
+		ph.filters = append(ph.filters, f)
+	}
+
+	if p.peek() != '}' {
+		if p.pos >= len(p.text) {
+			return nil, p.errorf(start, "unterminated placeholder {%s", name)
+		}
+		return nil, p.errorf(p.pos, "unexpected %q in placeholder {%s}", p.text[p.pos], name)
+	}
+	p.pos++
+	return ph, nil
+}
+
+// scanArg reads a filter argument, either double-quoted (with \" and \\ escapes)
+// or bare up to the next '|' or '}'.
+func (p *parser) scanArg() (string, error) {
+	if p.peek() != '"' {
+		return p.scanWhile(func(c byte) bool { return c != '|' && c != '}' }), nil
+	}
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.text):
+			sb.WriteByte(p.text[p.pos+1])
+			p.pos += 2
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf(start, "unterminated quoted argument")
+}
+
+func (p *parser) scanIdent() string {
+	return p.scanWhile(func(c byte) bool {
+		return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+	})
+}
+
+func (p *parser) scanWhile(accept func(byte) bool) string {
+	start := p.pos
+	for p.pos < len(p.text) && accept(p.text[p.pos]) {
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
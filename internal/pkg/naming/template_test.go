@@ -0,0 +1,218 @@
+package naming
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTemplateExecute(t *testing.T) {
+	values := Values{
+		"title":         "The Matrix",
+		"year":          1999,
+		"season":        1,
+		"episode":       5,
+		"episode_title": "Gray Matter",
+		"genres":        []string{"Action", "Science Fiction"},
+		"resolution":    "1080p",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "Plain placeholders",
+			template: "{title} ({year})",
+			want:     "The Matrix (1999)",
+		},
+		{
+			name:     "Zero padded season and episode",
+			template: "S{season:02d}E{episode:02d}",
+			want:     "S01E05",
+		},
+		{
+			name:     "Arbitrary width",
+			template: "S{season:03d}E{episode:04d} {year:06d}",
+			want:     "S001E0005 001999",
+		},
+		{
+			name:     "String spec",
+			template: "[{resolution:6s}]",
+			want:     "[ 1080p]",
+		},
+		{
+			name:     "Upper filter",
+			template: "{title|upper}",
+			want:     "THE MATRIX",
+		},
+		{
+			name:     "Lower filter",
+			template: "{title|lower}",
+			want:     "the matrix",
+		},
+		{
+			name:     "Join filter with quoted argument",
+			template: `{genres|join:", "}`,
+			want:     "Action, Science Fiction",
+		},
+		{
+			name:     "Join filter with bare argument",
+			template: "{genres|join:+}",
+			want:     "Action+Science Fiction",
+		},
+		{
+			name:     "Chained filters",
+			template: `{genres|join:" & "|upper}`,
+			want:     "ACTION & SCIENCE FICTION",
+		},
+		{
+			name:     "Index into string",
+			template: "{title[0]}/{title}",
+			want:     "T/The Matrix",
+		},
+		{
+			name:     "Index into list",
+			template: "{genres[1]}",
+			want:     "Science Fiction",
+		},
+		{
+			name:     "Escaped braces",
+			template: "{{{title}}}",
+			want:     "{The Matrix}",
+		},
+		{
+			name:     "Missing value renders empty",
+			template: "{title}{network}",
+			want:     "The Matrix",
+		},
+		{
+			name:     "Unicode literals are preserved",
+			template: "{title} – Ärger",
+			want:     "The Matrix – Ärger",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse(tt.template)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, err := tmpl.Execute(values)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateOptionalGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   Values
+		want     string
+	}{
+		{
+			name:     "Group with values",
+			template: "{title}< ({year})>< - {episode_title}>",
+			values:   Values{"title": "Pilot", "year": 2008, "episode_title": "Gray Matter"},
+			want:     "Pilot (2008) - Gray Matter",
+		},
+		{
+			name:     "Group with missing value",
+			template: "{title}< ({year})>< - {episode_title}>",
+			values:   Values{"title": "Pilot", "episode_title": "Gray Matter"},
+			want:     "Pilot - Gray Matter",
+		},
+		{
+			name:     "Group with zero value",
+			template: "{title}< ({year})>",
+			values:   Values{"title": "Pilot", "year": 0},
+			want:     "Pilot",
+		},
+		{
+			name:     "Group with empty string",
+			template: "{title}< - {episode_title}>",
+			values:   Values{"title": "Pilot", "episode_title": ""},
+			want:     "Pilot",
+		},
+		{
+			name:     "Group dropped when any placeholder is empty",
+			template: "{title}< [{resolution} {codec}]>",
+			values:   Values{"title": "Pilot", "codec": "h264"},
+			want:     "Pilot",
+		},
+		{
+			name:     "Literal angle brackets",
+			template: "<<{title}>>",
+			values:   Values{"title": "Pilot"},
+			want:     "<Pilot>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MustParse(tt.template).Execute(tt.values)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{name: "Unknown placeholder", template: "{title} {series}"},
+		{name: "Empty placeholder", template: "{}"},
+		{name: "Unterminated placeholder", template: "{title"},
+		{name: "Stray closing brace", template: "title}"},
+		{name: "Invalid format spec", template: "{season:2q}"},
+		{name: "Unknown filter", template: "{title|reverse}"},
+		{name: "Argument to filter without argument", template: "{title|upper:x}"},
+		{name: "Unterminated quoted argument", template: `{genres|join:", }`},
+		{name: "Invalid index", template: "{title[x]}"},
+		{name: "Unterminated group", template: "{title}< ({year})"},
+		{name: "Nested group", template: "{title}< <{year}>>"},
+		{name: "Stray group close", template: "{title}>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.template)
+			if err == nil {
+				t.Fatalf("Parse(%q) error = nil, want error", tt.template)
+			}
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Errorf("Parse(%q) error type = %T, want *ParseError", tt.template, err)
+			}
+		})
+	}
+}
+
+func TestExecuteFormatMismatch(t *testing.T) {
+	tmpl := MustParse("{title:02d}")
+	if _, err := tmpl.Execute(Values{"title": "The Matrix"}); err == nil {
+		t.Errorf("Execute() error = nil, want error for numeric format on string")
+	}
+}
+
+func TestTemplateUses(t *testing.T) {
+	tmpl := MustParse("{title} ({year})< - {part|upper}>")
+	for name, want := range map[string]bool{"title": true, "part": true, "edition": false} {
+		if got := tmpl.Uses(name); got != want {
+			t.Errorf("Uses(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
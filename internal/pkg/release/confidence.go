@@ -0,0 +1,69 @@
+package release
+
+// EpisodeConfidence rates how likely the release is a TV episode, from 0 for
+// names without any episode marker to 1. Together with MovieConfidence it
+// decides whether a file is looked up as an episode or as a movie.
+func (r ParsedRelease) EpisodeConfidence() float64 {
+	var score float64
+	switch {
+	case r.HasSeason() && r.Episode() > 0:
+		score = 0.6
+	case r.AirDate != "":
+		// Dates appear in names of all kinds of recordings, not only daily shows
+		score = 0.5
+	case r.AbsoluteEpisode > 0:
+		score = 0.4
+	default:
+		return 0
+	}
+	if r.Title != "" {
+		score += 0.2
+		if isObfuscated(r.Title) {
+			score -= 0.1
+		}
+	}
+	if r.EpisodeTitle != "" {
+		score += 0.1
+	}
+	if len(r.Episodes) > 1 {
+		score += 0.1
+	}
+	return clamp(score)
+}
+
+// MovieConfidence rates how likely the release is a movie, from 0 for names
+// without a title to 1. Names that also number an episode are unlikely to be
+// movies.
+func (r ParsedRelease) MovieConfidence() float64 {
+	if r.Title == "" {
+		return 0
+	}
+	score := 0.4
+	if r.Year > 0 {
+		score += 0.3
+	}
+	if r.Edition != "" || r.Part > 0 {
+		score += 0.1
+	}
+	if r.Resolution != "" || r.Source != "" || r.VideoCodec != "" {
+		score += 0.1
+	}
+	if isObfuscated(r.Title) {
+		score -= 0.2
+	}
+	if r.IsEpisode() {
+		score /= 2
+	}
+	return clamp(score)
+}
+
+// clamp limits a confidence score to the range from 0 to 1.
+func clamp(score float64) float64 {
+	switch {
+	case score < 0:
+		return 0
+	case score > 1:
+		return 1
+	}
+	return score
+}
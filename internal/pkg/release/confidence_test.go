@@ -0,0 +1,37 @@
+package release
+
+import "testing"
+
+func TestConfidence(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantTV    bool // Whether the episode interpretation should win
+		wantMovie bool // Whether the movie interpretation should win
+	}{
+		{name: "Show.Name.S01E02.720p.mkv", wantTV: true},
+		{name: "Show Name 1x02 Pilot.mkv", wantTV: true},
+		{name: "The.Daily.Show.2024.03.14.mkv", wantTV: true},
+		{name: "Screen Recording 2024-03-14 at 10.00.00.mov", wantMovie: true},
+		{name: "VID_2024-03-14_10-00-00.mp4", wantMovie: true},
+		{name: "Some.Movie.2020.1920x1080.WEB-DL.mkv", wantMovie: true},
+		{name: "Screen Recording 1280x720.mp4", wantMovie: true},
+		{name: "Inception (2010).mkv", wantMovie: true},
+		{name: "2x01.mkv", wantTV: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Parse(tt.name)
+			tv, movie := r.EpisodeConfidence(), r.MovieConfidence()
+			if tv < 0 || tv > 1 || movie < 0 || movie > 1 {
+				t.Fatalf("confidence out of range: episode %v, movie %v", tv, movie)
+			}
+			if got := tv > 0 && tv >= movie; got != tt.wantTV {
+				t.Errorf("episode confidence %v against movie %v, want episode %v", tv, movie, tt.wantTV)
+			}
+			if got := movie > tv; got != tt.wantMovie {
+				t.Errorf("movie confidence %v against episode %v, want movie %v", movie, tv, tt.wantMovie)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package release
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// wordSep matches the separators used between words of an edition.
+const wordSep = `[\s._-]+`
+
+// editions maps the canonical name of a movie edition to the pattern that
+// recognizes it in a filename. Every pattern is tried, so a single file may
+// name several editions ("Extended Remastered").
+var editions = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Director's Cut", editionPattern(`directors?'?s?` + wordSep + `cut`)},
+	{"Final Cut", editionPattern(`final` + wordSep + `cut`)},
+	{"Extended", editionPattern(`extended(?:` + wordSep + `(?:cut|edition|version))?`)},
+	{"Theatrical", editionPattern(`theatrical(?:` + wordSep + `(?:cut|edition|version))?`)},
+	{"Unrated", editionPattern(`unrated(?:` + wordSep + `(?:cut|edition))?`)},
+	{"Uncut", editionPattern(`uncut`)},
+	{"IMAX", editionPattern(`imax(?:` + wordSep + `edition)?`)},
+	{"Remastered", editionPattern(`(?:4k` + wordSep + `)?remastered`)},
+	{"Criterion", editionPattern(`criterion(?:` + wordSep + `collection)?`)},
+	{"Special Edition", editionPattern(`special` + wordSep + `edition`)},
+	{"Ultimate Edition", editionPattern(`ultimate` + wordSep + `edition`)},
+	{"Collector's Edition", editionPattern(`collector'?s` + wordSep + `edition`)},
+	{"Anniversary Edition", editionPattern(`(?:\d+(?:st|nd|rd|th)` + wordSep + `)?anniversary` + wordSep + `edition`)},
+}
+
+// editionPattern compiles an edition pattern that also consumes the separator
+// in front of the edition, so removing it leaves the surrounding words intact.
+func editionPattern(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)[\s._-]*\b` + pattern + `\b`)
+}
+
+var (
+	// plexEdition matches an edition tag in Plex notation: {edition-Director's Cut}
+	plexEdition = regexp.MustCompile(`\s*\{edition-([^}]+)\}`)
+
+	// emptyEditionBrackets matches brackets left empty after removing an edition
+	emptyEditionBrackets = regexp.MustCompile(`[\s._-]*(?:\[[\s._-]*\]|\([\s._-]*\))`)
+)
+
+// extractEdition finds the edition of a movie in a filename without
+// extension. It returns the edition ("Director's Cut", or several editions
+// such as "Extended Remastered" in the order they appear) and the name with
+// the edition removed. The edition is empty when the name does not mention one.
+//
+// Example:
+//
+//	extractEdition("Blade.Runner.1982.Final.Cut.1080p") // "Final Cut", "Blade.Runner.1982.1080p"
+func extractEdition(name string) (edition, rest string) {
+	if m := plexEdition.FindStringSubmatch(name); m != nil {
+		return strings.TrimSpace(m[1]), plexEdition.ReplaceAllString(name, "")
+	}
+
+	type found struct {
+		pos  int
+		name string
+	}
+	var matches []found
+	rest = name
+	for _, e := range editions {
+		if loc := e.pattern.FindStringIndex(name); loc != nil {
+			matches = append(matches, found{pos: loc[0], name: e.name})
+			rest = e.pattern.ReplaceAllString(rest, "")
+		}
+	}
+	if len(matches) == 0 {
+		return "", name
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].pos < matches[j].pos })
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	rest = emptyEditionBrackets.ReplaceAllString(rest, "")
+	return strings.Join(names, " "), strings.Trim(rest, " ._-")
+}
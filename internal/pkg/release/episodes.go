@@ -0,0 +1,190 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// episodeMarker is the part of a name that numbers an episode.
+type episodeMarker struct {
+	start, end int // Position of the marker in the name
+	season     int
+	episodes   []int
+	airDate    string
+	absolute   int
+	special    bool // Season 0 was named explicitly
+}
+
+// multiEpisodeSuffix matches the additional episodes after an SxxEyy marker,
+// such as "E02E03", "-E03", "-03" or ".E02". It is embedded in the marker
+// patterns as a capture group.
+const multiEpisodeSuffix = `((?:[\s._-]?e\d{1,3}|-\d{1,3}\b)*)`
+
+var (
+	// seasonEpisodePattern matches S01E02, including multi-episode files (S01E01E02, S01E01-E03)
+	seasonEpisodePattern = regexp.MustCompile(`(?i)s(\d{1,2})[\s._-]*e(\d{1,2})` + multiEpisodeSuffix)
+
+	// crossEpisodePattern matches 1x02. The numbers must stand on their own,
+	// so resolutions such as 1920x1080 do not match.
+	crossEpisodePattern = regexp.MustCompile(`(?i)(?:^|[\s._\-\[(])(\d{1,2})x(\d{1,2})(?:$|[\s._\-\])])`)
+
+	// wordEpisodePattern matches Season 1 Episode 2
+	wordEpisodePattern = regexp.MustCompile(`(?i)(?:season|s)[\s._-]*(\d{1,2})[\s._-]*(?:episode|ep|e)[\s._-]*(\d{1,2})`)
+
+	// dailyEpisodePattern matches daily shows numbered by air date: Show.2024.03.14
+	dailyEpisodePattern = regexp.MustCompile(`[\s._-]+((?:19|20)\d{2})[\s._-](\d{2})[\s._-](\d{2})(?:$|[\s._-])`)
+
+	// followingTime matches a time of day right after a date, as in the names
+	// of screen recordings and camera files: 2024-03-14 at 10.00.00
+	followingTime = regexp.MustCompile(`(?i)^[\s._-]+(?:at[\s._-]+)?\d{1,2}[.:h-]\d{2}(?:[.:-]\d{2})?(?:$|[\s._-])`)
+
+	// absoluteEpisodePattern matches anime style absolute numbering: Show - 143 [1080p]
+	absoluteEpisodePattern = regexp.MustCompile(`[\s._]+-[\s._]+((\d{1,4})(?:v\d)?)(?:$|[\s._])`)
+
+	// episodeOnlyPattern matches names that only number the episode: 03 - Title, E03, Episode 3
+	episodeOnlyPattern = regexp.MustCompile(`(?i)^(?:(?:episode|ep|e)[\s._-]*(\d{1,3})(?:$|[\s._-])|(\d{1,3})[\s._]+-)`)
+
+	// bareEpisodePattern matches names that are nothing but a number: 03. Such
+	// names are only episodes inside a season directory; elsewhere they are
+	// titles such as "300".
+	bareEpisodePattern = regexp.MustCompile(`^\d{1,3}$`)
+
+	// seasonOnlyPattern matches names of season directories and season packs: Season 02, Show.S02.1080p
+	seasonOnlyPattern = regexp.MustCompile(`(?i)(?:^|[\s._-])(?:season[\s._-]*(\d{1,2})|s(\d{1,2}))(?:$|[\s._-])`)
+
+	// specialsPattern matches the directory media servers keep season 0 in
+	specialsPattern = regexp.MustCompile(`(?i)^specials$`)
+
+	// multiEpisodePart matches a single episode of a multi-episode suffix
+	multiEpisodePart = regexp.MustCompile(`(?i)(-)?e?(\d{1,3})`)
+)
+
+// findEpisode finds the episode marker of a name. Air dates are tried first,
+// then the SxxEyy, NxNN and "Season N Episode N" styles, absolute numbering
+// and finally markers that only name the episode or the season, as used for
+// files inside season directories. Season 0 and "Specials" mark specials.
+func findEpisode(name string) (episodeMarker, bool) {
+	if m, ok := findDailyEpisode(name); ok {
+		return m, true
+	}
+	if loc := seasonEpisodePattern.FindStringSubmatchIndex(name); loc != nil {
+		season, _ := strconv.Atoi(name[loc[2]:loc[3]])
+		episode, _ := strconv.Atoi(name[loc[4]:loc[5]])
+		episodes := parseEpisodeSuffix(episode, name[loc[6]:loc[7]])
+		if episodes == nil {
+			episodes = []int{episode}
+		}
+		return episodeMarker{start: loc[0], end: loc[1], season: season, episodes: episodes, special: season == 0}, true
+	}
+	if m, ok := findCrossEpisode(name); ok {
+		return m, true
+	}
+	if loc := wordEpisodePattern.FindStringSubmatchIndex(name); loc != nil {
+		season, _ := strconv.Atoi(name[loc[2]:loc[3]])
+		episode, _ := strconv.Atoi(name[loc[4]:loc[5]])
+		return episodeMarker{start: loc[0], end: loc[1], season: season, episodes: []int{episode}, special: season == 0}, true
+	}
+	if m, ok := findAbsoluteEpisode(name); ok {
+		return m, true
+	}
+	if loc := episodeOnlyPattern.FindStringSubmatchIndex(name); loc != nil {
+		digits := loc[2:4]
+		if digits[0] < 0 {
+			digits = loc[4:6]
+		}
+		episode, _ := strconv.Atoi(name[digits[0]:digits[1]])
+		return episodeMarker{start: 0, end: digits[1], episodes: []int{episode}}, true
+	}
+	if loc := seasonOnlyPattern.FindStringSubmatchIndex(name); loc != nil {
+		digits := loc[2:4]
+		if digits[0] < 0 {
+			digits = loc[4:6]
+		}
+		season, _ := strconv.Atoi(name[digits[0]:digits[1]])
+		return episodeMarker{start: loc[0], end: digits[1], season: season, special: season == 0}, true
+	}
+	if specialsPattern.MatchString(name) {
+		return episodeMarker{start: 0, end: len(name), special: true}, true
+	}
+	return episodeMarker{}, false
+}
+
+// findCrossEpisode finds an NxNN marker. Candidates that are part of a
+// release tag, such as a resolution or codec, are skipped.
+func findCrossEpisode(name string) (episodeMarker, bool) {
+	var tokens []token
+	for _, loc := range crossEpisodePattern.FindAllStringSubmatchIndex(name, -1) {
+		if tokens == nil {
+			tokens = findTokens(name)
+		}
+		if insideToken(tokens, loc[2]) || insideToken(tokens, loc[5]-1) {
+			continue
+		}
+		season, _ := strconv.Atoi(name[loc[2]:loc[3]])
+		episode, _ := strconv.Atoi(name[loc[4]:loc[5]])
+		return episodeMarker{start: loc[2], end: loc[5], season: season, episodes: []int{episode}, special: season == 0}, true
+	}
+	return episodeMarker{}, false
+}
+
+// findDailyEpisode finds the air date of a daily show episode. The date must
+// be valid and follow the show title. Dates followed by a time of day are
+// timestamps of recordings rather than air dates.
+func findDailyEpisode(name string) (episodeMarker, bool) {
+	loc := dailyEpisodePattern.FindStringSubmatchIndex(name)
+	if loc == nil || CleanTitle(name[:loc[0]]) == "" || followingTime.MatchString(name[loc[7]:]) {
+		return episodeMarker{}, false
+	}
+	airDate := fmt.Sprintf("%s-%s-%s", name[loc[2]:loc[3]], name[loc[4]:loc[5]], name[loc[6]:loc[7]])
+	if _, err := time.Parse("2006-01-02", airDate); err != nil {
+		return episodeMarker{}, false
+	}
+	return episodeMarker{start: loc[0], end: loc[7], airDate: airDate}, true
+}
+
+// findAbsoluteEpisode finds the absolute episode number of anime style names.
+// Four-digit numbers that look like a year are not treated as episode numbers.
+func findAbsoluteEpisode(name string) (episodeMarker, bool) {
+	loc := absoluteEpisodePattern.FindStringSubmatchIndex(name)
+	if loc == nil || CleanTitle(name[:loc[0]]) == "" {
+		return episodeMarker{}, false
+	}
+	digits := name[loc[4]:loc[5]]
+	number, _ := strconv.Atoi(digits)
+	if number == 0 || (len(digits) == 4 && number >= 1900 && number < 2100) {
+		return episodeMarker{}, false
+	}
+	return episodeMarker{start: loc[0], end: loc[3], absolute: number}, true
+}
+
+// parseEpisodeSuffix returns all episodes of a marker given its first episode
+// and the suffix matched by multiEpisodeSuffix, or nil when the suffix does
+// not describe further episodes.
+//
+// A dash denotes a range ("E01-E03" is episodes 1, 2 and 3), while chained
+// markers list episodes ("E01E02").
+func parseEpisodeSuffix(first int, suffix string) []int {
+	if suffix == "" {
+		return nil
+	}
+
+	episodes := []int{first}
+	for _, part := range multiEpisodePart.FindAllStringSubmatch(suffix, -1) {
+		n, _ := strconv.Atoi(part[2])
+		last := episodes[len(episodes)-1]
+		if n <= last {
+			// Not a continuation of the episode list ("S01E05-01" is no range)
+			return nil
+		}
+		if part[1] == "-" {
+			for e := last + 1; e <= n; e++ {
+				episodes = append(episodes, e)
+			}
+		} else {
+			episodes = append(episodes, n)
+		}
+	}
+	return episodes
+}
@@ -0,0 +1,93 @@
+package release
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of extras, named after the folders media servers expect them in next
+// to the movie or show they belong to.
+const (
+	ExtraBehindTheScenes = "Behind The Scenes"
+	ExtraDeletedScenes   = "Deleted Scenes"
+	ExtraFeaturettes     = "Featurettes"
+	ExtraInterviews      = "Interviews"
+	ExtraTrailers        = "Trailers"
+)
+
+// extraKinds maps the normalized keywords of extras to their kind.
+var extraKinds = map[string]string{
+	"behindthescenes": ExtraBehindTheScenes,
+	"deletedscene":    ExtraDeletedScenes, "deletedscenes": ExtraDeletedScenes,
+	"featurette": ExtraFeaturettes, "featurettes": ExtraFeaturettes,
+	"interview": ExtraInterviews, "interviews": ExtraInterviews,
+	"trailer": ExtraTrailers, "trailers": ExtraTrailers,
+}
+
+// extraPattern matches the keywords of extras: Movie.2010.Featurette.Dreams, Movie (2010)-trailer
+var extraPattern = regexp.MustCompile(`(?i)(?:^|[\s._-])(behind[\s._-]?the[\s._-]?scenes|deleted[\s._-]?scenes?|featurettes?|interviews?|trailers?)(?:$|[\s._-])`)
+
+// leadingArticles lists the articles that cannot be a title on their own, so
+// a keyword following one belongs to the title: "The.Interview.mkv"
+var leadingArticles = map[string]bool{
+	"the": true, "a": true, "an": true,
+	"der": true, "die": true, "das": true, "le": true, "la": true, "les": true,
+	"el": true, "los": true, "las": true, "il": true, "de": true, "het": true,
+}
+
+// findExtra finds the keyword naming the kind of an extra and returns the
+// kind and position of the keyword, or an empty kind and -1 when there is
+// none.
+//
+// A keyword names an extra when it is the suffix media servers use, as in
+// "Inception (2010)-trailer", or when it follows a title of its own: more
+// than a lone article, or text carrying a year or release tags. Keywords in
+// front of a year are part of the title instead, as in
+// "The.Interview.2014.1080p", and so are keywords after a lone article, as
+// in "The.Interview.1080p".
+func findExtra(name string) (string, int) {
+	for offset := 0; offset < len(name); {
+		loc := extraPattern.FindStringSubmatchIndex(name[offset:])
+		if loc == nil {
+			break
+		}
+		start, end := offset+loc[2], offset+loc[3]
+		offset = end
+		suffix := end == len(name) && start > 0 && name[start-1] == '-'
+		title := CleanTitle(name[:start])
+		switch {
+		case suffix && title != "":
+		case title == "" || hasYear(name[end:]):
+			continue
+		case leadingArticles[strings.ToLower(title)] && !hasYear(name[:start]) && len(findTokens(name[:start])) == 0:
+			continue
+		}
+		return extraKinds[normalize(name[start:end])], start
+	}
+	return "", -1
+}
+
+// hasYear reports whether text contains a release year, delimited or bare.
+func hasYear(text string) bool {
+	if delimitedYear.MatchString(text) {
+		return true
+	}
+	for _, m := range bareYear.FindAllStringSubmatch(text, -1) {
+		if y, _ := strconv.Atoi(m[1]); y >= 1900 && y <= time.Now().Year()+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// extraFolder returns the kind of extras kept in a directory of that name,
+// or an empty string for other directories.
+func extraFolder(name string) string {
+	kind := extraKinds[normalize(name)]
+	if kind != "" && normalize(kind) == normalize(name) {
+		return kind
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package release
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxAncestors limits how many directories above a file are consulted.
+const maxAncestors = 3
+
+// ParsePath parses the release name of a file together with the names of the
+// directories above it. Downloads often keep the information in a directory
+// while the file inside is named poorly:
+//
+//	Show Name (2010)/Season 02/03 - Title.mkv
+//	Movie.Name.2012.1080p-GRP/grp-movie.mkv
+//
+// The title and year come from the level whose title is the most convincing,
+// with the file winning ties. Files in extras folders ("Trailers",
+// "Featurettes"...) are extras of the title above the folder. Season, episode and release tags missing from
+// the file are taken from the nearest directory that has them. Only the
+// directories named in path are consulted, so callers pass absolute paths to
+// use the full context.
+func ParsePath(path string) ParsedRelease {
+	r, _ := ParsePathWithRules(path, "", nil)
+	return r
+}
+
+// ParsePathWithRules parses path like ParsePath, but first tries the rules in
+// order on the filename. Rules limited to another scope or to paths outside
+// their glob are skipped; an empty scope only uses rules without a scope.
+// The groups of the first matching rule replace what the built-in patterns
+// found. It returns the name of that rule, or an empty string when no rule
+// matched.
+func ParsePathWithRules(path, scope string, rules []Rule) (ParsedRelease, string) {
+	name := filepath.Base(path)
+	file := Parse(name)
+	matched := ""
+	for _, rule := range rules {
+		if !rule.Applies(path, scope) {
+			continue
+		}
+		if fields, ok := rule.match(name); ok {
+			file = fields.apply(file)
+			matched = rule.Name
+			break
+		}
+	}
+
+	// Files in an extras folder belong to the title above the folder. Unless
+	// the filename names that title as well, it only names the extra.
+	dir := filepath.Dir(path)
+	if kind := extraFolder(filepath.Base(dir)); kind != "" {
+		if file.Extra == "" {
+			file.Title, file.Year, file.Edition = "", 0, ""
+		}
+		file.Extra = kind
+		dir = filepath.Dir(dir)
+	}
+
+	var dirs []ParsedRelease
+	for i := 0; i < maxAncestors; i++ {
+		name := filepath.Base(dir)
+		if name == "." || name == ".." || dir == filepath.Dir(dir) {
+			break
+		}
+		dirs = append(dirs, Parse(name))
+		dir = filepath.Dir(dir)
+	}
+	if matched == "" {
+		file = bareEpisode(file, dirs)
+	}
+	return mergeLevels(file, dirs), matched
+}
+
+// bareEpisode turns a file named by nothing but a number into that episode
+// when a directory above it names the season, as in "Season 02/03.mkv".
+func bareEpisode(file ParsedRelease, dirs []ParsedRelease) ParsedRelease {
+	if !bareEpisodePattern.MatchString(file.Title) || hasEpisode(file) {
+		return file
+	}
+	for _, d := range dirs {
+		if d.HasSeason() {
+			episode, _ := strconv.Atoi(file.Title)
+			file.Title = ""
+			file.Episodes = []int{episode}
+			return file
+		}
+	}
+	return file
+}
+
+// mergeLevels merges the release of a file with those of its directories,
+// nearest directory first.
+func mergeLevels(file ParsedRelease, dirs []ParsedRelease) ParsedRelease {
+	merged := file
+
+	// Directories only provide the title when they show some evidence of
+	// naming the video, unless the file has no title at all. The directory
+	// above a season directory names the show.
+	best := titleConfidence(file)
+	aboveSeason := false
+	for _, d := range dirs {
+		score := titleConfidence(d)
+		if d.Title != "" && aboveSeason {
+			score++
+		}
+		aboveSeason = d.Title == "" && d.HasSeason()
+		if score > best && (score > 1 || file.Title == "") {
+			best = score
+			merged.Title = d.Title
+			if d.Year > 0 {
+				merged.Year = d.Year
+			}
+		}
+	}
+
+	// Episode numbers belong together, so they are taken from a single level
+	if !hasEpisode(merged) {
+		for _, d := range dirs {
+			if hasEpisode(d) {
+				merged.Episodes = d.Episodes
+				merged.EpisodeTitle = d.EpisodeTitle
+				merged.AirDate = d.AirDate
+				merged.AbsoluteEpisode = d.AbsoluteEpisode
+				if !merged.HasSeason() {
+					merged.Season, merged.Special = d.Season, d.Special
+				}
+				break
+			}
+		}
+	}
+	// Rips split across discs may keep each part in a directory: CD1/movie.avi
+	if merged.Part == 0 && len(dirs) > 0 {
+		merged.Part = dirs[0].Part
+	}
+	for _, d := range dirs {
+		if !merged.HasSeason() {
+			merged.Season, merged.Special = d.Season, d.Special
+		}
+		setOnce(&merged.Edition, d.Edition)
+		setOnce(&merged.Resolution, d.Resolution)
+		setOnce(&merged.Source, d.Source)
+		setOnce(&merged.VideoCodec, d.VideoCodec)
+		setOnce(&merged.AudioCodec, d.AudioCodec)
+		setOnce(&merged.AudioChannels, d.AudioChannels)
+		setOnce(&merged.StreamingService, d.StreamingService)
+		setOnce(&merged.ReleaseGroup, d.ReleaseGroup)
+		if merged.HDR == nil {
+			merged.HDR = d.HDR
+		}
+		if merged.Languages == nil {
+			merged.Languages = d.Languages
+		}
+		merged.Proper = merged.Proper || d.Proper
+		merged.Repack = merged.Repack || d.Repack
+	}
+	return merged
+}
+
+// hasEpisode reports whether a release numbers an episode in any style.
+func hasEpisode(r ParsedRelease) bool {
+	return len(r.Episodes) > 0 || r.AirDate != "" || r.AbsoluteEpisode > 0
+}
+
+// titleConfidence rates how likely the title of a release is the real title
+// of the video. Titles accompanied by a year, an episode or season marker or
+// release tags are structured release names; single lowercase or
+// alphanumeric words are typical for obfuscated files ("grp-movie").
+func titleConfidence(r ParsedRelease) int {
+	if r.Title == "" {
+		return 0
+	}
+	score := 1
+	if r.Year > 0 {
+		score++
+	}
+	if hasEpisode(r) || r.HasSeason() {
+		score++
+	}
+	if r.Resolution != "" || r.Source != "" || r.VideoCodec != "" || r.AudioCodec != "" || r.ReleaseGroup != "" {
+		score++
+	}
+	if isObfuscated(r.Title) {
+		score--
+	}
+	return score
+}
+
+// isObfuscated reports whether a title is a single word that is lowercase or
+// contains digits.
+func isObfuscated(title string) bool {
+	if strings.Contains(title, " ") {
+		return false
+	}
+	return title == strings.ToLower(title) || strings.IndexFunc(title, unicode.IsDigit) >= 0
+}
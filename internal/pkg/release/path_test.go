@@ -0,0 +1,112 @@
+package release
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want ParsedRelease
+	}{
+		{
+			name: "Season directory below show directory",
+			path: "TV/Show Name (2010)/Season 02/03 - Title.mkv",
+			want: ParsedRelease{Title: "Show Name", Year: 2010, Season: 2, Episodes: []int{3}, EpisodeTitle: "Title", Container: "mkv"},
+		},
+		{
+			name: "Show directory without year",
+			path: "TV/Show Name/Season 1/E05.mkv",
+			want: ParsedRelease{Title: "Show Name", Season: 1, Episodes: []int{5}, Container: "mkv"},
+		},
+		{
+			name: "Bare episode number in season directory",
+			path: "TV/Show Name/Season 02/07.mkv",
+			want: ParsedRelease{Title: "Show Name", Season: 2, Episodes: []int{7}, Container: "mkv"},
+		},
+		{
+			name: "Bare number outside season directory",
+			path: "dl/300.mkv",
+			want: ParsedRelease{Title: "300", Container: "mkv"},
+		},
+		{
+			name: "Obfuscated movie in release directory",
+			path: "Downloads/Movie.Name.2012.1080p.BluRay.x264-GRP/grp-movie.mkv",
+			want: ParsedRelease{
+				Title: "Movie Name", Year: 2012,
+				Resolution: "1080p", Source: "BluRay", VideoCodec: "AVC", ReleaseGroup: "GRP", Container: "mkv",
+			},
+		},
+		{
+			name: "Obfuscated episode in season pack",
+			path: "Show.Name.S02.1080p.WEB-DL.DDP5.1.H.264-GRP/grp-sn-s02e03-1080p.mkv",
+			want: ParsedRelease{
+				Title: "Show Name", Season: 2, Episodes: []int{3},
+				Resolution: "1080p", Source: "WEB-DL", VideoCodec: "AVC", AudioCodec: "DDP", AudioChannels: "5.1",
+				ReleaseGroup: "GRP", Container: "mkv",
+			},
+		},
+		{
+			name: "Single episode release with obfuscated file",
+			path: "Show.Name.S01E04.720p.HDTV.x264-GRP/abc123.mkv",
+			want: ParsedRelease{
+				Title: "Show Name", Season: 1, Episodes: []int{4},
+				Resolution: "720p", Source: "HDTV", VideoCodec: "AVC", ReleaseGroup: "GRP", Container: "mkv",
+			},
+		},
+		{
+			name: "Movie directory adds the year",
+			path: "Movies/Heat (1995)/Heat.mkv",
+			want: ParsedRelease{Title: "Heat", Year: 1995, Container: "mkv"},
+		},
+		{
+			name: "Well named file wins",
+			path: "Show.Name.S02.1080p.WEB-DL-GRP/Show.Name.S02E03.1080p.WEB-DL-GRP.mkv",
+			want: ParsedRelease{
+				Title: "Show Name", Season: 2, Episodes: []int{3},
+				Resolution: "1080p", Source: "WEB-DL", ReleaseGroup: "GRP", Container: "mkv",
+			},
+		},
+		{
+			name: "Generic directories are ignored",
+			path: "Downloads/Movies/heat.mkv",
+			want: ParsedRelease{Title: "heat", Container: "mkv"},
+		},
+		{
+			name: "Specials directory",
+			path: "TV/Show Name/Specials/05 - Christmas Special.mkv",
+			want: ParsedRelease{Title: "Show Name", Special: true, Episodes: []int{5}, EpisodeTitle: "Christmas Special", Container: "mkv"},
+		},
+		{
+			name: "Season 00 directory",
+			path: "TV/Show Name/Season 00/E02.mkv",
+			want: ParsedRelease{Title: "Show Name", Special: true, Episodes: []int{2}, Container: "mkv"},
+		},
+		{
+			name: "Extras folder",
+			path: "Movies/Inception (2010)/Deleted Scenes/Limbo Extended.mkv",
+			want: ParsedRelease{Title: "Inception", Year: 2010, Extra: ExtraDeletedScenes, Container: "mkv"},
+		},
+		{
+			name: "Part directory",
+			path: "Movies/Old Movie (2004)/CD1/old-movie.avi",
+			want: ParsedRelease{Title: "Old Movie", Year: 2004, Part: 1, Container: "avi"},
+		},
+		{
+			name: "Bare filename",
+			path: "The.Matrix.1999.mkv",
+			want: ParsedRelease{Title: "The Matrix", Year: 1999, Container: "mkv"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParsePath(filepath.FromSlash(tt.path)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePath(%q) = %+v\nwant %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
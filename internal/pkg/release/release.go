@@ -0,0 +1,326 @@
+// Package release parses scene and P2P release names of video files.
+//
+// A release name such as "The.Show.S01E05.Pilot.1080p.AMZN.WEB-DL.DDP5.1.H.264-GRP.mkv"
+// carries the title and episode of a video together with tags describing the
+// release. Parse splits the name into these parts, so the title used for a
+// metadata search contains nothing but the title.
+package release
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedRelease holds the information found in a release name. Fields are
+// left empty when the name does not mention them.
+type ParsedRelease struct {
+	Title   string
+	Year    int
+	Edition string // Edition of a movie: "Director's Cut", "Extended Remastered"...
+	Extra   string // Kind of bonus material, named after its extras folder: ExtraTrailers, ExtraFeaturettes...
+	Part    int    // Part of a movie split across files: CD1, Disc 2, Part 1
+
+	Season          int
+	Special         bool  // Season 0, the specials of a show: S00E05, "Season 00", "Specials"
+	Episodes        []int // Episodes of the file in order; more than one for multi-episode files
+	EpisodeTitle    string
+	AirDate         string // Air date of a daily show episode (YYYY-MM-DD)
+	AbsoluteEpisode int    // Absolute episode number, counted across all seasons
+
+	Resolution       string   // "2160p", "1080p", "720p"...
+	Source           string   // "BluRay", "WEB-DL", "WEBRip", "HDTV", "DVD"...
+	VideoCodec       string   // "AVC", "HEVC", "XviD"...
+	AudioCodec       string   // "DDP", "DTS-HD MA", "TrueHD Atmos"...
+	AudioChannels    string   // "2.0", "5.1", "7.1"
+	HDR              []string // "HDR10", "HDR10+", "DV", "HLG" or "HDR"
+	StreamingService string   // "Netflix", "Amazon", "Disney+"...
+	ReleaseGroup     string
+	Languages        []string // ISO 639-1 codes; "multi" and "dual" for multi-language releases
+	Proper           bool
+	Repack           bool
+	Container        string // Container named by the file extension: "mkv", "mp4"...
+}
+
+// Episode returns the first episode of the file, or 0 when the name does not
+// number the episode by season.
+func (r ParsedRelease) Episode() int {
+	if len(r.Episodes) == 0 {
+		return 0
+	}
+	return r.Episodes[0]
+}
+
+// IsEpisode reports whether the name identifies a TV episode, by season and
+// episode, by air date or by absolute episode number. Specials count as
+// episodes of season 0.
+func (r ParsedRelease) IsEpisode() bool {
+	return (r.HasSeason() && r.Episode() > 0) || r.AirDate != "" || r.AbsoluteEpisode > 0
+}
+
+// HasSeason reports whether the name identifies a season, including season 0.
+func (r ParsedRelease) HasSeason() bool {
+	return r.Season > 0 || r.Special
+}
+
+// containers lists the file extensions of video containers. Only these are
+// removed from a name, so names of directories ("Movie.2012.1080p-GRP") keep
+// their last part.
+var containers = map[string]bool{
+	"mkv": true, "mp4": true, "m4v": true, "avi": true, "mov": true, "wmv": true,
+	"mpg": true, "mpeg": true, "ts": true, "m2ts": true, "mts": true, "webm": true,
+	"flv": true, "vob": true, "ogm": true, "divx": true, "3gp": true, "iso": true,
+}
+
+var (
+	// delimitedYear matches a year in parentheses or square brackets
+	delimitedYear = regexp.MustCompile(`\((\d{4})\)|\[(\d{4})\]`)
+
+	// leadingGroup matches the release group in front of anime releases: [Group] Show - 01
+	leadingGroup = regexp.MustCompile(`^\[([^\]]+)\][\s._-]*`)
+
+	// trailingGroup matches the release group at the end of scene releases: Show.S01E01.720p.HDTV.x264-GRP
+	trailingGroup = regexp.MustCompile(`-([A-Za-z0-9]+)$`)
+
+	// bareYear matches a four-digit number that may be a year: The.Matrix.1999
+	bareYear = regexp.MustCompile(`(?:^|[\s._-])(\d{4})(?:$|[\s._-])`)
+
+	// partPattern matches the part of a movie split across files: CD1, Disc 2, Part 1, pt2
+	partPattern = regexp.MustCompile(`(?i)(?:^|[\s._-])(cd|dis[ck]|part|pt)[\s._-]*(\d{1,2})(?:$|[\s._-])`)
+
+	// followingNumber matches a number right after a year, as in dates (2024.03.14)
+	followingNumber = regexp.MustCompile(`^[\s._-]\d{1,2}(?:$|[\s._-])`)
+
+	// whitespace matches runs of whitespace in cleaned titles
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+// Parse parses a release name, the base name of a file or directory. A video
+// file extension is recorded as the container and removed before parsing.
+//
+// Example:
+//
+//	r := release.Parse("Breaking.Bad.S01E05.Gray.Matter.720p.BluRay.x264-DEMAND.mkv")
+//	// r.Title "Breaking Bad", r.Season 1, r.Episodes [5], r.EpisodeTitle "Gray Matter",
+//	// r.Resolution "720p", r.Source "BluRay", r.VideoCodec "AVC", r.ReleaseGroup "DEMAND"
+func Parse(name string) ParsedRelease {
+	var r ParsedRelease
+	name, r.Container = splitContainer(name)
+
+	if m := leadingGroup.FindStringSubmatch(name); m != nil && !isTag(m[1]) {
+		r.ReleaseGroup = strings.TrimSpace(m[1])
+		name = name[len(m[0]):]
+	}
+
+	// Editions are named by movies; for episodes such words belong to the episode title
+	marker, hasMarker := findEpisode(name)
+	if !hasMarker {
+		r.Edition, name = extractEdition(name)
+	}
+	tokens := findTokens(name)
+
+	// The title ends at the first release tag, year, episode marker, part or
+	// the keyword of an extra, which is followed by the name of the extra
+	titleEnd := len(name)
+	for _, t := range tokens {
+		if !t.weak && t.start < titleEnd {
+			titleEnd = t.start
+		}
+	}
+	extraStart := -1
+	if len(marker.episodes) == 0 {
+		r.Extra, extraStart = findExtra(name)
+		if extraStart >= 0 && extraStart < titleEnd {
+			titleEnd = extraStart
+		}
+	}
+	if !hasMarker {
+		var partStart int
+		r.Part, partStart = findPart(name)
+		if partStart >= 0 && partStart < titleEnd {
+			titleEnd = partStart
+		}
+	}
+	yearStart := -1
+	if loc := delimitedYear.FindStringSubmatchIndex(name); loc != nil {
+		yearStart = loc[0]
+		for i := 2; i < len(loc); i += 2 {
+			if loc[i] >= 0 {
+				r.Year, _ = strconv.Atoi(name[loc[i]:loc[i+1]])
+			}
+		}
+	} else if !hasMarker {
+		yearStart, r.Year = findBareYear(name[:titleEnd])
+	}
+	if yearStart >= 0 && yearStart < titleEnd {
+		titleEnd = yearStart
+	}
+	if hasMarker && marker.start < titleEnd {
+		titleEnd = marker.start
+	}
+	// Weak tags right in front of the first strong tag belong to the release
+	// tags as well: Movie.2012.PROPER.MULTi.1080p
+	for i := len(tokens) - 1; i >= 0 && titleEnd < len(name); i-- {
+		t := tokens[i]
+		if t.weak && t.start < titleEnd && strings.Trim(name[t.end:titleEnd], " ._-[](){}") == "" {
+			titleEnd = t.start
+		}
+	}
+
+	// Release groups follow the tags of a scene release; extras named the
+	// Plex way end in their keyword instead: Movie (2010)-trailer. Dashes
+	// within the episode marker join episodes or the parts of a date.
+	groupStart := len(name)
+	if r.ReleaseGroup == "" && titleEnd < len(name) {
+		if loc := trailingGroup.FindStringSubmatchIndex(name); loc != nil && loc[0] > titleEnd && loc[2] != extraStart &&
+			!insideToken(tokens, loc[2]) && (!hasMarker || loc[0] >= marker.end) {
+			r.ReleaseGroup = name[loc[2]:loc[3]]
+			groupStart = loc[0]
+		}
+	}
+
+	// Weak tags only count once the title has ended, so titles such as
+	// "The Proper Way" keep their words
+	p := &parser{release: &r}
+	episodeTitleEnd := groupStart
+	covered := 0
+	for _, t := range tokens {
+		if t.start >= groupStart || t.start < covered || (t.weak && t.start < titleEnd) {
+			continue
+		}
+		covered = t.end
+		t.apply(p, t.match)
+		if hasMarker && t.start >= marker.end && t.start < episodeTitleEnd {
+			episodeTitleEnd = t.start
+		}
+	}
+	p.finish()
+
+	r.Title = CleanTitle(name[:titleEnd])
+	if hasMarker {
+		r.Season = marker.season
+		r.Special = marker.special
+		r.Episodes = marker.episodes
+		r.AirDate = marker.airDate
+		r.AbsoluteEpisode = marker.absolute
+		for _, end := range []int{yearStart, extraStart} {
+			if end > marker.end && end < episodeTitleEnd {
+				episodeTitleEnd = end
+			}
+		}
+		if marker.end < episodeTitleEnd {
+			r.EpisodeTitle = CleanTitle(name[marker.end:episodeTitleEnd])
+		}
+	}
+	return r
+}
+
+// splitContainer removes a video file extension from name and returns it as
+// the container.
+func splitContainer(name string) (string, string) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	if !containers[ext] {
+		return name, ""
+	}
+	return name[:len(name)-len(ext)-1], ext
+}
+
+// findBareYear finds the release year in the title part of a movie name
+// that does not put the year in brackets, such as "The.Matrix.1999". It
+// returns the position and value of the year, or -1 and 0 when there is none.
+//
+// Numbers in titles look like years too ("2001.A.Space.Odyssey.1968",
+// "1917.2019", "Blade.Runner.2049.2017"), so the last plausible year wins.
+// A year is plausible when it lies between 1900 and next year, follows at
+// least one word of the title and is not part of a date or number sequence.
+func findBareYear(name string) (int, int) {
+	start, year := -1, 0
+	latest := time.Now().Year() + 1
+	for offset := 0; offset < len(name); {
+		loc := bareYear.FindStringSubmatchIndex(name[offset:])
+		if loc == nil {
+			break
+		}
+		yStart, yEnd := offset+loc[2], offset+loc[3]
+		offset = yEnd
+		y, _ := strconv.Atoi(name[yStart:yEnd])
+		if y < 1900 || y > latest || CleanTitle(name[:yStart]) == "" || followingNumber.MatchString(name[yEnd:]) {
+			continue
+		}
+		start, year = yStart, y
+	}
+	return start, year
+}
+
+// findPart finds the part of a movie split across files and returns the part
+// and its position, or 0 and -1 when the name is not a part. Names consisting
+// of the part alone, such as the "CD1" directory of a rip, are parts as well.
+//
+// "Part" also appears in titles, as in "Deathly.Hallows.Part.1.2010", so it
+// only marks a part when no year follows.
+func findPart(name string) (int, int) {
+	for offset := 0; offset < len(name); {
+		loc := partPattern.FindStringSubmatchIndex(name[offset:])
+		if loc == nil {
+			break
+		}
+		word := strings.ToLower(name[offset+loc[2] : offset+loc[3]])
+		part, _ := strconv.Atoi(name[offset+loc[4] : offset+loc[5]])
+		start, end := offset+loc[2], offset+loc[5]
+		offset = end
+		switch {
+		case part == 0:
+			continue
+		case CleanTitle(name[:start]) == "" && CleanTitle(name[end:]) != "":
+			continue
+		case (word == "part" || word == "pt") && hasYear(name[end:]):
+			continue
+		}
+		return part, start
+	}
+	return 0, -1
+}
+
+// CleanTitle turns the title part of a release name into a search title:
+// dots, underscores and brackets become spaces and surrounding separators
+// are removed.
+func CleanTitle(title string) string {
+	title = strings.NewReplacer(
+		"[", " ",
+		"]", " ",
+		"(", " ",
+		")", " ",
+		"{", " ",
+		"}", " ",
+		".", " ",
+		"_", " ",
+	).Replace(title)
+	title = whitespace.ReplaceAllString(strings.TrimSpace(title), " ")
+	return strings.Trim(title, " -")
+}
+
+// isTag reports whether text in brackets is a release tag or a year rather
+// than the name of a release group.
+func isTag(text string) bool {
+	if delimitedYear.MatchString("(" + text + ")") {
+		return true
+	}
+	for _, t := range findTokens(text) {
+		if t.start == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// insideToken reports whether pos lies within one of the tokens.
+func insideToken(tokens []token, pos int) bool {
+	for _, t := range tokens {
+		if pos >= t.start && pos < t.end {
+			return true
+		}
+	}
+	return false
+}
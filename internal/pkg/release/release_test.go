@@ -0,0 +1,214 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		want ParsedRelease
+	}{
+		{
+			name: "Breaking.Bad.S01E05.Gray.Matter.720p.BluRay.x264-DEMAND.mkv",
+			want: ParsedRelease{
+				Title: "Breaking Bad", Season: 1, Episodes: []int{5}, EpisodeTitle: "Gray Matter",
+				Resolution: "720p", Source: "BluRay", VideoCodec: "AVC", ReleaseGroup: "DEMAND", Container: "mkv",
+			},
+		},
+		{
+			name: "The.Mandalorian.S02E01.2160p.DSNP.WEB-DL.DDP5.1.Atmos.HDR10.DV.H.265-FLUX.mkv",
+			want: ParsedRelease{
+				Title: "The Mandalorian", Season: 2, Episodes: []int{1},
+				Resolution: "2160p", Source: "WEB-DL", VideoCodec: "HEVC", AudioCodec: "DDP Atmos", AudioChannels: "5.1",
+				HDR: []string{"HDR10", "DV"}, StreamingService: "Disney+", ReleaseGroup: "FLUX", Container: "mkv",
+			},
+		},
+		{
+			name: "Dune (2021) 2160p UHD BluRay REMUX TrueHD 7.1 Atmos HEVC HDR10+.mkv",
+			want: ParsedRelease{
+				Title: "Dune", Year: 2021,
+				Resolution: "2160p", Source: "BluRay", VideoCodec: "HEVC", AudioCodec: "TrueHD Atmos", AudioChannels: "7.1",
+				HDR: []string{"HDR10+"}, Container: "mkv",
+			},
+		},
+		{
+			name: "Lost.S01E01-E02.Pilot.GERMAN.DL.720p.HDTV.x264.REPACK-GRP",
+			want: ParsedRelease{
+				Title: "Lost", Season: 1, Episodes: []int{1, 2}, EpisodeTitle: "Pilot",
+				Resolution: "720p", Source: "HDTV", VideoCodec: "AVC", ReleaseGroup: "GRP",
+				Languages: []string{"de"}, Repack: true,
+			},
+		},
+		{
+			name: "Some.Movie.2012.PROPER.MULTi.1080p.BluRay.DTS-HD.MA.5.1.x264-GRP.mkv",
+			want: ParsedRelease{
+				Title: "Some Movie", Year: 2012,
+				Resolution: "1080p", Source: "BluRay", VideoCodec: "AVC", AudioCodec: "DTS-HD MA", AudioChannels: "5.1",
+				ReleaseGroup: "GRP", Languages: []string{"multi"}, Proper: true, Container: "mkv",
+			},
+		},
+		{
+			name: "[SubsPlease] One Piece - 1071 (1080p) [AAC].mkv",
+			want: ParsedRelease{
+				Title: "One Piece", AbsoluteEpisode: 1071,
+				Resolution: "1080p", AudioCodec: "AAC", ReleaseGroup: "SubsPlease", Container: "mkv",
+			},
+		},
+		{
+			name: "The.Daily.Show.2024.03.14.Guest.Name.720p.WEB.h264-GRP.mkv",
+			want: ParsedRelease{
+				Title: "The Daily Show", AirDate: "2024-03-14", EpisodeTitle: "Guest Name",
+				Resolution: "720p", Source: "WEB", VideoCodec: "AVC", ReleaseGroup: "GRP", Container: "mkv",
+			},
+		},
+		{
+			name: "Show.S01E01-E03.mkv",
+			want: ParsedRelease{Title: "Show", Season: 1, Episodes: []int{1, 2, 3}, Container: "mkv"},
+		},
+		{
+			name: "Show 2024-03-14.mkv",
+			want: ParsedRelease{Title: "Show", AirDate: "2024-03-14", Container: "mkv"},
+		},
+		{
+			name: "Show.S03E07.1080p.AMZN.WEBRip.DD+5.1.x265.10bit",
+			want: ParsedRelease{
+				Title: "Show", Season: 3, Episodes: []int{7},
+				Resolution: "1080p", Source: "WEBRip", VideoCodec: "HEVC", AudioCodec: "DDP", AudioChannels: "5.1",
+				StreamingService: "Amazon",
+			},
+		},
+		{
+			name: "Movie.Name.2012.1080p-GRP",
+			want: ParsedRelease{Title: "Movie Name", Year: 2012, Resolution: "1080p", ReleaseGroup: "GRP"},
+		},
+		{
+			name: "Old Movie [1962] DVDRip XviD AC3.avi",
+			want: ParsedRelease{Title: "Old Movie", Year: 1962, Source: "DVD", VideoCodec: "XviD", AudioCodec: "DD", Container: "avi"},
+		},
+		{
+			name: "The Proper Way NF.mp4",
+			want: ParsedRelease{Title: "The Proper Way NF", Container: "mp4"},
+		},
+		{
+			name: "Spider-Man - Homecoming (2017).mkv",
+			want: ParsedRelease{Title: "Spider-Man - Homecoming", Year: 2017, Container: "mkv"},
+		},
+		{
+			name: "Doctor.Who.S00E05.The.Christmas.Invasion.720p.mkv",
+			want: ParsedRelease{
+				Title: "Doctor Who", Special: true, Episodes: []int{5}, EpisodeTitle: "The Christmas Invasion",
+				Resolution: "720p", Container: "mkv",
+			},
+		},
+		{
+			name: "Inception.2010.Featurette.Dreams.1080p.mkv",
+			want: ParsedRelease{Title: "Inception", Year: 2010, Extra: ExtraFeaturettes, Resolution: "1080p", Container: "mkv"},
+		},
+		{
+			name: "Inception (2010)-trailer.mp4",
+			want: ParsedRelease{Title: "Inception", Year: 2010, Extra: ExtraTrailers, Container: "mp4"},
+		},
+		{
+			name: "The.Interview.2014.1080p.mkv",
+			want: ParsedRelease{Title: "The Interview", Year: 2014, Resolution: "1080p", Container: "mkv"},
+		},
+		{
+			name: "The.Interview.1080p.BluRay.mkv",
+			want: ParsedRelease{Title: "The Interview", Resolution: "1080p", Source: "BluRay", Container: "mkv"},
+		},
+		{
+			name: "Interview with the Vampire (1994).mkv",
+			want: ParsedRelease{Title: "Interview with the Vampire", Year: 1994, Container: "mkv"},
+		},
+		{
+			name: "Old.Movie.2004.CD2.DVDRip.XviD-GRP.avi",
+			want: ParsedRelease{
+				Title: "Old Movie", Year: 2004, Part: 2,
+				Source: "DVD", VideoCodec: "XviD", ReleaseGroup: "GRP", Container: "avi",
+			},
+		},
+		{
+			name: "Old Movie (2004) - Part 1.mkv",
+			want: ParsedRelease{Title: "Old Movie", Year: 2004, Part: 1, Container: "mkv"},
+		},
+		{
+			name: "Harry.Potter.and.the.Deathly.Hallows.Part.1.2010.1080p.mkv",
+			want: ParsedRelease{Title: "Harry Potter and the Deathly Hallows Part 1", Year: 2010, Resolution: "1080p", Container: "mkv"},
+		},
+		{
+			name: "Some.Movie.2020.1920x1080.WEB-DL.mkv",
+			want: ParsedRelease{Title: "Some Movie", Year: 2020, Resolution: "1080p", Source: "WEB-DL", Container: "mkv"},
+		},
+		{
+			name: "Screen Recording 1280x720.mp4",
+			want: ParsedRelease{Title: "Screen Recording", Resolution: "720p", Container: "mp4"},
+		},
+		{
+			name: "Show.Name.1x02.720p.mkv",
+			want: ParsedRelease{Title: "Show Name", Season: 1, Episodes: []int{2}, Resolution: "720p", Container: "mkv"},
+		},
+		{
+			name: "Movie.Title.2020.1080p.WEB-DL.mkv",
+			want: ParsedRelease{Title: "Movie Title", Year: 2020, Resolution: "1080p", Source: "WEB-DL", Container: "mkv"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBareYear(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantTitle string
+		wantYear  int
+	}{
+		{name: "The.Matrix.1999.1080p.BluRay.mkv", wantTitle: "The Matrix", wantYear: 1999},
+		{name: "The Matrix 1999.mkv", wantTitle: "The Matrix", wantYear: 1999},
+		{name: "2001.A.Space.Odyssey.1968.1080p.mkv", wantTitle: "2001 A Space Odyssey", wantYear: 1968},
+		{name: "1917.2019.2160p.UHD.BluRay.x265.mkv", wantTitle: "1917", wantYear: 2019},
+		{name: "Blade.Runner.2049.2017.1080p.mkv", wantTitle: "Blade Runner 2049", wantYear: 2017},
+		{name: "2012.2009.720p.mkv", wantTitle: "2012", wantYear: 2009},
+		{name: "1917.mkv", wantTitle: "1917"},
+		{name: "Blade.Runner.2049.mkv", wantTitle: "Blade Runner 2049"},
+		{name: "Movie.1080.mkv", wantTitle: "Movie 1080"},
+		{name: "Show.2024.13.45.mkv", wantTitle: "Show 2024 13 45"},
+		{name: "Breaking.Bad.2008.S01E05.mkv", wantTitle: "Breaking Bad 2008"},
+		{name: "Blade Runner 2049 (2017).mkv", wantTitle: "Blade Runner 2049", wantYear: 2017},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.name)
+			if got.Title != tt.wantTitle || got.Year != tt.wantYear {
+				t.Errorf("Parse() = %q (%d), want %q (%d)", got.Title, got.Year, tt.wantTitle, tt.wantYear)
+			}
+		})
+	}
+}
+
+func TestParseEpisodeSuffix(t *testing.T) {
+	tests := []struct {
+		suffix string
+		want   []int
+	}{
+		{suffix: "", want: nil},
+		{suffix: "E02", want: []int{1, 2}},
+		{suffix: "-E03", want: []int{1, 2, 3}},
+		{suffix: "E02E05", want: []int{1, 2, 5}},
+		{suffix: "-01", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := parseEpisodeSuffix(1, tt.suffix); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseEpisodeSuffix(1, %q) = %v, want %v", tt.suffix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package release
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule scopes limit a rule to TV episodes or movies.
+const (
+	ScopeTV    = "tv"
+	ScopeMovie = "movie"
+)
+
+// RuleGroups lists the named groups a rule pattern may capture.
+var RuleGroups = []string{"title", "year", "season", "episode", "episode_title", "air_date", "edition"}
+
+// Rule is a user-defined pattern for naming schemes the built-in patterns do
+// not know, such as the recordings of a DVR:
+//
+//	^(?P<title>.+?)_(?P<air_date>\d{8})_\d{4}_(?P<episode_title>.+)$
+//
+// The pattern is matched against the filename without its extension and
+// names the parts it captures with the groups in RuleGroups.
+type Rule struct {
+	Name  string
+	Scope string // ScopeTV, ScopeMovie or empty for both
+	Path  string // Glob the path must match; empty matches every path
+
+	pattern *regexp.Regexp
+}
+
+// NewRule compiles a rule and checks its scope, path glob and groups.
+func NewRule(name, pattern, scope, path string) (Rule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("parse rule %q: invalid pattern: %v", name, err)
+	}
+	if scope != "" && scope != ScopeTV && scope != ScopeMovie {
+		return Rule{}, fmt.Errorf("parse rule %q: unknown scope %q (supported: %s, %s)", name, scope, ScopeTV, ScopeMovie)
+	}
+	if _, err := filepath.Match(path, ""); err != nil {
+		return Rule{}, fmt.Errorf("parse rule %q: invalid path glob %q: %v", name, path, err)
+	}
+
+	groups := 0
+	for _, group := range re.SubexpNames()[1:] {
+		if group == "" {
+			continue
+		}
+		if !slices.Contains(RuleGroups, group) {
+			return Rule{}, fmt.Errorf("parse rule %q: unknown group %q (supported: %s)", name, group, strings.Join(RuleGroups, ", "))
+		}
+		groups++
+	}
+	if groups == 0 {
+		return Rule{}, fmt.Errorf("parse rule %q: pattern has no named groups (supported: %s)", name, strings.Join(RuleGroups, ", "))
+	}
+	return Rule{Name: name, Scope: scope, Path: path, pattern: re}, nil
+}
+
+// Applies reports whether the rule may be used for a file in the given
+// scope. A glob without a path separator is matched against the filename,
+// otherwise against as many trailing path elements as the glob has.
+func (r Rule) Applies(path, scope string) bool {
+	if r.Scope != "" && r.Scope != scope {
+		return false
+	}
+	if r.Path == "" {
+		return true
+	}
+	glob := filepath.ToSlash(r.Path)
+	elements := strings.Split(filepath.ToSlash(path), "/")
+	if n := strings.Count(glob, "/") + 1; n < len(elements) {
+		elements = elements[len(elements)-n:]
+	}
+	ok, _ := filepath.Match(glob, strings.Join(elements, "/"))
+	return ok
+}
+
+// ruleFields holds the parts captured by a rule; only captured parts are set.
+type ruleFields struct {
+	values  map[string]string
+	year    int
+	season  int
+	episode int
+	airDate string
+}
+
+// match applies the rule to a filename. Captured numbers and dates must be
+// valid for the rule to match.
+func (r Rule) match(name string) (ruleFields, bool) {
+	name, _ = splitContainer(name)
+	m := r.pattern.FindStringSubmatch(name)
+	if m == nil {
+		return ruleFields{}, false
+	}
+
+	fields := ruleFields{values: make(map[string]string)}
+	for i, group := range r.pattern.SubexpNames() {
+		if group != "" && m[i] != "" {
+			fields.values[group] = m[i]
+		}
+	}
+	for group, target := range map[string]*int{"year": &fields.year, "season": &fields.season, "episode": &fields.episode} {
+		if value, ok := fields.values[group]; ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return ruleFields{}, false
+			}
+			*target = n
+		}
+	}
+	if value, ok := fields.values["air_date"]; ok {
+		date, ok := parseAirDate(value)
+		if !ok {
+			return ruleFields{}, false
+		}
+		fields.airDate = date
+	}
+	return fields, true
+}
+
+// apply replaces the title and year of a release and the other parts that the
+// rule captured. Capturing any episode part replaces the episode found by the
+// built-in patterns, and a captured season 0 marks a special.
+func (f ruleFields) apply(r ParsedRelease) ParsedRelease {
+	// The rule describes the whole name, so without a captured title the
+	// title is taken from the directories
+	r.Title, r.Year = CleanTitle(f.values["title"]), f.year
+	if edition, ok := f.values["edition"]; ok {
+		r.Edition = CleanTitle(edition)
+	}
+
+	_, hasSeason := f.values["season"]
+	_, hasEpisode := f.values["episode"]
+	if hasSeason || hasEpisode || f.airDate != "" {
+		r.Season, r.Episodes, r.AirDate, r.AbsoluteEpisode, r.EpisodeTitle = f.season, nil, f.airDate, 0, ""
+		r.Special = hasSeason && f.season == 0
+		if hasEpisode {
+			r.Episodes = []int{f.episode}
+		}
+	}
+	if episodeTitle, ok := f.values["episode_title"]; ok {
+		r.EpisodeTitle = CleanTitle(episodeTitle)
+	}
+	return r
+}
+
+// airDateDigits matches the parts of an air date: 20240314, 2024-03-14, 2024.03.14
+var airDateDigits = regexp.MustCompile(`^(\d{4})[\s._-]?(\d{2})[\s._-]?(\d{2})$`)
+
+// parseAirDate returns a captured air date as YYYY-MM-DD.
+func parseAirDate(value string) (string, bool) {
+	m := airDateDigits.FindStringSubmatch(value)
+	if m == nil {
+		return "", false
+	}
+	date := fmt.Sprintf("%s-%s-%s", m[1], m[2], m[3])
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", false
+	}
+	return date, true
+}
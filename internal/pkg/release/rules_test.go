@@ -0,0 +1,120 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		scope   string
+		path    string
+		wantErr bool
+	}{
+		{name: "Valid", pattern: `^(?P<title>.+?)_(?P<air_date>\d{8})$`, scope: ScopeTV, path: "DVR/*.ts"},
+		{name: "Invalid pattern", pattern: `^(?P<title>.+`, wantErr: true},
+		{name: "Unknown scope", pattern: `^(?P<title>.+)$`, scope: "music", wantErr: true},
+		{name: "Invalid glob", pattern: `^(?P<title>.+)$`, path: "[", wantErr: true},
+		{name: "Unknown group", pattern: `^(?P<show>.+)$`, wantErr: true},
+		{name: "No named groups", pattern: `^(.+)$`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRule(tt.name, tt.pattern, tt.scope, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePathWithRules(t *testing.T) {
+	mustRule := func(name, pattern, scope, path string) Rule {
+		rule, err := NewRule(name, pattern, scope, path)
+		if err != nil {
+			t.Fatalf("NewRule() error = %v", err)
+		}
+		return rule
+	}
+	dvr := mustRule("dvr", `^(?P<title>.+?)_(?P<air_date>\d{8})_\d{4}_(?P<episode_title>.+)$`, ScopeTV, "")
+	concerts := mustRule("concerts", `^(?P<title>.+) - (?P<edition>Live)$`, ScopeMovie, "Concerts/*")
+	collection := mustRule("collection", `^(?P<season>\d)-(?P<episode>\d{2}) (?P<episode_title>.+)$`, "", "")
+
+	tests := []struct {
+		name     string
+		path     string
+		scope    string
+		wantRule string
+		want     ParsedRelease
+	}{
+		{
+			name:     "DVR recording",
+			path:     "/recordings/Show_20240314_2100_Episode Title.ts",
+			scope:    ScopeTV,
+			wantRule: "dvr",
+			want:     ParsedRelease{Title: "Show", AirDate: "2024-03-14", EpisodeTitle: "Episode Title", Container: "ts"},
+		},
+		{
+			name:  "Rule outside its scope",
+			path:  "/recordings/Show_20240314_2100_Episode Title.ts",
+			scope: ScopeMovie,
+			want:  ParsedRelease{Title: "Show 20240314 2100 Episode Title", Container: "ts"},
+		},
+		{
+			name:  "Invalid air date",
+			path:  "/recordings/Show_20241314_2100_Episode Title.ts",
+			scope: ScopeTV,
+			want:  ParsedRelease{Title: "Show 20241314 2100 Episode Title", Container: "ts"},
+		},
+		{
+			name:     "Path glob matches",
+			path:     "/media/Concerts/Band - Live.mkv",
+			scope:    ScopeMovie,
+			wantRule: "concerts",
+			want:     ParsedRelease{Title: "Band", Edition: "Live", Container: "mkv"},
+		},
+		{
+			name:  "Path glob does not match",
+			path:  "/media/Movies/Band - Live.mkv",
+			scope: ScopeMovie,
+			want:  ParsedRelease{Title: "Band - Live", Container: "mkv"},
+		},
+		{
+			name:     "Season and episode keep the directory title",
+			path:     "/media/Some Show/1-05 The Title.mkv",
+			scope:    ScopeTV,
+			wantRule: "collection",
+			want:     ParsedRelease{Title: "Some Show", Season: 1, Episodes: []int{5}, EpisodeTitle: "The Title", Container: "mkv"},
+		},
+		{
+			name:     "Captured season 0 is a special",
+			path:     "/media/Some Show/0-05 The Title.mkv",
+			scope:    ScopeTV,
+			wantRule: "collection",
+			want:     ParsedRelease{Title: "Some Show", Special: true, Episodes: []int{5}, EpisodeTitle: "The Title", Container: "mkv"},
+		},
+		{
+			name:     "Captured season replaces a built-in special",
+			path:     "/media/Some Show/2-05 S00E05 Recap.mkv",
+			scope:    ScopeTV,
+			wantRule: "collection",
+			want:     ParsedRelease{Title: "Some Show", Season: 2, Episodes: []int{5}, EpisodeTitle: "S00E05 Recap", Container: "mkv"},
+		},
+	}
+
+	rules := []Rule{dvr, concerts, collection}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rule := ParsePathWithRules(tt.path, tt.scope, rules)
+			if rule != tt.wantRule {
+				t.Errorf("ParsePathWithRules() rule = %q, want %q", rule, tt.wantRule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePathWithRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
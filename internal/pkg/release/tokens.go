@@ -0,0 +1,251 @@
+package release
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// separators lists the characters that delimit tags in release names.
+const separators = `[\s._\-\[\](){}]`
+
+// token is a release tag found in a name.
+type token struct {
+	start, end int      // Position of the tag in the name
+	match      []string // The tag followed by the submatches of its rule
+	weak       bool
+	apply      func(p *parser, m []string)
+}
+
+// tokenRule recognizes one kind of release tag.
+//
+// Strong tags such as "1080p" or "x264" never appear in titles, so the
+// first of them ends the title. Weak tags such as "NF", "GERMAN" or "PROPER"
+// are also ordinary words; they are matched case-sensitively and only count
+// after the title has ended.
+type tokenRule struct {
+	pattern *regexp.Regexp
+	weak    bool
+	apply   func(p *parser, m []string)
+}
+
+// strongRule compiles a case-insensitive rule for a strong tag.
+func strongRule(pattern string, apply func(p *parser, m []string)) tokenRule {
+	return tokenRule{pattern: regexp.MustCompile(`(?i)(?:^|` + separators + `)(` + pattern + `)(?:$|` + separators + `)`), apply: apply}
+}
+
+// weakRule compiles a case-sensitive rule for a weak tag.
+func weakRule(pattern string, apply func(p *parser, m []string)) tokenRule {
+	return tokenRule{pattern: regexp.MustCompile(`(?:^|` + separators + `)(` + pattern + `)(?:$|` + separators + `)`), weak: true, apply: apply}
+}
+
+// parser collects the tags of a release.
+type parser struct {
+	release *ParsedRelease
+	atmos   bool
+}
+
+// finish completes the release once every tag has been applied.
+func (p *parser) finish() {
+	r := p.release
+	if p.atmos && !strings.Contains(r.AudioCodec, "Atmos") {
+		r.AudioCodec = strings.TrimSpace(r.AudioCodec + " Atmos")
+	}
+}
+
+// normalize lowercases a tag and removes the separators inside it, so
+// "WEB-DL", "web.dl" and "WEBDL" compare equal.
+func normalize(tag string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '_', '-':
+			return -1
+		}
+		return r
+	}, strings.ToLower(tag))
+}
+
+// setOnce stores value in field unless an earlier tag has set it.
+func setOnce(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// appendOnce appends value to list unless it is already present.
+func appendOnce(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+var resolutions = map[string]string{"4k": "2160p", "uhd": "2160p"}
+
+var sources = map[string]string{
+	"bluray": "BluRay", "bdrip": "BluRay", "brrip": "BluRay", "bd25": "BluRay", "bd50": "BluRay", "bdremux": "BluRay",
+	"webdl": "WEB-DL", "webrip": "WEBRip", "web": "WEB",
+	"hdtv": "HDTV", "pdtv": "TVRip", "tvrip": "TVRip", "dsr": "TVRip",
+	"dvdrip": "DVD", "dvd": "DVD", "dvd5": "DVD", "dvd9": "DVD", "dvdr": "DVD",
+	"hdrip": "HDRip", "cam": "CAM", "hdcam": "CAM", "ts": "Telesync", "hdts": "Telesync", "telesync": "Telesync",
+}
+
+var videoCodecs = map[string]string{
+	"x264": "AVC", "h264": "AVC", "avc": "AVC",
+	"x265": "HEVC", "h265": "HEVC", "hevc": "HEVC",
+	"xvid": "XviD", "divx": "DivX", "av1": "AV1", "vp9": "VP9", "mpeg2": "MPEG2", "vc1": "VC-1",
+}
+
+var audioCodecs = map[string]string{
+	"ddp": "DDP", "dd+": "DDP", "eac3": "DDP",
+	"dd": "DD", "ac3": "DD",
+	"truehd":  "TrueHD",
+	"dtshdma": "DTS-HD MA", "dtshd": "DTS-HD", "dtsx": "DTS:X", "dts": "DTS",
+	"aac": "AAC", "flac": "FLAC", "opus": "Opus", "mp3": "MP3", "lpcm": "LPCM", "pcm": "LPCM",
+}
+
+var hdrFormats = map[string]string{
+	"hdr10+": "HDR10+", "hdr10plus": "HDR10+", "hdr10": "HDR10", "hdr": "HDR",
+	"dv": "DV", "dovi": "DV", "dolbyvision": "DV", "hlg": "HLG",
+}
+
+// streamingServices maps the service tags of web releases to the service name.
+var streamingServices = map[string]string{
+	"AMZN": "Amazon", "NF": "Netflix", "DSNP": "Disney+", "HMAX": "HBO Max", "ATVP": "Apple TV+",
+	"HULU": "Hulu", "PCOK": "Peacock", "PMTP": "Paramount+", "CRAV": "Crave", "STAN": "Stan",
+	"iT": "iTunes", "iP": "BBC iPlayer", "CR": "Crunchyroll",
+}
+
+// languages maps the language tags of releases to ISO 639-1 codes.
+var languages = map[string]string{
+	"MULTi": "multi", "MULTI": "multi", "DUAL": "dual",
+	"ENGLISH": "en", "ENG": "en", "GERMAN": "de", "GER": "de", "FRENCH": "fr", "TRUEFRENCH": "fr", "VFF": "fr",
+	"ITALIAN": "it", "ITA": "it", "SPANISH": "es", "SPA": "es", "ESP": "es", "DUTCH": "nl",
+	"SWEDISH": "sv", "SWE": "sv", "PORTUGUESE": "pt", "POR": "pt", "RUSSIAN": "ru", "RUS": "ru",
+	"JAPANESE": "ja", "JPN": "ja", "KOREAN": "ko", "KOR": "ko",
+}
+
+// alternation joins the keys of a tag map into a regexp alternation, longest
+// first so that "HDR10" is preferred over "HDR".
+func alternation(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, regexp.QuoteMeta(k))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return strings.Join(keys, "|")
+}
+
+// channels matches an audio channel layout: 5.1, 7 1, 2.0
+const channels = `[1-7][\s._][01]`
+
+// codecChannels splits an audio tag into codec and channels: DD5.1
+var codecChannels = regexp.MustCompile(`^(.*?)[\s._-]?(` + channels + `)$`)
+
+var tokenRules = []tokenRule{
+	strongRule(`(?:2160|1440|1080|720|576|480|360)[pi]|4k|uhd|\d{3,4}x(2160|1440|1080|720|576|480)`, func(p *parser, m []string) {
+		tag := strings.ToLower(m[0])
+		if name, ok := resolutions[tag]; ok {
+			tag = name
+		} else if m[1] != "" {
+			tag = m[1] + "p"
+		}
+		setOnce(&p.release.Resolution, tag)
+	}),
+	strongRule(`blu[\s._-]?ray|bdrip|brrip|bd25|bd50|bdremux|web[\s._-]?dl|web[\s._-]?rip|hdtv|pdtv|tvrip|dvd[\s._-]?rip|dvd[59r]?|hdrip|hdcam|hdts|telesync`, func(p *parser, m []string) {
+		setOnce(&p.release.Source, sources[normalize(m[0])])
+	}),
+	weakRule(`WEB|CAM|TS`, func(p *parser, m []string) {
+		setOnce(&p.release.Source, sources[normalize(m[0])])
+	}),
+	strongRule(`[xh][\s.]?26[45]|avc|hevc|xvid|divx|av1|vp9|mpeg-?2|vc-?1`, func(p *parser, m []string) {
+		setOnce(&p.release.VideoCodec, videoCodecs[normalize(m[0])])
+	}),
+	strongRule(`(ddp|dd\+|e-?ac-?3|ac-?3|truehd|dts[\s._-]?hd[\s._-]?ma|dts[\s._-]?hd|dts[\s._-]?x|dts|aac|lpcm|(?:dd|flac|opus|mp3|pcm)[\s._-]?`+channels+`)(?:[\s._-]?(`+channels+`))?(?:[\s._-]?(atmos))?`, func(p *parser, m []string) {
+		codec := m[1]
+		if c := codecChannels.FindStringSubmatch(codec); c != nil {
+			// Channels written directly after the codec: DD5.1
+			codec, m[2] = c[1], c[2]
+		}
+		setOnce(&p.release.AudioCodec, audioCodecs[normalize(codec)])
+		if m[2] != "" {
+			setOnce(&p.release.AudioChannels, m[2][:1]+"."+m[2][2:])
+		}
+		if m[3] != "" {
+			p.atmos = true
+		}
+	}),
+	weakRule(`DD|FLAC|OPUS|Opus|MP3|PCM`, func(p *parser, m []string) {
+		setOnce(&p.release.AudioCodec, audioCodecs[normalize(m[0])])
+	}),
+	weakRule(`[1-7]\.[01]|[2-8]ch`, func(p *parser, m []string) {
+		layout := m[0]
+		switch layout {
+		case "2ch":
+			layout = "2.0"
+		case "6ch":
+			layout = "5.1"
+		case "8ch":
+			layout = "7.1"
+		}
+		setOnce(&p.release.AudioChannels, layout)
+	}),
+	strongRule(`atmos`, func(p *parser, m []string) {
+		p.atmos = true
+	}),
+	strongRule(`hdr10(?:\+|plus)?|dovi|dolby[\s._-]?vision|hlg`, func(p *parser, m []string) {
+		p.release.HDR = appendOnce(p.release.HDR, hdrFormats[normalize(m[0])])
+	}),
+	weakRule(`HDR|DV`, func(p *parser, m []string) {
+		p.release.HDR = appendOnce(p.release.HDR, hdrFormats[normalize(m[0])])
+	}),
+	weakRule(alternation(streamingServices), func(p *parser, m []string) {
+		setOnce(&p.release.StreamingService, streamingServices[m[0]])
+	}),
+	weakRule(alternation(languages), func(p *parser, m []string) {
+		p.release.Languages = appendOnce(p.release.Languages, languages[m[0]])
+	}),
+	weakRule(`PROPER|REPACK|RERIP`, func(p *parser, m []string) {
+		if m[0] == "PROPER" {
+			p.release.Proper = true
+		} else {
+			p.release.Repack = true
+		}
+	}),
+	// Tags that carry no information for renaming but are never part of a title
+	strongRule(`(?:8|10|12)[\s._-]?bits?|remux|sdr`, func(p *parser, m []string) {}),
+	weakRule(`INTERNAL|iNTERNAL|LIMITED|COMPLETE|READNFO|NFOFIX|SUBBED|DUBBED|HYBRID|HC`, func(p *parser, m []string) {}),
+}
+
+// findTokens returns every release tag in name, ordered by position.
+func findTokens(name string) []token {
+	var tokens []token
+	for _, rule := range tokenRules {
+		for offset := 0; offset < len(name); {
+			loc := rule.pattern.FindStringSubmatchIndex(name[offset:])
+			if loc == nil {
+				break
+			}
+			match := make([]string, 0, len(loc)/2-1)
+			for i := 2; i < len(loc); i += 2 {
+				if loc[i] >= 0 {
+					match = append(match, name[offset+loc[i]:offset+loc[i+1]])
+				} else {
+					match = append(match, "")
+				}
+			}
+			tokens = append(tokens, token{start: offset + loc[2], end: offset + loc[3], match: match, weak: rule.weak, apply: rule.apply})
+			// Continue after the tag; the separator behind it may start the next one
+			offset += loc[3]
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].start < tokens[j].start })
+	return tokens
+}
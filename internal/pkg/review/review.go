@@ -0,0 +1,101 @@
+// Package review keeps the files that batch runs did not rename because the
+// metadata found for them was a weak match. "vidkit review" goes through
+// them with the user later.
+//
+// The queue is a JSON file next to the configuration file. Files are
+// identified by their absolute path, so queuing a file again replaces its
+// entry.
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is a file waiting for review.
+type Entry struct {
+	Path       string    `json:"path"`               // Absolute path of the file
+	Search     string    `json:"search"`             // What the filename was identified as
+	Match      string    `json:"match,omitempty"`    // Best match found by the providers; empty when none was found
+	Provider   string    `json:"provider,omitempty"` // Provider of the match
+	Confidence float64   `json:"confidence"`         // How well the match fits the search, from 0 to 1
+	Queued     time.Time `json:"queued"`
+}
+
+// Queue is the list of files waiting for review.
+type Queue struct {
+	path    string
+	Entries []Entry
+}
+
+// Load reads the queue stored at path. A missing file is an empty queue.
+func Load(path string) (*Queue, error) {
+	q := &Queue{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading review queue: %v", err)
+	}
+	if err := json.Unmarshal(data, &q.Entries); err != nil {
+		return nil, fmt.Errorf("error parsing review queue %s: %v", path, err)
+	}
+	return q, nil
+}
+
+// Add queues a file, replacing an earlier entry of the same file.
+func (q *Queue) Add(entry Entry) {
+	if abs, err := filepath.Abs(entry.Path); err == nil {
+		entry.Path = abs
+	}
+	if entry.Queued.IsZero() {
+		entry.Queued = time.Now()
+	}
+	for i, e := range q.Entries {
+		if e.Path == entry.Path {
+			q.Entries[i] = entry
+			return
+		}
+	}
+	q.Entries = append(q.Entries, entry)
+}
+
+// Remove removes a file from the queue. It reports whether the file was
+// queued.
+func (q *Queue) Remove(path string) bool {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	for i, e := range q.Entries {
+		if e.Path == path {
+			q.Entries = append(q.Entries[:i], q.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the queue back to its file. An empty queue removes the file.
+func (q *Queue) Save() error {
+	if len(q.Entries) == 0 {
+		if err := os.Remove(q.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing review queue: %v", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("error creating review queue directory: %v", err)
+	}
+	data, err := json.MarshalIndent(q.Entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling review queue: %v", err)
+	}
+	if err := os.WriteFile(q.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing review queue: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vidkit", "review.json")
+
+	q, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing queue error = %v", err)
+	}
+	if len(q.Entries) != 0 {
+		t.Fatalf("Load() of a missing queue = %v, want no entries", q.Entries)
+	}
+
+	q.Add(Entry{Path: "/tv/The.Office.S01E01.mkv", Search: "The Office S01E01", Match: "The Office (2001)", Confidence: 0.3})
+	q.Add(Entry{Path: "/movies/Solaris.mkv", Search: "Solaris", Match: "Solaris (2002)", Confidence: 0.5})
+	q.Add(Entry{Path: "/tv/The.Office.S01E01.mkv", Search: "The Office S01E01", Match: "The Office (2005)", Confidence: 0.4})
+	if err := q.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	q, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(q.Entries) != 2 {
+		t.Fatalf("Load() = %d entries, want 2 with the file queued twice replaced", len(q.Entries))
+	}
+	if e := q.Entries[0]; e.Path != "/tv/The.Office.S01E01.mkv" || e.Match != "The Office (2005)" || e.Queued.IsZero() {
+		t.Errorf("Load() first entry = %+v, want the later match of the episode", e)
+	}
+
+	if !q.Remove("/movies/Solaris.mkv") || q.Remove("/movies/Solaris.mkv") {
+		t.Error("Remove() should report the file only while it is queued")
+	}
+	q.Remove("/tv/The.Office.S01E01.mkv")
+	if err := q.Save(); err != nil {
+		t.Fatalf("Save() of an empty queue error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Save() of an empty queue left %s behind", path)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of an invalid queue error = nil, want an error")
+	}
+}